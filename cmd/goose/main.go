@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"strings"
 
 	"github.com/fatih/color"
 
@@ -29,24 +30,72 @@ func writeFileIfChanged(name string, data []byte, perm os.FileMode) error {
 	return os.WriteFile(name, data, perm)
 }
 
+// checkFile checks that name already has contents data, for making sure the
+// output is up-to-date without changing it
+//
+// The error summarizes the first difference.
+func checkFile(name string, data []byte) error {
+	contents, err := os.ReadFile(name)
+	if err != nil {
+		return fmt.Errorf("%s: could not read existing output: %w", name, err)
+	}
+	if bytes.Equal(contents, data) {
+		return nil
+	}
+	have := strings.Split(string(contents), "\n")
+	want := strings.Split(string(data), "\n")
+	line := 0
+	for line < len(have) && line < len(want) && have[line] == want[line] {
+		line++
+	}
+	lineOf := func(lines []string) string {
+		if line >= len(lines) {
+			return "<end of file>"
+		}
+		return lines[line]
+	}
+	return fmt.Errorf("%s is out of date (first difference at line %d)\n"+
+		"  existing:  %s\n  generated: %s",
+		name, line+1, lineOf(have), lineOf(want))
+}
+
 func coqFileContents(f coq.File) []byte {
 	var b bytes.Buffer
 	f.Write(&b)
 	return b.Bytes()
 }
 
-func translate(pkgPatterns []string, outRootDir string, modDir string,
-	ignoreErrors bool, tr goose.Translator) {
+// outputFile determines where to write f: in outDir named after the package
+// if outDir is set, otherwise under outRootDir at a path based on the import
+// path
+func outputFile(f coq.File, outRootDir string, outDir string) string {
+	if outDir != "" {
+		return path.Join(outDir, f.Filename())
+	}
+	return path.Join(outRootDir,
+		coq.ImportToPath(f.PkgPath, f.GoPackage))
+}
+
+func translate(pkgPatterns []string, outRootDir string, outDir string, modDir string,
+	ignoreErrors bool, check bool, verbose bool, tr goose.Translator) {
 	red := color.New(color.FgRed).SprintFunc()
-	fs, errs, patternError := tr.TranslatePackages(modDir, pkgPatterns...)
+	yellow := color.New(color.FgYellow).SprintFunc()
+	results, patternError := tr.Translate(modDir, pkgPatterns...)
 	if patternError != nil {
 		fmt.Fprintln(os.Stderr, red(patternError.Error()))
 		os.Exit(1)
 	}
 
 	someError := false
-	for i, f := range fs {
-		err := errs[i]
+	written := make(map[string]string)
+	for _, r := range results {
+		if verbose {
+			for _, w := range r.Warnings {
+				fmt.Fprintln(os.Stderr, yellow(w.String()))
+			}
+		}
+		f := r.File
+		err := r.Err()
 		if err != nil {
 			fmt.Fprintln(os.Stderr, red(err.Error()))
 			someError = true
@@ -54,10 +103,22 @@ func translate(pkgPatterns []string, outRootDir string, modDir string,
 				continue
 			}
 		}
-		outFile := path.Join(outRootDir,
-			coq.ImportToPath(f.PkgPath, f.GoPackage))
-		outDir := path.Dir(outFile)
-		err = os.MkdirAll(outDir, 0777)
+		outFile := outputFile(f, outRootDir, outDir)
+		if other, ok := written[outFile]; ok {
+			fmt.Fprintln(os.Stderr, red(fmt.Sprintf(
+				"%s and %s would both be written to %s", other, f.PkgPath, outFile)))
+			someError = true
+			continue
+		}
+		written[outFile] = f.PkgPath
+		if check {
+			if err := checkFile(outFile, coqFileContents(f)); err != nil {
+				fmt.Fprintln(os.Stderr, red(err.Error()))
+				someError = true
+			}
+			continue
+		}
+		err = os.MkdirAll(path.Dir(outFile), 0777)
 		if err != nil {
 			fmt.Fprintln(os.Stderr, err.Error())
 			fmt.Fprintln(os.Stderr, red("could not create output directory"))
@@ -86,11 +147,51 @@ func main() {
 	flag.BoolVar(&tr.AddSourceFileComments, "source-comments", false,
 		"add comments indicating Go source code location for each top-level declaration")
 	flag.BoolVar(&tr.TypeCheck, "typecheck", false, "add type-checking theorems")
+	flag.StringVar(&tr.ImportPrefix, "import-prefix", coq.DefaultImportPrefix,
+		"Coq logical path of the GooseLang library, for the prelude and trusted imports")
+	flag.StringVar(&tr.PackagePrefix, "package-prefix", coq.DefaultPackagePrefix,
+		"Coq logical path of other translated packages, for their imports")
+	flag.BoolVar(&tr.RequireExport, "require-export", false,
+		"re-export the prelude and imports (Require Export rather than Require Import)")
+	flag.BoolVar(&tr.NoSprintf, "no-sprintf", false,
+		"report fmt.Sprintf as unsupported rather than translating format strings")
+	flag.BoolVar(&tr.IncludeTests, "tests", false,
+		"also translate _test.go files and external test packages")
+	flag.IntVar(&tr.Printer.IndentWidth, "indent", 2,
+		"number of spaces for each level of indentation in the output")
+	flag.IntVar(&tr.Printer.MaxWidth, "max-width", 0,
+		"wrap function literals and if branches longer than this onto their own lines (0 for no limit)")
+	flag.Func("assert",
+		"translate calls to this function (by full name, e.g. github.com/mit-pdos/goose-nfsd/util.Assert, "+
+			"or Assert in the translated package) as assertions; may be repeated",
+		func(name string) error {
+			tr.AssertFunctions = append(tr.AssertFunctions, name)
+			return nil
+		})
+
+	flag.Func("extern",
+		"translate calls to a function from another package to a model, as go-name=coq-name "+
+			"(e.g. strings.HasPrefix=StringHasPrefix); may be repeated",
+		func(mapping string) error {
+			goName, coqName, ok := strings.Cut(mapping, "=")
+			if !ok {
+				return fmt.Errorf("expected go-name=coq-name")
+			}
+			if tr.ExternalFunctions == nil {
+				tr.ExternalFunctions = make(map[string]string)
+			}
+			tr.ExternalFunctions[goName] = coqName
+			return nil
+		})
 
 	var outRootDir string
 	flag.StringVar(&outRootDir, "out", ".",
 		"root directory for output (default is current directory)")
 
+	var outDir string
+	flag.StringVar(&outDir, "out-dir", "",
+		"write each package to <dir>/<package name>.v rather than under -out")
+
 	var modDir string
 	flag.StringVar(&modDir, "dir", ".",
 		"directory containing necessary go.mod")
@@ -99,7 +200,15 @@ func main() {
 	flag.BoolVar(&ignoreErrors, "ignore-errors", false,
 		"output partial translation even if there are errors")
 
+	var check bool
+	flag.BoolVar(&check, "check", false,
+		"check that the output is up-to-date rather than writing it")
+
+	var verbose bool
+	flag.BoolVar(&verbose, "v", false,
+		"print warnings about code that was translated with caveats")
+
 	flag.Parse()
 
-	translate(flag.Args(), outRootDir, modDir, ignoreErrors, tr)
+	translate(flag.Args(), outRootDir, outDir, modDir, ignoreErrors, check, verbose, tr)
 }
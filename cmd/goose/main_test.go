@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tchajed/goose/internal/coq"
+)
+
+func TestCheckFile(t *testing.T) {
+	assert := assert.New(t)
+	name := filepath.Join(t.TempDir(), "out.v")
+	assert.Error(checkFile(name, []byte("a\n")), "missing file should fail")
+
+	assert.NoError(os.WriteFile(name, []byte("a\nb\nc\n"), 0666))
+	assert.NoError(checkFile(name, []byte("a\nb\nc\n")))
+
+	err := checkFile(name, []byte("a\nB\nc\n"))
+	if assert.Error(err) {
+		assert.Contains(err.Error(), "line 2")
+		assert.Contains(err.Error(), "existing:  b")
+		assert.Contains(err.Error(), "generated: B")
+	}
+
+	err = checkFile(name, []byte("a\nb\nc\nd\n"))
+	if assert.Error(err) {
+		assert.Contains(err.Error(), "line 4")
+		assert.Contains(err.Error(), "generated: d")
+	}
+}
+
+func TestOutputFile(t *testing.T) {
+	assert := assert.New(t)
+	wal := coq.File{PkgPath: "github.com/tchajed/goose/internal/examples/wal", GoPackage: "wal"}
+	trusted := coq.File{PkgPath: "example.com/trusted-ffi", GoPackage: "trusted_ffi"}
+
+	assert.Equal("out/wal.v", outputFile(wal, ".", "out"))
+	assert.Equal("out/trusted_ffi.v", outputFile(trusted, ".", "out"))
+
+	assert.Equal("root/github_com/tchajed/goose/internal/examples/wal.v",
+		outputFile(wal, "root", ""))
+	assert.Equal("root/example_com/trusted_ffi.v",
+		outputFile(trusted, "root", ""))
+}
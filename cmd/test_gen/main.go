@@ -10,6 +10,8 @@ import (
 	"path"
 	"regexp"
 	"strings"
+
+	"github.com/tchajed/goose/internal/coq"
 )
 
 const coqHeader string = `(* autogenerated by goose/cmd/test_gen *)
@@ -126,11 +128,15 @@ func main() {
 				re := regexp.MustCompile(`(?:^func\s)(?P<fail>(failing_)?)(?P<name>test[[:alnum:]]+)(?:\(.*)`)
 				m := re.FindStringSubmatch(line)
 
+				// each test first initializes the package's modified
+				// variables, as Go does before running any code
 				if len(m) != 0 {
 					if len(m[2]) != 0 {
-						fmt.Fprintf(out, "Fail Example %s_ok : %s%s #() ~~> #true := t.\n", m[3], m[2], m[3])
+						fmt.Fprintf(out, "Fail Example %s_ok : (%s #();; %s%s #()) ~~> #true := t.\n",
+							m[3], coq.GlobalsInitName, m[2], m[3])
 					} else {
-						fmt.Fprintf(out, "Example %s_ok : %s #() ~~> #true := t.\n", m[3], m[3])
+						fmt.Fprintf(out, "Example %s_ok : (%s #();; %s #()) ~~> #true := t.\n",
+							m[3], coq.GlobalsInitName, m[3])
 					}
 				}
 			}
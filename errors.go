@@ -14,10 +14,13 @@ import (
 // issue was encountered in a uniform way.
 type errorReporter struct {
 	fset *token.FileSet
+	// warnings collects the warnings for all declarations (errorReporter is
+	// copied along with Ctx, so this is shared)
+	warnings *[]Warning
 }
 
 func newErrorReporter(fset *token.FileSet) errorReporter {
-	return errorReporter{fset}
+	return errorReporter{fset: fset, warnings: new([]Warning)}
 }
 
 // printField implements custom printing for fields, since printer.Fprint does
@@ -106,6 +109,21 @@ func (e *ConversionError) Error() string {
 	return strings.Join(lines, "\n")
 }
 
+// newError creates an error for n without panicking, for errors found outside
+// of translating a declaration
+func (r errorReporter) newError(category string, n ast.Node, msg string, args ...interface{}) *ConversionError {
+	where := r.fset.Position(n.Pos())
+	return &ConversionError{
+		Category:    category,
+		Message:     fmt.Sprintf(msg, args...),
+		GoCode:      r.printGo(n),
+		GooseCaller: getCaller(1),
+		GoSrcFile:   where.String(),
+		Pos:         n.Pos(),
+		End:         n.End(),
+	}
+}
+
 func (r errorReporter) prefixed(prefix string, n ast.Node, msg string, args ...interface{}) {
 	where := r.fset.Position(n.Pos())
 	what := r.printGo(n)
@@ -153,3 +171,35 @@ func (r errorReporter) todo(n ast.Node, msg string, args ...interface{}) {
 func (r errorReporter) unsupported(n ast.Node, msg string, args ...interface{}) {
 	r.prefixed("unsupported", n, msg, args...)
 }
+
+// A Warning describes Go code that was translated, but with some caveat, such
+// as information the model cannot represent being dropped.
+type Warning struct {
+	Message string
+	// the snippet in the source program the warning is about
+	GoCode string
+	// file:lineno for the source program where GoCode appears
+	GoSrcFile string
+	Pos, End  token.Pos
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("[warning]: %s\n%s\n  src: %s",
+		w.Message, w.GoCode, w.GoSrcFile)
+}
+
+// warn reports a warning about n and continues translation
+func (r errorReporter) warn(n ast.Node, msg string, args ...interface{}) {
+	*r.warnings = append(*r.warnings, Warning{
+		Message:   fmt.Sprintf(msg, args...),
+		GoCode:    r.printGo(n),
+		GoSrcFile: r.fset.Position(n.Pos()).String(),
+		Pos:       n.Pos(),
+		End:       n.End(),
+	})
+}
+
+// Warnings returns the warnings reported so far
+func (r errorReporter) Warnings() []Warning {
+	return *r.warnings
+}
@@ -28,6 +28,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/tchajed/goose"
+	"github.com/tchajed/goose/internal/coq"
 )
 
 var updateGold = flag.Bool("update-gold",
@@ -215,6 +216,30 @@ func TestTrustedImport(t *testing.T) {
 	testExample(t, "trust_import", goose.Translator{})
 }
 
+func TestTypeCheck(t *testing.T) {
+	testExample(t, "typecheck", goose.Translator{TypeCheck: true})
+}
+
+func TestAssert(t *testing.T) {
+	testExample(t, "assert", goose.Translator{AssertFunctions: []string{"Assert"}})
+}
+
+func TestAssertFullName(t *testing.T) {
+	testExample(t, "assert", goose.Translator{AssertFunctions: []string{
+		"github.com/tchajed/goose/internal/examples/assert.Assert"}})
+}
+
+func TestExternalFunctions(t *testing.T) {
+	testExample(t, "extern", goose.Translator{ExternalFunctions: map[string]string{
+		"strings.HasPrefix":              "StringHasPrefix",
+		"(*strings.Builder).WriteString": "StringBuilder.WriteString",
+		"(*sync.WaitGroup).Done":         "wg.Done",
+		"(*sync.WaitGroup).Wait":         "wg.Wait",
+		"(*github.com/tchajed/goose/internal/examples/extern/buffer.Buffer).Append": "Buffer.Append",
+		"(github.com/tchajed/goose/internal/examples/extern/buffer.Buffer).Len":     "Buffer.Len",
+	}})
+}
+
 type errorExpectation struct {
 	Line  int
 	Error string
@@ -315,3 +340,193 @@ func TestNegativeExamples(testingT *testing.T) {
 		})
 	}
 }
+
+func TestTranslateFile(testingT *testing.T) {
+	assert := assert.New(testingT)
+	dir := "./testdata/translate-file"
+	f, err := goose.Translator{}.TranslateFile(dir, "point.go")
+	if !assert.NoError(err) {
+		return
+	}
+	var b bytes.Buffer
+	f.Write(&b)
+	assert.Contains(b.String(), "Definition point__sum: val :=")
+	assert.Contains(b.String(), "Definition origin: val :=")
+	assert.NotContains(b.String(), "scaledSum")
+
+	_, err = goose.Translator{}.TranslateFile(dir, "use.go")
+	if !assert.Error(err) {
+		return
+	}
+	for _, name := range []string{"point", "sum", "origin"} {
+		assert.Contains(err.Error(),
+			fmt.Sprintf("reference to %s from point.go", name))
+	}
+	assert.NotContains(err.Error(), "reference to scale")
+}
+
+func TestTranslateResults(testingT *testing.T) {
+	assert := assert.New(testingT)
+	results, err := goose.Translator{}.Translate(".", "./testdata/results")
+	if !assert.NoError(err) || !assert.Len(results, 1) {
+		return
+	}
+	r := results[0]
+	assert.Equal("github.com/tchajed/goose/testdata/results", r.PkgPath)
+	assert.NoError(r.LoadErr)
+	assert.Equal([]string{
+		"github.com/tchajed/goose/internal/examples/trust_import/trusted_example",
+	}, r.Imports)
+
+	// the translation is partial, and the diagnostics say why
+	var b bytes.Buffer
+	r.File.Write(&b)
+	assert.Contains(b.String(), "Definition callTrusted: val :=")
+	assert.NotContains(b.String(), "Definition less")
+	if assert.Len(r.Errors, 1) {
+		cerr := r.Errors[0].(*goose.ConversionError)
+		assert.Equal("unsupported", cerr.Category)
+		assert.Contains(cerr.Message, "string comparison")
+		assert.Contains(cerr.GoSrcFile, "results.go:14")
+	}
+	assert.Error(r.Err())
+
+	if assert.Len(r.Warnings, 2) {
+		w := r.Warnings[0]
+		assert.Contains(w.Message, "panic message is not a constant string")
+		assert.Equal("msg", w.GoCode)
+		assert.Contains(w.GoSrcFile, "results.go:18")
+		w = r.Warnings[1]
+		assert.Contains(w.Message, "panic message formatted with non-constant x")
+		assert.Equal("x", w.GoCode)
+		assert.Contains(w.GoSrcFile, "results.go:22")
+	}
+
+	assert.Equal(map[string]string{"end": "end'"}, r.Renamed)
+}
+
+// translateDir translates the single package in dir with tr, returning the
+// Coq output (or "" after failing t if translation fails)
+func translateDir(t *testing.T, tr goose.Translator, dir string) string {
+	results, err := tr.Translate(".", dir)
+	if !assert.NoError(t, err) || !assert.Len(t, results, 1) {
+		return ""
+	}
+	assert.NoError(t, results[0].Err())
+	var b bytes.Buffer
+	results[0].File.Write(&b)
+	return b.String()
+}
+
+func TestDeterministicLiterals(testingT *testing.T) {
+	assert := assert.New(testingT)
+	translate := func() string {
+		return translateDir(testingT, goose.Translator{}, "./testdata/literals")
+	}
+
+	out := translate()
+	// fields and entries are in source order
+	assert.Contains(out, `"d" ::= #4;
+      "b" ::= #(str"b");
+      "a" ::= #1;
+      "c" ::= #true;
+      "mu" ::= lock.new #()`)
+	assert.Contains(out, `MapInsert "$m" #(str"z") #26;;
+     MapInsert "$m" #(str"a") #1;;`)
+	for i := 0; i < 10; i++ {
+		assert.Equal(out, translate())
+	}
+}
+
+func TestSynthesizedOnce(testingT *testing.T) {
+	assert := assert.New(testingT)
+	results, err := goose.Translator{}.Translate(".", "./internal/examples/unittest")
+	if !assert.NoError(err) || !assert.Len(results, 1) {
+		return
+	}
+	assert.NoError(results[0].Err())
+	// declarations synthesized for several declarations (like the anonymous
+	// struct in anon_struct.go) are generated only once
+	seen := make(map[string]bool)
+	for _, d := range results[0].File.Decls {
+		if _, ok := d.(coq.CommentDecl); ok {
+			continue
+		}
+		decl := d.CoqDecl()
+		assert.False(seen[decl], "duplicate declaration:\n%s", decl)
+		seen[decl] = true
+	}
+}
+
+func TestMultipleFiles(testingT *testing.T) {
+	assert := assert.New(testingT)
+	out := translateDir(testingT, goose.Translator{}, "./testdata/multifile")
+
+	assert.Contains(out,
+		"autogenerated from github.com/tchajed/goose/testdata/multifile")
+	// declarations are in file order, then source order within each file
+	var positions []int
+	for _, decl := range []string{
+		"(* a_point.go *)",
+		"Definition point := struct.decl [",
+		"Definition point__sum: val :=",
+		"(* b_norm.go *)",
+		"Definition origin: val :=",
+		"Definition norm: val :=",
+	} {
+		i := strings.Index(out, decl)
+		assert.NotEqual(-1, i, "missing %s", decl)
+		positions = append(positions, i)
+	}
+	assert.IsIncreasing(positions)
+}
+
+func TestPrinterConfig(testingT *testing.T) {
+	assert := assert.New(testingT)
+	tr := goose.Translator{Printer: coq.PrinterConfig{IndentWidth: 4}}
+	out := translateDir(testingT, tr, "./testdata/multifile")
+	assert.Contains(out,
+		"Definition origin: val :=\n    rec: \"origin\" <> :=\n        struct.mk point [")
+}
+
+func TestIncludeTests(testingT *testing.T) {
+	assert := assert.New(testingT)
+	dir := "./testdata/withtests"
+	out := translateDir(testingT, goose.Translator{}, dir)
+	assert.Contains(out, "Definition double: val :=")
+	assert.NotContains(out, "doubleTwice")
+
+	out = translateDir(testingT, goose.Translator{IncludeTests: true}, dir)
+	assert.Contains(out, "Definition double: val :=")
+	assert.Contains(out, "Definition doubleTwice: val :=")
+}
+
+func TestGo122Loops(testingT *testing.T) {
+	assert := assert.New(testingT)
+	dir := "./testdata/go122"
+	files, errs, err := goose.Translator{}.TranslatePackages(dir,
+		"./rangeint", "./capture", "./address")
+	if !assert.NoError(err) || !assert.Len(files, 3) {
+		return
+	}
+
+	if assert.NoError(errs[0]) {
+		var b bytes.Buffer
+		files[0].Write(&b)
+		actual := b.String()
+		assert.Contains(actual, `let: "$n" := "n" in`)
+		assert.Contains(actual, `let: "i" := ![uint64T] "$i" in`)
+		assert.Contains(actual, `(for: (λ: <>, (![uint32T] "$i") < "$n")`)
+	}
+
+	// loop variables are per-iteration since Go 1.22, which the translation
+	// of three-clause for loops does not model
+	if assert.Error(errs[1]) {
+		assert.Contains(errs[1].Error(),
+			"closure captures loop variable i, which Go makes a fresh variable per iteration")
+	}
+	if assert.Error(errs[2]) {
+		assert.Contains(errs[2].Error(),
+			"address of loop variable i, which Go makes a fresh variable per iteration")
+	}
+}
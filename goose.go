@@ -37,6 +37,23 @@ type Ctx struct {
 	Config
 
 	dep *depTracker
+	// package variables that the package modifies, which are translated as
+	// locations (see globalVarDecl)
+	mutableGlobals map[types.Object]bool
+	// the function whose body is currently being translated
+	fn funcInfo
+}
+
+// funcInfo describes the function whose body is being translated, for
+// translating return and defer statements
+type funcInfo struct {
+	// the result variables, if the results are named
+	namedResults []*ast.Ident
+	// the function's results
+	results *types.Tuple
+	// hasDefer is true if the body has any defer statements (not counting
+	// those in nested function literals)
+	hasDefer bool
 }
 
 // Says how the result of the currently generated expression will be used
@@ -59,6 +76,42 @@ type Config struct {
 	AddSourceFileComments bool
 	TypeCheck             bool
 	Ffi                   string
+	// ImportPrefix is the logical path of the GooseLang library
+	ImportPrefix string
+	// PackagePrefix is the logical path of other translated packages
+	PackagePrefix string
+	// RequireExport re-exports the prelude and imports
+	RequireExport bool
+	// LoopVarPerIteration gives loop variables Go 1.22 semantics, where each
+	// iteration has a fresh copy of the variables, rather than one variable
+	// shared by the whole loop
+	LoopVarPerIteration bool
+	// AssertFunctions names functions that assert their boolean argument, by
+	// their full name (as in types.Func.FullName, for example
+	// "github.com/mit-pdos/goose-nfsd/util.Assert"), or just by name for a
+	// function of the package being translated; calls to them are translated
+	// to the model's assertion
+	AssertFunctions []string
+	// NoSprintf disables translating fmt.Sprintf to string operations
+	NoSprintf bool
+	// ExternalFunctions maps functions and methods from other packages, by
+	// their full name (as in types.Func.FullName, for example
+	// "strings.HasPrefix" or "(*strings.Builder).WriteString"), to the Gallina
+	// name of their model. Calls to methods pass the receiver as the first
+	// argument.
+	ExternalFunctions map[string]string
+	// Printer is the layout of the generated code
+	Printer coq.PrinterConfig
+}
+
+// goVersionAtLeast checks if a go.mod version like "1.21" or "1.22.3" is at
+// least 1.minor
+func goVersionAtLeast(version string, minor int) bool {
+	var major, m int
+	if _, err := fmt.Sscanf(version, "%d.%d", &major, &m); err != nil {
+		return false
+	}
+	return major > 1 || (major == 1 && m >= minor)
 }
 
 func getFfi(pkg *packages.Package) string {
@@ -97,6 +150,22 @@ func NewPkgCtx(pkg *packages.Package, tr Translator) Ctx {
 	config.TypeCheck = tr.TypeCheck
 	config.AddSourceFileComments = tr.AddSourceFileComments
 	config.Ffi = getFfi(pkg)
+	config.ImportPrefix = tr.ImportPrefix
+	config.PackagePrefix = tr.PackagePrefix
+	config.RequireExport = tr.RequireExport
+	config.AssertFunctions = tr.AssertFunctions
+	config.NoSprintf = tr.NoSprintf
+	config.ExternalFunctions = tr.ExternalFunctions
+	config.Printer = tr.Printer
+	if pkg.Module != nil {
+		config.LoopVarPerIteration = goVersionAtLeast(pkg.Module.GoVersion, 22)
+	}
+	if config.ImportPrefix == "" {
+		config.ImportPrefix = coq.DefaultImportPrefix
+	}
+	if config.PackagePrefix == "" {
+		config.PackagePrefix = coq.DefaultPackagePrefix
+	}
 
 	return Ctx{
 		idents:        newIdentCtx(),
@@ -116,9 +185,10 @@ func NewCtx(pkgPath string, conf Config) Ctx {
 		Uses: make(map[*ast.Ident]types.Object),
 		// TODO: these instances give the generic arguments of function
 		//  calls, use those
-		Instances: make(map[*ast.Ident]types.Instance),
-		Types:     make(map[ast.Expr]types.TypeAndValue),
-		Scopes:    make(map[ast.Node]*types.Scope),
+		Instances:  make(map[*ast.Ident]types.Instance),
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Scopes:     make(map[ast.Node]*types.Scope),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
 	}
 	fset := token.NewFileSet()
 	return Ctx{
@@ -216,11 +286,15 @@ func (ctx Ctx) structFields(fs *ast.FieldList) []coq.FieldDecl {
 			ctx.futureWork(f, "multiple fields for same type (split them up)")
 			return nil
 		}
+		ty := ctx.coqType(f.Type)
 		if len(f.Names) == 0 {
-			ctx.unsupported(f, "unnamed (embedded) field")
-			return nil
+			// an embedded field is named after its type
+			decls = append(decls, coq.FieldDecl{
+				Name: ctx.embeddedFieldName(f),
+				Type: ty,
+			})
+			continue
 		}
-		ty := ctx.coqType(f.Type)
 		decls = append(decls, coq.FieldDecl{
 			Name: f.Names[0].Name,
 			Type: ty,
@@ -229,6 +303,37 @@ func (ctx Ctx) structFields(fs *ast.FieldList) []coq.FieldDecl {
 	return decls
 }
 
+func (ctx Ctx) embeddedFieldName(f *ast.Field) string {
+	t := f.Type
+	if star, ok := t.(*ast.StarExpr); ok {
+		t = star.X
+	}
+	switch t := t.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	}
+	ctx.unsupported(f, "embedded field of type %s", ctx.printGo(f.Type))
+	return ""
+}
+
+// hasDirective checks if doc has a //goose:<name> directive line.
+//
+// Directives are omitted from doc.Text(), so they don't show up in the
+// translated comment.
+func hasDirective(doc *ast.CommentGroup, name string) bool {
+	if doc == nil {
+		return false
+	}
+	for _, c := range doc.List {
+		if c.Text == "//goose:"+name {
+			return true
+		}
+	}
+	return false
+}
+
 func addSourceDoc(doc *ast.CommentGroup, comment *string) {
 	if doc == nil {
 		return
@@ -260,7 +365,7 @@ func (ctx Ctx) typeDecl(doc *ast.CommentGroup, spec *ast.TypeSpec) coq.Decl {
 			IsMacro:      false,
 		})
 		ty := coq.StructDecl{
-			Name: spec.Name.Name,
+			Name: coq.GallinaName(spec.Name.Name),
 		}
 		addSourceDoc(doc, &ty.Comment)
 		ctx.addSourceFile(spec, &ty.Comment)
@@ -272,21 +377,32 @@ func (ctx Ctx) typeDecl(doc *ast.CommentGroup, spec *ast.TypeSpec) coq.Decl {
 			IsMacro:      false,
 		})
 		ty := coq.InterfaceDecl{
-			Name: spec.Name.Name,
+			Name: coq.GallinaName(spec.Name.Name),
 		}
 		addSourceDoc(doc, &ty.Comment)
 		ctx.addSourceFile(spec, &ty.Comment)
-		ty.Methods = ctx.structFields(goTy.Methods)
+		for _, m := range goTy.Methods.List {
+			if len(m.Names) == 0 {
+				ctx.futureWork(m, "embedded interface")
+			}
+		}
+		ty.Methods = append([]coq.FieldDecl{{
+			Name: interfaceTypeField,
+			Type: coq.TypeIdent("stringT"),
+		}}, ctx.structFields(goTy.Methods)...)
 		return ty
 	default:
 		ctx.addDef(spec.Name, identInfo{
 			IsPtrWrapped: false,
 			IsMacro:      true,
 		})
-		return coq.TypeDecl{
-			Name: spec.Name.Name,
+		ty := coq.TypeDecl{
+			Name: coq.GallinaName(spec.Name.Name),
 			Body: ctx.coqType(spec.Type),
 		}
+		addSourceDoc(doc, &ty.Comment)
+		ctx.addSourceFile(spec, &ty.Comment)
+		return ty
 	}
 }
 
@@ -343,6 +459,25 @@ func (ctx Ctx) lockMethod(f *ast.SelectorExpr) coq.CallExpr {
 	}
 }
 
+// rwLockMethod translates the methods of sync.RWMutex, where Lock and Unlock
+// take the lock for writing and RLock and RUnlock take it for reading
+func (ctx Ctx) rwLockMethod(f *ast.SelectorExpr) coq.CallExpr {
+	l := ctx.expr(f.X)
+	switch f.Sel.Name {
+	case "Lock":
+		return coq.NewCallExpr(coq.GallinaIdent("rwlock.write_acquire"), l)
+	case "Unlock":
+		return coq.NewCallExpr(coq.GallinaIdent("rwlock.write_release"), l)
+	case "RLock":
+		return coq.NewCallExpr(coq.GallinaIdent("rwlock.read_acquire"), l)
+	case "RUnlock":
+		return coq.NewCallExpr(coq.GallinaIdent("rwlock.read_release"), l)
+	default:
+		ctx.unsupported(f, "method %s of sync.RWMutex", f.Sel.Name)
+		return coq.CallExpr{}
+	}
+}
+
 func (ctx Ctx) condVarMethod(f *ast.SelectorExpr) coq.CallExpr {
 	l := ctx.expr(f.X)
 	switch f.Sel.Name {
@@ -384,16 +519,46 @@ func (ctx Ctx) prophIdMethod(f *ast.SelectorExpr, args []ast.Expr) coq.CallExpr
 	}
 }
 
+// modeledPackages maps the import path of each package whose functions
+// packageMethod translates to operations of the model, rather than to calls, to
+// the name packageMethod dispatches on
+var modeledPackages = map[string]string{
+	"github.com/tchajed/goose/machine/filesys": "filesys",
+	"github.com/tchajed/goose/machine/disk":    "disk",
+	"github.com/tchajed/goose/machine":         "machine",
+	"github.com/mit-pdos/goose-nfsd/util":      "util",
+	"log":                                      "log",
+	"fmt":                                      "fmt",
+	"errors":                                   "errors",
+	"sync":                                     "sync",
+}
+
+// modeledPackage returns the name of the package x refers to in
+// modeledPackages, if it is one
+func (ctx Ctx) modeledPackage(x ast.Expr) (string, bool) {
+	ident, ok := x.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+	pkg, ok := ctx.info.Uses[ident].(*types.PkgName)
+	if !ok {
+		return "", false
+	}
+	name, ok := modeledPackages[pkg.Imported().Path()]
+	return name, ok
+}
+
 func (ctx Ctx) packageMethod(f *ast.SelectorExpr,
 	call *ast.CallExpr) coq.Expr {
 	args := call.Args
-	if isIdent(f.X, "filesys") {
+	pkgName, _ := ctx.modeledPackage(f.X)
+	if pkgName == "filesys" {
 		return ctx.newCoqCall("FS."+toInitialLower(f.Sel.Name), args)
 	}
-	if isIdent(f.X, "disk") {
+	if pkgName == "disk" {
 		return ctx.newCoqCall("disk."+f.Sel.Name, args)
 	}
-	if isIdent(f.X, "machine") {
+	if pkgName == "machine" {
 		switch f.Sel.Name {
 		case "UInt64Get", "UInt64Put", "UInt32Get", "UInt32Put":
 			return ctx.newCoqCall(f.Sel.Name, args)
@@ -402,7 +567,7 @@ func (ctx Ctx) packageMethod(f *ast.SelectorExpr,
 		case "UInt64ToString":
 			return ctx.newCoqCall("uint64_to_string", args)
 		case "Linearize":
-			return coq.GallinaIdent("Linearize")
+			return ctx.newCoqCall("Linearize", args)
 		case "Assume":
 			return ctx.newCoqCall("control.impl.Assume", args)
 		case "Assert":
@@ -424,7 +589,7 @@ func (ctx Ctx) packageMethod(f *ast.SelectorExpr,
 			return coq.CallExpr{}
 		}
 	}
-	if isIdent(f.X, "log") {
+	if pkgName == "log" {
 		switch f.Sel.Name {
 		case "Print", "Printf", "Println":
 			return coq.LoggingStmt{GoCall: ctx.printGo(call)}
@@ -437,19 +602,26 @@ func (ctx Ctx) packageMethod(f *ast.SelectorExpr,
 	//  GooseLang, so it's ok to skip the arguments.
 	//
 	// See https://github.com/mit-pdos/goose-nfsd/blob/master/util/util.go
-	if isIdent(f.X, "util") && f.Sel.Name == "DPrintf" {
+	if pkgName == "util" && f.Sel.Name == "DPrintf" {
 		return coq.NewCallExpr(coq.GallinaIdent("util.DPrintf"),
 			ctx.expr(args[0]),
 			ctx.expr(args[1]),
 			coq.UnitLiteral{})
 	}
-	if isIdent(f.X, "fmt") {
+	if pkgName == "fmt" {
 		switch f.Sel.Name {
 		case "Println", "Printf":
 			return coq.LoggingStmt{GoCall: ctx.printGo(call)}
+		case "Sprintf":
+			return ctx.sprintfExpr(call)
+		case "Errorf":
+			return coq.NewErrorExpr(ctx.sprintfExpr(call))
 		}
 	}
-	if isIdent(f.X, "sync") {
+	if pkgName == "errors" && f.Sel.Name == "New" {
+		return coq.NewErrorExpr(ctx.expr(args[0]))
+	}
+	if pkgName == "sync" {
 		switch f.Sel.Name {
 		case "NewCond":
 			return ctx.newCoqCall("lock.newCond", args)
@@ -469,9 +641,12 @@ func (ctx Ctx) selectorMethod(f *ast.SelectorExpr,
 	if !ok {
 		return ctx.packageMethod(f, call)
 	}
-	if isLockRef(selectorType) {
+	if isLockRef(selectorType) || isMutex(selectorType) {
 		return ctx.lockMethod(f)
 	}
+	if isRWMutexRef(selectorType) || isRWMutex(selectorType) {
+		return ctx.rwLockMethod(f)
+	}
 	if isCFMutexRef(selectorType) {
 		return ctx.lockMethod(f)
 	}
@@ -484,6 +659,9 @@ func (ctx Ctx) selectorMethod(f *ast.SelectorExpr,
 	if isProphId(selectorType) {
 		return ctx.prophIdMethod(f, args)
 	}
+	if isErrorType(selectorType) && f.Sel.Name == "Error" {
+		return coq.ErrorMessageExpr{X: ctx.expr(f.X)}
+	}
 	if isDisk(selectorType) {
 		method := fmt.Sprintf("disk.%s", f.Sel)
 		// skip disk argument (f.X) and just pass the method arguments
@@ -493,10 +671,13 @@ func (ctx Ctx) selectorMethod(f *ast.SelectorExpr,
 	case *types.Interface:
 		interfaceInfo, ok := ctx.getInterfaceInfo(selectorType)
 		if ok {
-			callArgs := append([]ast.Expr{f.X}, args...)
-			return ctx.newCoqCall(
-				coq.InterfaceMethod(interfaceInfo.name, f.Sel.Name),
-				callArgs)
+			// look up the method's closure in the interface value
+			ctx.dep.addDep(interfaceInfo.name)
+			method := coq.NewCallExpr(coq.GallinaIdent("struct.get"),
+				coq.StructDesc(interfaceInfo.name),
+				coq.GallinaString(f.Sel.Name),
+				ctx.expr(f.X))
+			return coq.NewCallExpr(method, ctx.callArgs(call)...)
 		}
 	default:
 		structInfo, ok := ctx.getStructInfo(selectorType)
@@ -504,25 +685,35 @@ func (ctx Ctx) selectorMethod(f *ast.SelectorExpr,
 		// see if f.Sel.Name is a struct field, and translate accordingly if so
 		for _, name := range structInfo.fields() {
 			if f.Sel.Name == name {
-				return ctx.newCoqCallWithExpr(
-					ctx.structSelector(structInfo, f),
-					args)
+				return coq.NewCallExpr(ctx.structSelector(structInfo, f),
+					ctx.callArgs(call)...)
 			}
 		}
 
 		if ok {
-			callArgs := append([]ast.Expr{f.X}, args...)
-			m := coq.StructMethod(structInfo.name, f.Sel.Name)
+			name, recv := ctx.methodTarget(structInfo, f)
+			m := coq.StructMethod(name, f.Sel.Name)
 			ctx.dep.addDep(m)
-			return ctx.newCoqCall(m, callArgs)
+			return coq.NewCallExpr(coq.GallinaIdent(m),
+				append([]coq.Expr{recv}, ctx.callArgs(call)...)...)
 		}
 	}
 	ctx.unsupported(f, "unexpected select on type "+selectorType.String())
 	return nil
 }
 
+// nullaryPrimitives are the operations of the model that are values rather
+// than functions, so calling one with no arguments refers to it instead of
+// applying it to #()
+var nullaryPrimitives = map[coq.GallinaIdent]bool{
+	"Linearize": true,
+}
+
 func (ctx Ctx) newCoqCallTypeArgs(method coq.Expr, typeArgs []coq.Expr,
 	es []ast.Expr) coq.CallExpr {
+	if name, ok := method.(coq.GallinaIdent); ok && len(es) == 0 && nullaryPrimitives[name] {
+		return coq.NewNullaryCall(method, typeArgs...)
+	}
 	var args []coq.Expr
 	for _, e := range es {
 		args = append(args, ctx.expr(e))
@@ -532,6 +723,115 @@ func (ctx Ctx) newCoqCallTypeArgs(method coq.Expr, typeArgs []coq.Expr,
 	return call
 }
 
+// interfaceTypeField is the field of an interface value holding the name of
+// its concrete type. A nil interface is the zero value of the interface's
+// struct, so this field is the empty string, and x == nil compares the field
+// of x to "".
+//
+// Only struct values (not pointers) can be converted to interfaces, so a
+// non-nil interface always holds a struct, and Go's non-nil interface holding
+// a nil pointer cannot be constructed.
+const interfaceTypeField = "$type"
+
+// convertedExpr translates e for use as a value of type t, converting structs
+// to interfaces.
+//
+// An interface value is a table with a closure for each method. Converting a
+// struct to an interface uses a conversion function S__to__I which is declared
+// alongside the current declaration. Only structs from the current package
+// with value receivers can be converted.
+func (ctx Ctx) convertedExpr(t types.Type, e ast.Expr) coq.Expr {
+	if isErrorType(t) {
+		return ctx.errorExpr(e)
+	}
+	iface, ok := ctx.getInterfaceInfo(t)
+	if !ok || iface.interfaceType.Empty() {
+		return ctx.expr(e)
+	}
+	if ctx.info.Types[e].IsNil() {
+		ctx.dep.addDep(iface.name)
+		return coq.NewNullaryCall(coq.GallinaIdent("zero_val"),
+			coq.StructName(iface.name))
+	}
+	srcTy := ctx.typeOf(e)
+	if _, ok := srcTy.Underlying().(*types.Interface); ok {
+		if !types.Identical(srcTy, t) {
+			ctx.unsupported(e, "conversion from interface %v to %v", srcTy, t)
+		}
+		return ctx.expr(e)
+	}
+	info, ok := ctx.getStructInfo(srcTy)
+	if !ok {
+		ctx.unsupported(e, "conversion of %v to interface %v (only structs are supported)", srcTy, t)
+	}
+	if info.throughPointer {
+		ctx.futureWork(e, "conversion of pointer %v to interface %v", srcTy, t)
+	}
+	if strings.Contains(info.name, ".") || strings.Contains(iface.name, ".") {
+		ctx.unsupported(e, "conversion of %v to interface %v from another package", srcTy, t)
+	}
+	name := ctx.interfaceConversion(info, iface)
+	return coq.NewCallExpr(coq.GallinaIdent(name), ctx.expr(e))
+}
+
+// errorExpr translates e for use as an error (see isErrorType)
+func (ctx Ctx) errorExpr(e ast.Expr) coq.Expr {
+	if ctx.info.Types[e].IsNil() {
+		return coq.NilError
+	}
+	if !isErrorType(ctx.typeOf(e)) {
+		ctx.unsupported(e, "conversion of %v to error (only errors.New and fmt.Errorf create errors)", ctx.typeOf(e))
+	}
+	return ctx.expr(e)
+}
+
+// interfaceConversion adds a function converting a struct to an interface to
+// the current declaration, returning the function's name
+func (ctx Ctx) interfaceConversion(info structTypeInfo, iface interfaceTypeInfo) string {
+	name := fmt.Sprintf("%s__to__%s", info.name, iface.name)
+	ctx.dep.addDep(info.name)
+	ctx.dep.addDep(iface.name)
+	methods := coq.NewStructLiteral(iface.name)
+	methods.AddField(interfaceTypeField, coq.StringLiteral{info.name})
+	for i := 0; i < iface.interfaceType.NumMethods(); i++ {
+		m := iface.interfaceType.Method(i)
+		structMethod := coq.StructMethod(info.name, m.Name())
+		ctx.dep.addDep(structMethod)
+		var closure coq.Expr = coq.NewCallExpr(coq.GallinaIdent(structMethod),
+			coq.IdentExpr("t"))
+		// methods without parameters run as soon as they are applied to the
+		// receiver, so they need to be delayed
+		if m.Type().(*types.Signature).Params().Len() == 0 {
+			closure = coq.FuncLit{Body: closure}
+		}
+		methods.AddField(m.Name(), closure)
+	}
+	ctx.dep.addSynthesized(name, coq.FuncDecl{
+		Name:       name,
+		Args:       []coq.FieldDecl{{Name: "t", Type: coq.StructName(info.name)}},
+		ReturnType: coq.StructName(iface.name),
+		Body:       methods,
+		AddTypes:   ctx.Config.TypeCheck,
+	})
+	return name
+}
+
+// callArgs translates the arguments to a call, converting each to the type of
+// its parameter
+func (ctx Ctx) callArgs(call *ast.CallExpr) []coq.Expr {
+	sig, ok := ctx.typeOf(call.Fun).Underlying().(*types.Signature)
+	var args []coq.Expr
+	for i, arg := range call.Args {
+		if !ok || (sig.Variadic() && i >= sig.Params().Len()-1) ||
+			len(call.Args) != sig.Params().Len() {
+			args = append(args, ctx.expr(arg))
+			continue
+		}
+		args = append(args, ctx.convertedExpr(sig.Params().At(i).Type(), arg))
+	}
+	return args
+}
+
 func (ctx Ctx) newCoqCall(method string, es []ast.Expr) coq.CallExpr {
 	return ctx.newCoqCallTypeArgs(coq.GallinaIdent(method), nil, es)
 }
@@ -545,32 +845,33 @@ func (ctx Ctx) methodExpr(call *ast.CallExpr) coq.Expr {
 	// discovered this API via
 	// https://go.googlesource.com/example/+/HEAD/gotypes#named-types
 	if ctx.info.Types[call.Fun].IsType() {
-		// string -> []byte conversions are handled specially
-		if f, ok := call.Fun.(*ast.ArrayType); ok {
-			if f.Len == nil && isIdent(f.Elt, "byte") {
-				arg := args[0]
-				if isString(ctx.typeOf(arg)) {
-					return ctx.newCoqCall("StringToBytes", args)
-				}
-			}
+		// Conversions between strings and byte slices always copy in Go, since
+		// strings are immutable: StringToBytes allocates a fresh slice and
+		// StringFromBytes reads the current contents of the slice, so later
+		// writes to either side are not visible through the other. Both are
+		// heap operations and cost time linear in the length.
+		toTy := ctx.typeOf(call.Fun).Underlying()
+		argTy := ctx.typeOf(args[0]).Underlying()
+		if isByteSlice(toTy) && isString(argTy) {
+			return ctx.newCoqCall("StringToBytes", args)
+		}
+		if isString(toTy) && isByteSlice(argTy) {
+			return ctx.newCoqCall("StringFromBytes", args)
 		}
-		// []byte -> string are handled specially
 		if f, ok := call.Fun.(*ast.Ident); ok && f.Name == "string" {
-			arg := args[0]
-			if isString(ctx.typeOf(arg).Underlying()) {
-				return ctx.expr(args[0])
-			}
-			if !isByteSlice(ctx.typeOf(arg)) {
+			if !isString(argTy) {
 				ctx.unsupported(call,
-					"conversion from type %v to string", ctx.typeOf(arg))
+					"conversion from type %v to string", ctx.typeOf(args[0]))
 				return coq.CallExpr{}
 			}
-			return ctx.newCoqCall("StringFromBytes", args)
+		}
+		// conversions to an integer type (including named integer types)
+		// truncate or zero-extend to the target width
+		if info, ok := getIntegerType(toTy); ok && !info.isUntyped {
+			return ctx.integerConversion(call, args[0], info.width)
 		}
 		// a different type conversion, which is a noop in GooseLang (which is
 		// untyped)
-		// TODO: handle integer conversions here, checking if call.Fun is an integer
-		//  type; see https://github.com/tchajed/goose/issues/14
 		return ctx.expr(args[0])
 	}
 
@@ -584,7 +885,11 @@ func (ctx Ctx) methodExpr(call *ast.CallExpr) coq.Expr {
 	// arguments
 	switch indexF := f.(type) {
 	case *ast.IndexExpr:
-		f = indexF.X
+		// but not calls like `fs[i](x)`, which index into a slice or map of
+		// functions
+		if ctx.info.Types[indexF.Index].IsType() {
+			f = indexF.X
+		}
 	case *ast.IndexListExpr:
 		f = indexF.X
 	}
@@ -596,27 +901,69 @@ func (ctx Ctx) methodExpr(call *ast.CallExpr) coq.Expr {
 		// XXX: this could be a struct field of type `func()`; right now we
 		// don't support generic structs, so code with a generic function field
 		// will be rejected. But, in the future, that might change.
-		retExpr = ctx.newCoqCallTypeArgs(ctx.identExpr(f), typeArgs, args)
+		call := coq.NewCallExpr(ctx.identExpr(f), ctx.callArgs(call)...)
+		call.TypeArgs = typeArgs
+		retExpr = call
 	case *ast.SelectorExpr:
+		if name, ok := ctx.externalFunction(f); ok {
+			var args []coq.Expr
+			if ctx.info.Selections[f] != nil {
+				args = append(args, ctx.externalReceiver(f))
+			}
+			return coq.NewCallExpr(coq.GallinaIdent(name),
+				append(args, ctx.callArgs(call)...)...)
+		}
 		retExpr = ctx.selectorMethod(f, call)
 	case *ast.IndexExpr:
-		// generic type instantiation f[T]
-		ctx.nope(call, "double explicit generic type instantiation")
+		if ctx.info.Types[f.Index].IsType() {
+			// generic type instantiation f[T]
+			ctx.nope(call, "double explicit generic type instantiation")
+		}
+		retExpr = coq.NewCallExpr(ctx.expr(f), ctx.callArgs(call)...)
 	case *ast.IndexListExpr:
 		// generic type instantiation f[T, V]
 		ctx.nope(call, "double explicit generic type instantiation with multiple arguments")
 	default:
-		ctx.unsupported(call, "call to unexpected function (of type %T)", call.Fun)
+		// the function is computed by an arbitrary expression (for example, a
+		// function literal or the result of another call), so evaluate it and
+		// apply the result
+		retExpr = coq.NewCallExpr(ctx.expr(f), ctx.callArgs(call)...)
 	}
 
 	return retExpr
 }
 
+// externalFunction looks up the model of a function or method from another
+// package in Config.ExternalFunctions
+func (ctx Ctx) externalFunction(f *ast.SelectorExpr) (string, bool) {
+	fn, ok := ctx.info.Uses[f.Sel].(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() == ctx.pkgPath {
+		return "", false
+	}
+	name, ok := ctx.ExternalFunctions[fn.FullName()]
+	return name, ok
+}
+
+// externalReceiver translates the receiver of a call to the external method f,
+// taking the address of a struct or loading it to match the method's receiver
+// (see methodTarget)
+func (ctx Ctx) externalReceiver(f *ast.SelectorExpr) coq.Expr {
+	if info, ok := ctx.getStructInfo(ctx.typeOf(f.X)); ok {
+		_, recv := ctx.methodTarget(info, f)
+		return recv
+	}
+	return ctx.expr(f.X)
+}
+
+// makeSliceExpr translates make([]T, len) or make([]T, len, cap); constant
+// sizes are folded like constant declarations, while other sizes are computed at
+// run time.
 func (ctx Ctx) makeSliceExpr(elt coq.Type, args []ast.Expr) coq.CallExpr {
 	if len(args) == 2 {
-		return coq.NewCallExpr(coq.GallinaIdent("NewSlice"), elt, ctx.expr(args[1]))
+		return coq.NewCallExpr(coq.GallinaIdent("NewSlice"), elt, ctx.constExpr(args[1]))
 	} else if len(args) == 3 {
-		return coq.NewCallExpr(coq.GallinaIdent("NewSliceWithCap"), elt, ctx.expr(args[1]), ctx.expr(args[2]))
+		return coq.NewCallExpr(coq.GallinaIdent("NewSliceWithCap"), elt,
+			ctx.constExpr(args[1]), ctx.constExpr(args[2]))
 	} else {
 		ctx.unsupported(args[0], "Too many or too few arguments in slice construction")
 		return coq.CallExpr{}
@@ -645,6 +992,13 @@ func (ctx Ctx) makeExpr(args []ast.Expr) coq.CallExpr {
 			ctx.coqTypeOfType(args[0], ty.Key()),
 			ctx.coqTypeOfType(args[0], ty.Elem()),
 			coq.UnitLiteral{})
+	case *types.Chan:
+		var size coq.Expr = coq.IntLiteral{0}
+		if len(args) > 1 {
+			size = ctx.constExpr(args[1])
+		}
+		return coq.NewCallExpr(coq.GallinaIdent("chan.make"),
+			ctx.coqTypeOfType(args[0], ty.Elem()), size)
 	default:
 		ctx.unsupported(args[0],
 			"make of should be slice or map, got %v", ty)
@@ -658,6 +1012,9 @@ func (ctx Ctx) newExpr(s ast.Node, ty ast.Expr) coq.CallExpr {
 		if isIdent(sel.X, "sync") && isIdent(sel.Sel, "Mutex") {
 			return coq.NewCallExpr(coq.GallinaIdent("lock.new"))
 		}
+		if isIdent(sel.X, "sync") && isIdent(sel.Sel, "RWMutex") {
+			return coq.NewCallExpr(coq.GallinaIdent("rwlock.new"))
+		}
 		if isIdent(sel.X, "sync") && isIdent(sel.Sel, "WaitGroup") {
 			return coq.NewCallExpr(coq.GallinaIdent("waitgroup.New"))
 		}
@@ -665,12 +1022,13 @@ func (ctx Ctx) newExpr(s ast.Node, ty ast.Expr) coq.CallExpr {
 			return coq.NewCallExpr(coq.GallinaIdent("lock.new"))
 		}
 	}
+	ctx.checkZeroSync(s, ctx.typeOf(ty))
 	if t, ok := ctx.typeOf(ty).(*types.Array); ok {
 		return coq.NewCallExpr(coq.GallinaIdent("zero_array"),
 			ctx.coqTypeOfType(ty, t.Elem()),
 			coq.IntLiteral{uint64(t.Len())})
 	}
-	e := coq.NewCallExpr(coq.GallinaIdent("zero_val"), ctx.coqType(ty))
+	e := coq.NewNullaryCall(coq.GallinaIdent("zero_val"), ctx.coqType(ty))
 	// check for new(T) where T is a struct, but not a pointer to a struct
 	// (new(*T) should be translated to ref (zero_val ptrT) as usual,
 	// a pointer to a nil pointer)
@@ -680,6 +1038,15 @@ func (ctx Ctx) newExpr(s ast.Node, ty ast.Expr) coq.CallExpr {
 	return coq.NewCallExpr(coq.GallinaIdent("ref"), e)
 }
 
+// checkZeroSync reports an error if the zero value of t, used for s, would
+// have a mutex or wait group that is not allocated (see isMutex)
+func (ctx Ctx) checkZeroSync(s ast.Node, t types.Type) {
+	if containsSyncValue(t) {
+		ctx.unsupported(s, "zero value of %v, which contains a sync.Mutex, "+
+			"sync.RWMutex, or sync.WaitGroup (use a struct literal or a pointer)", t)
+	}
+}
+
 // integerConversion generates an expression for converting x to an integer
 // of a specific width
 //
@@ -737,64 +1104,173 @@ func (ctx Ctx) callExpr(s *ast.CallExpr) coq.Expr {
 	if isIdent(s.Fun, "copy") {
 		return ctx.copyExpr(s, s.Args[0], s.Args[1])
 	}
+	if isIdent(s.Fun, "close") {
+		return coq.NewCallExpr(coq.GallinaIdent("chan.close"), ctx.expr(s.Args[0]))
+	}
 	if isIdent(s.Fun, "delete") {
-		if _, ok := ctx.typeOf(s.Args[0]).(*types.Map); !ok {
+		// like Go's delete, MapDelete removes the key from the map, after which
+		// lookups see the zero value; deleting an absent key does nothing
+		if _, ok := ctx.typeOf(s.Args[0]).Underlying().(*types.Map); !ok {
 			ctx.unsupported(s, "delete on non-map")
 		}
 		return coq.NewCallExpr(coq.GallinaIdent("MapDelete"), ctx.expr(s.Args[0]), ctx.expr(s.Args[1]))
 	}
-	if isIdent(s.Fun, "uint64") {
-		return ctx.integerConversion(s, s.Args[0], 64)
+	if isIdent(s.Fun, "panic") {
+		return ctx.panicExpr(s)
+	}
+	if ctx.isAssertFunction(s.Fun) {
+		return ctx.newCoqCall("control.impl.Assert", s.Args)
 	}
-	if isIdent(s.Fun, "uint32") {
-		return ctx.integerConversion(s, s.Args[0], 32)
+	return ctx.methodExpr(s)
+}
+
+// isAssertFunction returns true if f refers to one of the AssertFunctions
+func (ctx Ctx) isAssertFunction(f ast.Expr) bool {
+	var ident *ast.Ident
+	switch f := f.(type) {
+	case *ast.Ident:
+		ident = f
+	case *ast.SelectorExpr:
+		ident = f.Sel
+	default:
+		return false
 	}
-	if isIdent(s.Fun, "uint8") {
-		return ctx.integerConversion(s, s.Args[0], 8)
+	fn, ok := ctx.info.Uses[ident].(*types.Func)
+	if !ok || fn.Pkg() == nil {
+		return false
 	}
-	if isIdent(s.Fun, "panic") {
-		msg := "oops"
-		if e, ok := s.Args[0].(*ast.BasicLit); ok {
-			if e.Kind == token.STRING {
-				v := ctx.info.Types[e].Value
-				msg = constant.StringVal(v)
-			}
+	for _, assert := range ctx.AssertFunctions {
+		if assert == fn.FullName() ||
+			(assert == fn.Name() && fn.Pkg().Path() == ctx.pkgPath) {
+			return true
 		}
-		return coq.NewCallExpr(coq.GallinaIdent("Panic"), coq.GallinaString(msg))
 	}
-	// Special case for *sync.NewCond
-	if _, ok := s.Fun.(*ast.SelectorExpr); ok {
+	return false
+}
+
+// panicExpr translates a call to panic. The message in the model is a Gallina
+// string, so it can only use what is known statically: a constant string, or a
+// call to fmt.Sprintf with a constant format string and constant arguments,
+// which is formatted during translation. Other messages are replaced, with a
+// warning.
+func (ctx Ctx) panicExpr(s *ast.CallExpr) coq.Expr {
+	msg := "oops"
+	arg := s.Args[0]
+	if call, ok := arg.(*ast.CallExpr); ok && ctx.isFmtSprintf(call.Fun) &&
+		len(call.Args) > 0 {
+		return coq.NewCallExpr(coq.GallinaIdent("Panic"),
+			coq.GallinaString(ctx.constSprintf(call)))
+	}
+	if v := ctx.info.Types[arg].Value; v != nil && v.Kind() == constant.String {
+		msg = constant.StringVal(v)
 	} else {
-		if signature, ok := ctx.typeOf(s.Fun).(*types.Signature); ok {
-			for j := 0; j < signature.Params().Len(); j++ {
-				if _, ok := signature.Params().At(j).Type().Underlying().(*types.Interface); ok {
-					interfaceName := signature.Params().At(j).Type().String()
-					structName := ctx.typeOf(s.Args[0]).String()
-					interfaceName = unqualifyName(interfaceName)
-					structName = unqualifyName(structName)
-					if interfaceName != structName && interfaceName != "" && structName != "" {
-						conversion := coq.StructToInterfaceDecl{
-							Fun:       ctx.expr(s.Fun).Coq(true),
-							Struct:    structName,
-							Interface: interfaceName,
-							Arg:       ctx.expr(s.Args[0]).Coq(true),
-						}.Coq(true)
-						for i, arg := range s.Args {
-							if i > 0 {
-								conversion += " " + ctx.expr(arg).Coq(true)
-							}
-						}
-						return coq.CallExpr{MethodName: coq.GallinaIdent(conversion)}
-					}
-				}
-			}
+		ctx.warn(s.Args[0], "panic message is not a constant string, using %q", msg)
+	}
+	return coq.NewCallExpr(coq.GallinaIdent("Panic"), coq.GallinaString(msg))
+}
+
+// constSprintf formats a call to fmt.Sprintf whose format string and arguments
+// are all constants. Otherwise it warns and falls back to the format string
+// itself, or to "oops" if even the format string is not constant.
+func (ctx Ctx) constSprintf(call *ast.CallExpr) string {
+	v := ctx.info.Types[call.Args[0]].Value
+	if v == nil || v.Kind() != constant.String {
+		ctx.warn(call.Args[0], "panic message is not a constant string, using %q", "oops")
+		return "oops"
+	}
+	format := constant.StringVal(v)
+	var args []interface{}
+	for _, arg := range call.Args[1:] {
+		v := ctx.info.Types[arg].Value
+		if v == nil {
+			ctx.warn(arg, "panic message formatted with non-constant %s, using %q",
+				types.ExprString(arg), format)
+			return format
 		}
+		args = append(args, constant.Val(v))
 	}
-	return ctx.methodExpr(s)
+	return fmt.Sprintf(format, args...)
+}
+
+// sprintfExpr translates fmt.Sprintf with a constant format string by
+// appending the pieces of the result. Only the verbs %d and %v of integers and
+// %s and %v of strings are supported, without flags or widths.
+func (ctx Ctx) sprintfExpr(call *ast.CallExpr) coq.Expr {
+	if ctx.NoSprintf {
+		ctx.unsupported(call, "fmt.Sprintf (translation of format strings is disabled)")
+	}
+	v := ctx.info.Types[call.Args[0]].Value
+	if v == nil || v.Kind() != constant.String {
+		ctx.unsupported(call.Args[0], "fmt.Sprintf with a non-constant format string")
+	}
+	format := constant.StringVal(v)
+	args := call.Args[1:]
+	var pieces []coq.Expr
+	var lit strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			lit.WriteByte(format[i])
+			continue
+		}
+		if i+1 == len(format) {
+			ctx.unsupported(call, "fmt.Sprintf format ending in %%")
+		}
+		i++
+		verb := format[i]
+		if verb == '%' {
+			lit.WriteByte('%')
+			continue
+		}
+		if len(args) == 0 {
+			ctx.unsupported(call, "fmt.Sprintf with missing argument for %%%c", verb)
+		}
+		arg := args[0]
+		args = args[1:]
+		if lit.Len() > 0 {
+			pieces = append(pieces, coq.StringLiteral{lit.String()})
+			lit.Reset()
+		}
+		pieces = append(pieces, ctx.formatArg(arg, verb))
+	}
+	if len(args) > 0 {
+		ctx.unsupported(args[0], "extra argument to fmt.Sprintf")
+	}
+	if lit.Len() > 0 || len(pieces) == 0 {
+		pieces = append(pieces, coq.StringLiteral{lit.String()})
+	}
+	e := pieces[0]
+	for _, p := range pieces[1:] {
+		e = coq.BinaryExpr{X: e, Op: coq.OpAppend, Y: p}
+	}
+	return e
+}
+
+// formatArg translates arg formatted with the verb %<verb> to a string
+func (ctx Ctx) formatArg(arg ast.Expr, verb byte) coq.Expr {
+	t := ctx.typeOf(arg)
+	if _, ok := getIntegerType(t); ok && (verb == 'd' || verb == 'v') {
+		return coq.NewCallExpr(coq.GallinaIdent("uint64_to_string"),
+			ctx.integerConversion(arg, arg, 64))
+	}
+	if isString(t.Underlying()) && (verb == 's' || verb == 'v') {
+		return ctx.expr(arg)
+	}
+	ctx.unsupported(arg, "fmt.Sprintf verb %%%c for %v", verb, t)
+	return nil
+}
+
+func (ctx Ctx) isFmtSprintf(f ast.Expr) bool {
+	sel, ok := f.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	fn, ok := ctx.info.Uses[sel.Sel].(*types.Func)
+	return ok && fn.Pkg() != nil && fn.Pkg().Path() == "fmt" &&
+		fn.Name() == "Sprintf"
 }
 
 func (ctx Ctx) qualifiedName(obj types.Object) string {
-	name := obj.Name()
+	name := coq.GallinaName(obj.Name())
 	if ctx.pkgPath == obj.Pkg().Path() {
 		// no module name needed
 		return name
@@ -824,6 +1300,12 @@ func (ctx Ctx) selectExpr(e *ast.SelectorExpr) coq.Expr {
 	// If it is, we need to translate to 'StructName__FuncName varName' instead
 	// of a struct access
 	_, isFuncType := (ctx.typeOf(e)).(*types.Signature)
+	if isFuncType && ok {
+		name, recv := ctx.methodTarget(structInfo, e)
+		m := coq.StructMethod(name, e.Sel.Name)
+		ctx.dep.addDep(m)
+		return coq.NewCallExpr(coq.GallinaIdent(m), recv)
+	}
 	if isFuncType {
 		m := coq.StructMethod(structInfo.name, e.Sel.Name)
 		ctx.dep.addDep(m)
@@ -836,26 +1318,140 @@ func (ctx Ctx) selectExpr(e *ast.SelectorExpr) coq.Expr {
 	return nil
 }
 
+// methodTarget determines the struct whose method x.m refers to and translates
+// the receiver x, following Go's rules for method calls: if m has a pointer
+// receiver and x is a value, the receiver is &x, and if m has a value receiver
+// and x is a pointer, the receiver is *x. A method promoted from an embedded
+// field is a method of the embedded struct, and the receiver is that field of
+// x (or its address).
+func (ctx Ctx) methodTarget(info structTypeInfo, f *ast.SelectorExpr) (string, coq.Expr) {
+	sel, ok := ctx.info.Selections[f]
+	if !ok || sel.Kind() != types.MethodVal {
+		return info.name, ctx.expr(f.X)
+	}
+	recv := sel.Obj().(*types.Func).Type().(*types.Signature).Recv()
+	_, needsPtr := recv.Type().(*types.Pointer)
+	path := sel.Index()
+	path = path[:len(path)-1]
+
+	// translate x to either a pointer to the current struct or its value
+	var ptr, val coq.Expr
+	if info.throughPointer {
+		ptr = ctx.expr(f.X)
+	} else if needsPtr && !ctx.embedsPointer(info, path) {
+		ptr = ctx.refExpr(f.X)
+	} else {
+		val = ctx.expr(f.X)
+	}
+	for _, i := range path {
+		field := info.structType.Field(i)
+		desc := coq.StructDesc(info.name)
+		name := coq.GallinaString(field.Name())
+		_, fieldIsPtr := field.Type().(*types.Pointer)
+		switch {
+		case ptr != nil && fieldIsPtr:
+			ptr = coq.NewCallExpr(coq.GallinaIdent("struct.loadF"), desc, name, ptr)
+		case ptr != nil && needsPtr:
+			ptr = coq.NewCallExpr(coq.GallinaIdent("struct.fieldRef"), desc, name, ptr)
+		case ptr != nil:
+			ptr, val = nil, coq.NewCallExpr(coq.GallinaIdent("struct.loadF"), desc, name, ptr)
+		case fieldIsPtr:
+			ptr, val = coq.NewCallExpr(coq.GallinaIdent("struct.get"), desc, name, val), nil
+		default:
+			val = coq.NewCallExpr(coq.GallinaIdent("struct.get"), desc, name, val)
+		}
+		info, ok = ctx.getStructInfo(field.Type())
+		if !ok {
+			ctx.unsupported(f, "method promoted through embedded %v", field.Type())
+		}
+		ctx.dep.addDep(info.name)
+	}
+
+	if needsPtr {
+		if ptr == nil {
+			ctx.unsupported(f, "method %s needs a pointer, but the embedded struct is not addressable", f.Sel.Name)
+		}
+		return info.name, ptr
+	}
+	if ptr != nil {
+		return info.name, coq.NewCallExpr(coq.GallinaIdent("struct.load"),
+			coq.StructDesc(info.name), ptr)
+	}
+	return info.name, val
+}
+
+// embedsPointer checks if following the embedded fields in path from the
+// struct info passes through a pointer
+func (ctx Ctx) embedsPointer(info structTypeInfo, path []int) bool {
+	for _, i := range path {
+		t := info.structType.Field(i).Type()
+		if _, ok := t.(*types.Pointer); ok {
+			return true
+		}
+		info, _ = ctx.getStructInfo(t)
+	}
+	return false
+}
+
+// structSelector translates a field access. The struct expression (which might
+// be a call, as in f().x) appears exactly once in the translation, as the
+// argument to a struct.get or struct.loadF, so it is evaluated once, before the
+// field is read.
 func (ctx Ctx) structSelector(info structTypeInfo, e *ast.SelectorExpr) coq.StructFieldAccessExpr {
 	ctx.dep.addDep(info.name)
+	info, x := ctx.promotedFieldStruct(info, e)
 	return coq.StructFieldAccessExpr{
 		Struct:         info.name,
 		Field:          e.Sel.Name,
-		X:              ctx.expr(e.X),
+		X:              x,
 		ThroughPointer: info.throughPointer,
 	}
 }
 
-func (ctx Ctx) compositeLiteral(e *ast.CompositeLit) coq.Expr {
-	if _, ok := ctx.typeOf(e).Underlying().(*types.Slice); ok {
-		if len(e.Elts) == 0 {
-			return coq.NewCallExpr(coq.GallinaIdent("nil"))
-		}
-		if len(e.Elts) == 1 {
-			return ctx.newCoqCall("SliceSingleton", []ast.Expr{e.Elts[0]})
+// promotedFieldStruct translates the struct that directly contains the field
+// e.Sel, following embedded fields if the field is promoted.
+//
+// Returns the info for the containing struct along with its translation.
+func (ctx Ctx) promotedFieldStruct(info structTypeInfo,
+	e *ast.SelectorExpr) (structTypeInfo, coq.Expr) {
+	x := ctx.expr(e.X)
+	sel, ok := ctx.info.Selections[e]
+	if !ok {
+		return info, x
+	}
+	path := sel.Index()
+	for _, i := range path[:len(path)-1] {
+		f := info.structType.Field(i)
+		x = coq.StructFieldAccessExpr{
+			Struct:         info.name,
+			Field:          f.Name(),
+			X:              x,
+			ThroughPointer: info.throughPointer,
+		}
+		info, ok = ctx.getStructInfo(f.Type())
+		if !ok {
+			ctx.unsupported(e, "field promoted through embedded %v", f.Type())
 		}
-		ctx.unsupported(e, "slice literal with multiple elements")
-		return nil
+		ctx.dep.addDep(info.name)
+	}
+	return info, x
+}
+
+// isPromotedField checks if e selects a field promoted from an embedded struct
+func (ctx Ctx) isPromotedField(e *ast.SelectorExpr) bool {
+	sel, ok := ctx.info.Selections[e]
+	return ok && sel.Kind() == types.FieldVal && len(sel.Index()) > 1
+}
+
+func (ctx Ctx) compositeLiteral(e *ast.CompositeLit) coq.Expr {
+	if t, ok := ctx.typeOf(e).Underlying().(*types.Slice); ok {
+		return ctx.sliceLiteral(e, t)
+	}
+	if t, ok := ctx.typeOf(e).Underlying().(*types.Array); ok {
+		return ctx.arrayLiteral(e, t)
+	}
+	if t, ok := ctx.typeOf(e).Underlying().(*types.Map); ok {
+		return ctx.mapLiteral(e, t)
 	}
 	info, ok := ctx.getStructInfo(ctx.typeOf(e))
 	if ok {
@@ -868,6 +1464,13 @@ func (ctx Ctx) compositeLiteral(e *ast.CompositeLit) coq.Expr {
 func (ctx Ctx) structLiteral(info structTypeInfo,
 	e *ast.CompositeLit) coq.StructLiteral {
 	ctx.dep.addDep(info.name)
+	if t, ok := ctx.typeOf(e).Underlying().(*types.Struct); ok && info.name == anonStructName(t) {
+		ctx.anonStruct(e, t)
+	}
+	// fields not listed are filled in with their zero values by struct.mk
+	//
+	// fields are emitted in source order, followed by any allocated fields in
+	// declaration order, so the output is stable
 	lit := coq.NewStructLiteral(info.name)
 	for _, el := range e.Elts {
 		switch el := el.(type) {
@@ -877,12 +1480,105 @@ func (ctx Ctx) structLiteral(info structTypeInfo,
 				ctx.noExample(el.Key, "struct field keyed by non-identifier %+v", el.Key)
 				return coq.StructLiteral{}
 			}
-			lit.AddField(ident, ctx.expr(el.Value))
+			lit.AddField(ident, ctx.convertedExpr(ctx.typeOf(el.Key), el.Value))
 		default:
 			ctx.unsupported(e,
 				"un-keyed struct literal field %v", ctx.printGo(el))
 		}
 	}
+	// an omitted mutex or wait group field is allocated rather than given a
+	// zero value
+	for i := 0; i < info.structType.NumFields(); i++ {
+		f := info.structType.Field(i)
+		if lit.HasField(f.Name()) {
+			continue
+		}
+		if alloc, ok := syncAllocation(f.Type()); ok {
+			lit.AddField(f.Name(), coq.NewCallExpr(coq.GallinaIdent(alloc)))
+		} else {
+			ctx.checkZeroSync(e, f.Type())
+		}
+	}
+	return lit
+}
+
+// indexedElts translates the elements of an array or slice literal, along
+// with the index of each element
+//
+// Elements can have explicit indices (e.g. []uint64{2: 5, 0: 1}); an element
+// without an index follows the previous one. Also returns the length of the
+// literal, one past the largest index.
+func (ctx Ctx) indexedElts(e *ast.CompositeLit, elem types.Type) (elts []coq.IndexedElt, length uint64) {
+	var next uint64
+	for _, el := range e.Elts {
+		if kv, ok := el.(*ast.KeyValueExpr); ok {
+			i, ok := constant.Uint64Val(ctx.info.Types[kv.Key].Value)
+			if !ok {
+				ctx.unsupported(kv.Key, "literal index must be a constant")
+			}
+			next = i
+			el = kv.Value
+		}
+		elts = append(elts, coq.IndexedElt{
+			Index: next,
+			Value: ctx.convertedExpr(elem, el),
+		})
+		next++
+		if next > length {
+			length = next
+		}
+	}
+	return
+}
+
+// arrayLiteral translates an array literal, filling in elements not listed
+// with the zero value
+func (ctx Ctx) arrayLiteral(e *ast.CompositeLit, t *types.Array) coq.ArrayLiteral {
+	elt := ctx.coqTypeOfType(e, t.Elem())
+	lit := coq.ArrayLiteral{Elt: elt}
+	for i := int64(0); i < t.Len(); i++ {
+		lit.Elts = append(lit.Elts,
+			coq.NewNullaryCall(coq.GallinaIdent("zero_val"), elt))
+	}
+	elts, _ := ctx.indexedElts(e, t.Elem())
+	for i, el := range elts {
+		// the array is constructed all at once, so elements must be listed in
+		// order to be evaluated in order
+		if i > 0 && el.Index < elts[i-1].Index {
+			ctx.unsupported(e.Elts[i], "array literal with out-of-order indices")
+		}
+		lit.Elts[el.Index] = el.Value
+	}
+	return lit
+}
+
+// sliceLiteral translates a slice literal, which is allocated with zero values
+// and then filled in with the listed elements
+func (ctx Ctx) sliceLiteral(e *ast.CompositeLit, t *types.Slice) coq.SliceLiteral {
+	elts, length := ctx.indexedElts(e, t.Elem())
+	return coq.SliceLiteral{
+		Elt:  ctx.coqTypeOfType(e, t.Elem()),
+		Len:  length,
+		Elts: elts,
+	}
+}
+
+// mapLiteral translates a map literal, inserting entries in source order
+func (ctx Ctx) mapLiteral(e *ast.CompositeLit, t *types.Map) coq.MapLiteral {
+	if !supportedMapKey(t.Key()) {
+		ctx.unsupported(e, "maps must be from uint64 or string (not %v)", t.Key())
+	}
+	lit := coq.MapLiteral{
+		Key:   ctx.coqTypeOfType(e, t.Key()),
+		Value: ctx.coqTypeOfType(e, t.Elem()),
+	}
+	for _, el := range e.Elts {
+		kv := el.(*ast.KeyValueExpr)
+		lit.Entries = append(lit.Entries, coq.MapEntry{
+			Key:   ctx.convertedExpr(t.Key(), kv.Key),
+			Value: ctx.convertedExpr(t.Elem(), kv.Value),
+		})
+	}
 	return lit
 }
 
@@ -898,27 +1594,46 @@ func (ctx Ctx) basicLiteral(e *ast.BasicLit) coq.Expr {
 		}
 		return coq.StringLiteral{s}
 	}
-	if e.Kind == token.INT {
-		info, ok := getIntegerType(ctx.typeOf(e))
-		v := ctx.info.Types[e].Value
-		n, ok := constant.Uint64Val(v)
-		if !ok {
-			ctx.unsupported(e,
-				"int literals must be positive numbers")
-			return nil
-		}
-		if info.isUint64() {
-			return coq.IntLiteral{n}
-		} else if info.isUint32() {
-			return coq.Int32Literal{uint32(n)}
-		} else if info.isUint8() {
-			return coq.ByteLiteral{uint8(n)}
-		}
+	if e.Kind == token.INT || e.Kind == token.CHAR {
+		return ctx.intLiteral(e, ctx.typeOf(e), ctx.info.Types[e].Value)
 	}
 	ctx.unsupported(e, "literal with kind %s", e.Kind)
 	return nil
 }
 
+// intLiteral translates the integer constant v of type t to a literal of the
+// appropriate width
+func (ctx Ctx) intLiteral(e ast.Expr, t types.Type, v constant.Value) coq.Expr {
+	info, _ := getIntegerType(t)
+	if constant.Sign(v) < 0 {
+		ctx.unsupported(e,
+			"int literals must be positive numbers")
+		return nil
+	}
+	// the type checker rejects typed constants that overflow, but the value
+	// is checked here anyway, since an untyped constant can be arbitrarily
+	// large and the literals below would silently truncate it
+	width := info.width
+	if width == 0 {
+		width = 64
+	}
+	if constant.BitLen(v) > width {
+		ctx.unsupported(e,
+			"integer constant %s does not fit in %d bits", v.ExactString(), width)
+		return nil
+	}
+	n, _ := constant.Uint64Val(v)
+	if info.isUint64() {
+		return coq.IntLiteral{n}
+	} else if info.isUint32() {
+		return coq.Int32Literal{uint32(n)}
+	} else if info.isUint8() {
+		return coq.ByteLiteral{uint8(n)}
+	}
+	ctx.unsupported(e, "integer constant of type %v", t)
+	return nil
+}
+
 func (ctx Ctx) isNilCompareExpr(e *ast.BinaryExpr) bool {
 	if !(e.Op == token.EQL || e.Op == token.NEQ) {
 		return false
@@ -927,6 +1642,11 @@ func (ctx Ctx) isNilCompareExpr(e *ast.BinaryExpr) bool {
 }
 
 func (ctx Ctx) binExpr(e *ast.BinaryExpr) coq.Expr {
+	// a comparison with nil is translated with nil on the right (see
+	// isNilCompareExpr)
+	if (e.Op == token.EQL || e.Op == token.NEQ) && ctx.info.Types[e.X].IsNil() {
+		e = &ast.BinaryExpr{X: e.Y, OpPos: e.OpPos, Op: e.Op, Y: e.X}
+	}
 	op, ok := map[token.Token]coq.BinOp{
 		token.LSS:  coq.OpLessThan,
 		token.GTR:  coq.OpGreaterThan,
@@ -947,13 +1667,32 @@ func (ctx Ctx) binExpr(e *ast.BinaryExpr) coq.Expr {
 		token.SHR:  coq.OpShr,
 	}[e.Op]
 	if e.Op == token.ADD {
-		if isString(ctx.typeOf(e.X)) {
+		if isString(ctx.typeOf(e.X).Underlying()) {
 			op = coq.OpAppend
 		} else {
 			op = coq.OpPlus
 		}
 		ok = true
 	}
+	// Strings and booleans are GooseLang base literals, so = and ≠ compare them
+	// by value just like integers. There is no string ordering in GooseLang.
+	if ok && isString(ctx.typeOf(e.X).Underlying()) {
+		switch op {
+		case coq.OpLessThan, coq.OpGreaterThan, coq.OpLessEq, coq.OpGreaterEq:
+			ctx.unsupported(e, "string comparison with %v", e.Op)
+		}
+	}
+	if ok && (op == coq.OpEquals || op == coq.OpNotEquals) {
+		_, xStruct := ctx.typeOf(e.X).Underlying().(*types.Struct)
+		_, yStruct := ctx.typeOf(e.Y).Underlying().(*types.Struct)
+		if xStruct && yStruct {
+			eq := ctx.structCompare(e, ctx.typeOf(e.X), ctx.expr(e.X), ctx.expr(e.Y))
+			if op == coq.OpNotEquals {
+				return coq.NotExpr{X: eq}
+			}
+			return eq
+		}
+	}
 	if ok {
 		expr := coq.BinaryExpr{
 			X:  ctx.expr(e.X),
@@ -964,6 +1703,19 @@ func (ctx Ctx) binExpr(e *ast.BinaryExpr) coq.Expr {
 			if _, ok := ctx.typeOf(e.X).(*types.Pointer); ok {
 				expr.Y = coq.Null
 			}
+			if isErrorType(ctx.typeOf(e.X)) {
+				expr.Y = coq.NilError
+			}
+			// an interface is nil if it has no concrete type
+			if info, ok := ctx.getInterfaceInfo(ctx.typeOf(e.X)); ok &&
+				!info.interfaceType.Empty() {
+				ctx.dep.addDep(info.name)
+				expr.X = coq.NewCallExpr(coq.GallinaIdent("struct.get"),
+					coq.StructDesc(info.name),
+					coq.GallinaString(interfaceTypeField),
+					expr.X)
+				expr.Y = coq.StringLiteral{""}
+			}
 		}
 		return expr
 	}
@@ -971,6 +1723,69 @@ func (ctx Ctx) binExpr(e *ast.BinaryExpr) coq.Expr {
 	return nil
 }
 
+// structCompare compares x and y, which are values of the struct type t,
+// binding each to a variable first so it is evaluated only once
+func (ctx Ctx) structCompare(n ast.Node, t types.Type, x, y coq.Expr) coq.Expr {
+	info, _ := ctx.getStructInfo(t)
+	var bindings []coq.Binding
+	// $ cannot appear in Go identifiers, so these names are always fresh
+	bind := func(name string, e coq.Expr) coq.Expr {
+		if _, ok := e.(coq.IdentExpr); ok {
+			return e
+		}
+		bindings = append(bindings, coq.Binding{Names: []string{name}, Expr: e})
+		return coq.IdentExpr(name)
+	}
+	x = bind("$x", x)
+	y = bind("$y", y)
+	eq := ctx.structEquals(n, info, x, y)
+	if len(bindings) == 0 {
+		return eq
+	}
+	return coq.BlockExpr{Bindings: append(bindings, coq.NewAnon(eq))}
+}
+
+// structEquals compares the struct values x and y field by field, since = in
+// GooseLang only compares base values. Blank fields are skipped, as in Go.
+//
+// x and y are used once per field, so they should be variables (see
+// structCompare).
+func (ctx Ctx) structEquals(n ast.Node, info structTypeInfo, x, y coq.Expr) coq.Expr {
+	ctx.dep.addDep(info.name)
+	if info.name == anonStructName(info.structType) {
+		ctx.anonStruct(n, info.structType)
+	}
+	var eq coq.Expr
+	for i := 0; i < info.structType.NumFields(); i++ {
+		f := info.structType.Field(i)
+		if f.Name() == "_" {
+			continue
+		}
+		fx := coq.StructFieldAccessExpr{Struct: info.name, Field: f.Name(), X: x}
+		fy := coq.StructFieldAccessExpr{Struct: info.name, Field: f.Name(), X: y}
+		var fieldEq coq.Expr
+		switch f.Type().Underlying().(type) {
+		case *types.Basic, *types.Pointer, *types.Chan:
+			fieldEq = coq.BinaryExpr{X: fx, Op: coq.OpEquals, Y: fy}
+		case *types.Struct:
+			fieldInfo, _ := ctx.getStructInfo(f.Type())
+			fieldEq = ctx.structEquals(n, fieldInfo, fx, fy)
+		default:
+			ctx.unsupported(n, "comparison of structs with field %s of type %v",
+				f.Name(), f.Type())
+		}
+		if eq == nil {
+			eq = fieldEq
+		} else {
+			eq = coq.BinaryExpr{X: eq, Op: coq.OpLAnd, Y: fieldEq}
+		}
+	}
+	if eq == nil {
+		return coq.True
+	}
+	return eq
+}
+
 func (ctx Ctx) sliceExpr(e *ast.SliceExpr) coq.Expr {
 	if e.Slice3 {
 		ctx.unsupported(e, "3-index slice")
@@ -980,11 +1795,36 @@ func (ctx Ctx) sliceExpr(e *ast.SliceExpr) coq.Expr {
 		ctx.unsupported(e, "setting the max capacity in a slice expression is not supported")
 		return nil
 	}
-	x := ctx.expr(e.X)
+	var x coq.Expr
+	var elt coq.Type
+	switch t := ctx.typeOf(e.X).Underlying().(type) {
+	case *types.Slice:
+		if e.Low == nil && e.High == nil {
+			ctx.unsupported(e, "complete slice doesn't do anything")
+			return nil
+		}
+		x = ctx.expr(e.X)
+		elt = ctx.coqTypeOfType(e, t.Elem())
+	case *types.Array:
+		// Go only slices addressable arrays, and the slice shares the array's
+		// storage
+		elt = ctx.coqTypeOfType(e, t.Elem())
+		x = arraySlice(elt, ctx.refExpr(e.X), t.Len())
+	case *types.Pointer:
+		arrayT, ok := t.Elem().Underlying().(*types.Array)
+		if !ok {
+			ctx.unsupported(e, "slice of %v", t)
+			return nil
+		}
+		elt = ctx.coqTypeOfType(e, arrayT.Elem())
+		x = arraySlice(elt, ctx.expr(e.X), arrayT.Len())
+	default:
+		ctx.unsupported(e, "slice of %v", t)
+		return nil
+	}
 	if e.Low != nil && e.High == nil {
 		return coq.NewCallExpr(coq.GallinaIdent("SliceSkip"),
-			ctx.coqTypeOfType(e, sliceElem(ctx.typeOf(e.X))),
-			x, ctx.expr(e.Low))
+			elt, x, ctx.expr(e.Low))
 	}
 	if e.Low == nil && e.High != nil {
 		return coq.NewCallExpr(coq.GallinaIdent("SliceTake"),
@@ -992,13 +1832,16 @@ func (ctx Ctx) sliceExpr(e *ast.SliceExpr) coq.Expr {
 	}
 	if e.Low != nil && e.High != nil {
 		return coq.NewCallExpr(coq.GallinaIdent("SliceSubslice"),
-			ctx.coqTypeOfType(e, sliceElem(ctx.typeOf(e.X))),
-			x, ctx.expr(e.Low), ctx.expr(e.High))
-	}
-	if e.Low == nil && e.High == nil {
-		ctx.unsupported(e, "complete slice doesn't do anything")
+			elt, x, ctx.expr(e.Low), ctx.expr(e.High))
 	}
-	return nil
+	return x
+}
+
+// arraySlice slices the entire array of length n at location ptr, sharing the
+// array's storage
+func arraySlice(elt coq.Type, ptr coq.Expr, n int64) coq.Expr {
+	return coq.NewCallExpr(coq.GallinaIdent("array.toSlice"),
+		elt, ptr, coq.IntLiteral{Value: uint64(n)})
 }
 
 func (ctx Ctx) nilExpr(e *ast.Ident) coq.Expr {
@@ -1030,7 +1873,10 @@ func (ctx Ctx) unaryExpr(e *ast.UnaryExpr) coq.Expr {
 	if e.Op == token.AND {
 		if x, ok := e.X.(*ast.IndexExpr); ok {
 			// e is &a[b] where x is a.b
-			if xTy, ok := ctx.typeOf(x.X).(*types.Slice); ok {
+			//
+			// the pointer aliases the slice's backing array, so stores through
+			// it are visible to every slice sharing that array
+			if xTy, ok := ctx.typeOf(x.X).Underlying().(*types.Slice); ok {
 				return coq.NewCallExpr(coq.GallinaIdent("SliceRef"),
 					ctx.coqTypeOfType(e, xTy.Elem()),
 					ctx.expr(x.X), ctx.expr(x.Index))
@@ -1045,6 +1891,13 @@ func (ctx Ctx) unaryExpr(e *ast.UnaryExpr) coq.Expr {
 				return sl
 			}
 		}
+		if lit, ok := e.X.(*ast.CompositeLit); ok {
+			// e is &[]T{...} or &[n]T{...}, which allocates the literal
+			return coq.RefExpr{
+				X:  ctx.compositeLiteral(lit),
+				Ty: ctx.coqTypeOfType(e, ctx.typeOf(lit)),
+			}
+		}
 		// e is something else
 		return ctx.refExpr(e.X)
 	}
@@ -1052,11 +1905,93 @@ func (ctx Ctx) unaryExpr(e *ast.UnaryExpr) coq.Expr {
 	return nil
 }
 
+// receiveExpr translates a receive <-ch. Like v, ok := <-ch, chan.receive
+// returns the value along with whether it was sent (rather than being the zero
+// value from a closed channel); a plain receive uses only the value.
+func (ctx Ctx) receiveExpr(e *ast.UnaryExpr, isSpecial bool) coq.Expr {
+	var x coq.Expr = coq.NewCallExpr(coq.GallinaIdent("chan.receive"), ctx.expr(e.X))
+	if !isSpecial {
+		x = coq.NewCallExpr(coq.GallinaIdent("Fst"), x)
+	}
+	return x
+}
+
+// selectStmt translates a select statement.
+//
+// As in Go, the channel and value of every case are evaluated when the select
+// runs. The body of the chosen case runs as a closure, so it cannot return
+// from the enclosing function or break out of an enclosing loop.
+func (ctx Ctx) selectStmt(s *ast.SelectStmt) coq.SelectExpr {
+	var e coq.SelectExpr
+	for _, c := range s.Body.List {
+		c := c.(*ast.CommClause)
+		var sc coq.SelectCase
+		var recv *ast.UnaryExpr
+		switch comm := c.Comm.(type) {
+		case nil:
+			e.Default = &coq.FuncLit{Body: ctx.stmts(c.Body, ExprValLocal)}
+			continue
+		case *ast.SendStmt:
+			elem := ctx.typeOf(comm.Chan).Underlying().(*types.Chan).Elem()
+			sc.Chan = ctx.expr(comm.Chan)
+			sc.Value = ctx.convertedExpr(elem, comm.Value)
+		case *ast.ExprStmt:
+			// case <-ch:
+			recv = ctx.selectRecv(comm.X)
+			sc.Body.Args = []coq.FieldDecl{{Name: "_"}, {Name: "_"}}
+		case *ast.AssignStmt:
+			// case v := <-ch: or case v, ok := <-ch:
+			if comm.Tok != token.DEFINE {
+				ctx.unsupported(comm, "select case assigning to existing variables")
+			}
+			recv = ctx.selectRecv(comm.Rhs[0])
+			for _, lhs := range comm.Lhs {
+				name := lhs.(*ast.Ident)
+				if name.Name != "_" {
+					ctx.addDef(name, identInfo{})
+				}
+				sc.Body.Args = append(sc.Body.Args, coq.FieldDecl{Name: name.Name})
+			}
+			if len(comm.Lhs) == 1 {
+				sc.Body.Args = append(sc.Body.Args, coq.FieldDecl{Name: "_"})
+			}
+		default:
+			ctx.unsupported(c, "select case")
+		}
+		if recv != nil {
+			sc.Chan = ctx.expr(recv.X)
+		}
+		sc.Body.Body = ctx.stmts(c.Body, ExprValLocal)
+		e.Cases = append(e.Cases, sc)
+	}
+	return e
+}
+
+// selectRecv gets the receive <-ch in the case of a select
+func (ctx Ctx) selectRecv(e ast.Expr) *ast.UnaryExpr {
+	recv, ok := e.(*ast.UnaryExpr)
+	if !ok || recv.Op != token.ARROW {
+		ctx.unsupported(e, "select case with parenthesized receive")
+	}
+	return recv
+}
+
+// sendStmt translates a send ch <- v
+func (ctx Ctx) sendStmt(s *ast.SendStmt) coq.Expr {
+	elem := ctx.typeOf(s.Chan).Underlying().(*types.Chan).Elem()
+	return coq.NewCallExpr(coq.GallinaIdent("chan.send"),
+		ctx.expr(s.Chan), ctx.convertedExpr(elem, s.Value))
+}
+
 func (ctx Ctx) variable(s *ast.Ident) coq.Expr {
+	if ctx.isMutableGlobal(s) {
+		return coq.DerefExpr{X: ctx.globalLoc(s), Ty: ctx.coqTypeOfType(s, ctx.typeOf(s))}
+	}
 	info := ctx.identInfo(s)
 	if info.IsMacro {
-		ctx.dep.addDep(s.Name)
-		return coq.GallinaIdent(s.Name)
+		name := coq.GallinaName(s.Name)
+		ctx.dep.addDep(name)
+		return coq.GallinaIdent(name)
 	}
 	e := coq.IdentExpr(s.Name)
 	if info.IsPtrWrapped {
@@ -1065,9 +2000,23 @@ func (ctx Ctx) variable(s *ast.Ident) coq.Expr {
 	return e
 }
 
+// isMutableGlobal returns true if s refers to a package variable that the
+// package modifies
+func (ctx Ctx) isMutableGlobal(s *ast.Ident) bool {
+	return ctx.mutableGlobals[ctx.info.Uses[s]]
+}
+
+// globalLoc is the location of the mutable package variable s
+func (ctx Ctx) globalLoc(s *ast.Ident) coq.Expr {
+	name := coq.GallinaName(s.Name)
+	ctx.dep.addDep(name)
+	return coq.GlobalLoc(name)
+}
+
 func (ctx Ctx) function(s *ast.Ident) coq.Expr {
-	ctx.dep.addDep(s.Name)
-	return coq.GallinaIdent(s.Name)
+	name := coq.GallinaName(s.Name)
+	ctx.dep.addDep(name)
+	return coq.GallinaIdent(name)
 }
 
 func (ctx Ctx) goBuiltin(e *ast.Ident) bool {
@@ -1113,6 +2062,8 @@ func (ctx Ctx) indexExpr(e *ast.IndexExpr, isSpecial bool) coq.CallExpr {
 	xTy := ctx.typeOf(e.X).Underlying()
 	switch xTy := xTy.(type) {
 	case *types.Map:
+		// MapGet returns the zero value of the map's value type when the key is
+		// missing, so a map[K]bool used as a set reads #false for non-members
 		e := coq.NewCallExpr(coq.GallinaIdent("MapGet"), ctx.expr(e.X), ctx.expr(e.Index))
 		if !isSpecial {
 			e = coq.NewCallExpr(coq.GallinaIdent("Fst"), e)
@@ -1122,6 +2073,12 @@ func (ctx Ctx) indexExpr(e *ast.IndexExpr, isSpecial bool) coq.CallExpr {
 		return coq.NewCallExpr(coq.GallinaIdent("SliceGet"),
 			ctx.coqTypeOfType(e, xTy.Elem()),
 			ctx.expr(e.X), ctx.expr(e.Index))
+	case *types.Basic:
+		// like SliceGet, StringGet panics if the index is out of bounds
+		if xTy.Kind() == types.String {
+			return coq.NewCallExpr(coq.GallinaIdent("StringGet"),
+				ctx.expr(e.X), ctx.expr(e.Index))
+		}
 	}
 	ctx.unsupported(e, "index into unknown type %v", xTy)
 	return coq.CallExpr{}
@@ -1149,7 +2106,7 @@ func (ctx Ctx) funcLit(e *ast.FuncLit) coq.FuncLit {
 
 	fl.Args = ctx.paramList(e.Type.Params)
 	// fl.ReturnType = ctx.returnType(d.Type.Results)
-	fl.Body = ctx.blockStmt(e.Body, ExprValReturned)
+	fl.Body = ctx.funcBody(ctx.typeOf(e).(*types.Signature), e.Type, e.Body)
 	return fl
 }
 
@@ -1174,6 +2131,9 @@ func (ctx Ctx) exprSpecial(e ast.Expr, isSpecial bool) coq.Expr {
 	case *ast.IndexExpr:
 		return ctx.indexExpr(e, isSpecial)
 	case *ast.UnaryExpr:
+		if e.Op == token.ARROW {
+			return ctx.receiveExpr(e, isSpecial)
+		}
 		return ctx.unaryExpr(e)
 	case *ast.ParenExpr:
 		return ctx.expr(e.X)
@@ -1251,6 +2211,15 @@ func (ctx Ctx) stmts(ss []ast.Stmt, usage ExprValUsage) coq.BlockExpr {
 	var finalized bool
 	for c.HasNext() {
 		s := c.Next()
+		// a declaration initialized by the following if statement is bound
+		// once, to the value of the conditional
+		if decl, ok := s.(*ast.DeclStmt); ok && c.HasNext() {
+			if b, ok := ctx.conditionalDef(decl, c.Stmts[0], c.Stmts[1:]); ok {
+				c.Next()
+				bindings = append(bindings, b)
+				continue
+			}
+		}
 		// ifStmt is special, it gets a chance to "wrap" the entire remainder
 		// to better support early returns.
 		switch s := s.(type) {
@@ -1258,6 +2227,9 @@ func (ctx Ctx) stmts(ss []ast.Stmt, usage ExprValUsage) coq.BlockExpr {
 			bindings = append(bindings, ctx.ifStmt(s, c.Remainder(), usage))
 			finalized = true
 			break // This would happen anyway since we consumed the iterator via "Remainder"
+		case *ast.SwitchStmt:
+			bindings = append(bindings, ctx.switchStmt(s, c.Remainder(), usage))
+			finalized = true
 		default:
 			// All other statements are translated one-by-one
 			if c.HasNext() {
@@ -1274,7 +2246,7 @@ func (ctx Ctx) stmts(ss []ast.Stmt, usage ExprValUsage) coq.BlockExpr {
 	if !finalized {
 		switch usage {
 		case ExprValReturned:
-			bindings = append(bindings, coq.NewAnon(coq.ReturnExpr{coq.Tt}))
+			bindings = append(bindings, coq.NewAnon(ctx.returnExpr(nil)))
 		case ExprValLoop:
 			bindings = append(bindings, coq.NewAnon(coq.LoopContinue))
 		case ExprValLocal:
@@ -1298,8 +2270,26 @@ func (ctx Ctx) ifStmt(s *ast.IfStmt, remainder []ast.Stmt, usage ExprValUsage) c
 	//  an if statement with early return, this is probably not handled correctly.
 	//  We should conservatively disallow such returns until they're properly analyzed.
 	if s.Init != nil {
-		ctx.unsupported(s.Init, "if statement initializations")
-		return coq.Binding{}
+		// the init statement's variables are only in scope in the if, but the
+		// remainder ends up inside their binding
+		if ctx.shadowsRemainder(&ast.BlockStmt{List: []ast.Stmt{s.Init}}, remainder) {
+			ctx.futureWork(s.Init, "if initialization shadows a variable used after the if")
+			return coq.Binding{}
+		}
+		init := ctx.stmt(s.Init)
+		noInit := *s
+		noInit.Init = nil
+		return coq.NewAnon(coq.BlockExpr{Bindings: []coq.Binding{
+			init, ctx.ifStmt(&noInit, remainder, usage),
+		}})
+	}
+	// if !c { panic(...) } is an assertion of c
+	if not, ok := s.Cond.(*ast.UnaryExpr); ok && not.Op == token.NOT &&
+		s.Else == nil && ctx.isPanicBlock(s.Body) {
+		assert := coq.NewAnon(ctx.newCoqCall("control.impl.Assert", []ast.Expr{not.X}))
+		tailExpr := ctx.stmts(remainder, usage)
+		bindings := append([]coq.Binding{assert}, tailExpr.Bindings...)
+		return coq.NewAnon(coq.BlockExpr{Bindings: bindings})
 	}
 	condExpr := ctx.expr(s.Cond)
 	ife := coq.IfExpr{
@@ -1321,7 +2311,8 @@ func (ctx Ctx) ifStmt(s *ast.IfStmt, remainder []ast.Stmt, usage ExprValUsage) c
 
 	// Supported cases are:
 	// - There is no code after the conditional -- then anything goes.
-	// - The "then" branch always returns, and there is no "else" branch.
+	// - One branch always returns; the code after the conditional then
+	//   continues the other branch.
 	// - Neither "then" nor "else" ever return.
 
 	if len(remainder) == 0 {
@@ -1337,15 +2328,24 @@ func (ctx Ctx) ifStmt(s *ast.IfStmt, remainder []ast.Stmt, usage ExprValUsage) c
 	if ctx.endsWithReturn(s.Body) {
 		ife.Then = ctx.blockStmt(s.Body, usage)
 		// Put trailing code into "else". This is correct because "then" will always return.
-		// "else" must be empty.
-		if len(Else.List) > 0 {
+		if ctx.shadowsRemainder(Else, remainder) {
 			ctx.futureWork(s.Else, "early return in if with an else branch")
 			return coq.Binding{}
 		}
 		// We can propagate the usage here since the return value of this part
 		// will become the return value of the entire conditional (that's why we
 		// put the remainder *inside* the conditional).
-		ife.Else = ctx.stmts(remainder, usage)
+		ife.Else = ctx.stmts(append(append([]ast.Stmt{}, Else.List...), remainder...), usage)
+		return coq.NewAnon(ife)
+	}
+	if len(Else.List) > 0 && ctx.endsWithReturn(Else) {
+		// Symmetrically, put trailing code into "then".
+		if ctx.shadowsRemainder(s.Body, remainder) {
+			ctx.futureWork(s.Body, "early return in else with code after the if")
+			return coq.Binding{}
+		}
+		ife.Then = ctx.stmts(append(append([]ast.Stmt{}, s.Body.List...), remainder...), usage)
+		ife.Else = ctx.blockStmt(Else, usage)
 		return coq.NewAnon(ife)
 	}
 
@@ -1361,6 +2361,119 @@ func (ctx Ctx) ifStmt(s *ast.IfStmt, remainder []ast.Stmt, usage ExprValUsage) c
 	return coq.NewAnon(coq.BlockExpr{Bindings: bindings})
 }
 
+// switchStmt translates an expression switch to a chain of conditionals, one
+// per case in order, with the default case (wherever it appears) last.
+//
+// Like ifStmt, it is responsible for the statements that follow it. If every
+// case other than the default ends in a return, these statements only run
+// after the default case and are placed there; otherwise, the cases cannot use
+// the usage's control effects (for example, they cannot return).
+func (ctx Ctx) switchStmt(s *ast.SwitchStmt, remainder []ast.Stmt, usage ExprValUsage) coq.Binding {
+	if s.Init != nil {
+		if ctx.shadowsRemainder(&ast.BlockStmt{List: []ast.Stmt{s.Init}}, remainder) {
+			ctx.futureWork(s.Init, "switch initialization shadows a variable used after the switch")
+			return coq.Binding{}
+		}
+		init := ctx.stmt(s.Init)
+		noInit := *s
+		noInit.Init = nil
+		return coq.NewAnon(coq.BlockExpr{Bindings: []coq.Binding{
+			init, ctx.switchStmt(&noInit, remainder, usage),
+		}})
+	}
+	var dfltBody []ast.Stmt
+	casesReturn := true
+	for _, c := range s.Body.List {
+		c := c.(*ast.CaseClause)
+		if c.List == nil {
+			dfltBody = c.Body
+		} else if !ctx.stmtsEndWithReturn(c.Body) {
+			casesReturn = false
+		}
+	}
+	caseUsage := usage
+	if len(remainder) > 0 {
+		if casesReturn {
+			if ctx.shadowsRemainder(&ast.BlockStmt{List: dfltBody}, remainder) {
+				ctx.futureWork(s, "early return in switch with code after the default case")
+				return coq.Binding{}
+			}
+			dfltBody = append(append([]ast.Stmt{}, dfltBody...), remainder...)
+			remainder = nil
+		} else {
+			caseUsage = ExprValLocal
+		}
+	}
+	var bindings []coq.Binding
+	// $ cannot appear in Go identifiers, so this name is always fresh
+	tag := coq.IdentExpr("$tag")
+	if s.Tag != nil {
+		// the tag is evaluated once, before any of the cases
+		bindings = append(bindings, coq.Binding{
+			Names: []string{string(tag)}, Expr: ctx.expr(s.Tag)})
+	}
+	var cases []coq.IfExpr
+	for _, c := range s.Body.List {
+		c := c.(*ast.CaseClause)
+		ctx.checkCaseBranches(c)
+		if c.List == nil {
+			continue
+		}
+		body := ctx.stmts(c.Body, caseUsage)
+		var cond coq.Expr
+		for _, e := range c.List {
+			var match coq.Expr
+			if s.Tag != nil {
+				tagTy := ctx.typeOf(s.Tag)
+				caseVal := ctx.convertedExpr(tagTy, e)
+				if _, ok := tagTy.Underlying().(*types.Struct); ok {
+					match = ctx.structCompare(e, tagTy, tag, caseVal)
+				} else {
+					match = coq.BinaryExpr{X: tag, Op: coq.OpEquals, Y: caseVal}
+				}
+			} else {
+				match = ctx.expr(e)
+			}
+			if cond == nil {
+				cond = match
+			} else {
+				cond = coq.BinaryExpr{X: cond, Op: coq.OpLOr, Y: match}
+			}
+		}
+		cases = append(cases, coq.IfExpr{Cond: cond, Then: body})
+	}
+	var e coq.Expr = ctx.stmts(dfltBody, caseUsage)
+	for i := len(cases) - 1; i >= 0; i-- {
+		cases[i].Else = e
+		e = cases[i]
+	}
+	bindings = append(bindings, coq.NewAnon(e))
+	if len(remainder) > 0 {
+		bindings = append(bindings, ctx.stmts(remainder, usage).Bindings...)
+	}
+	return coq.NewAnon(coq.BlockExpr{Bindings: bindings})
+}
+
+// checkCaseBranches reports an error for a break or fallthrough out of a switch
+// case, which has no equivalent in the translation of the switch
+func (ctx Ctx) checkCaseBranches(c *ast.CaseClause) {
+	for _, s := range c.Body {
+		ast.Inspect(s, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.ForStmt, *ast.RangeStmt, *ast.SwitchStmt,
+				*ast.TypeSwitchStmt, *ast.SelectStmt, *ast.FuncLit:
+				// a break in these refers to the inner statement
+				return false
+			case *ast.BranchStmt:
+				if n.Tok == token.BREAK || n.Tok == token.FALLTHROUGH {
+					ctx.unsupported(n, "%v in a switch case", n.Tok)
+				}
+			}
+			return true
+		})
+	}
+}
+
 func (ctx Ctx) loopVar(s ast.Stmt) (ident *ast.Ident, init coq.Expr) {
 	initAssign, ok := s.(*ast.AssignStmt)
 	if !ok ||
@@ -1379,7 +2492,7 @@ func (ctx Ctx) loopVar(s ast.Stmt) (ident *ast.Ident, init coq.Expr) {
 }
 
 func (ctx Ctx) forStmt(s *ast.ForStmt) coq.ForLoopExpr {
-	var init = coq.NewAnon(coq.Skip)
+	var init coq.Binding
 	var ident *ast.Ident
 	if s.Init != nil {
 		ident, _ = ctx.loopVar(s.Init)
@@ -1401,6 +2514,11 @@ func (ctx Ctx) forStmt(s *ast.ForStmt) coq.ForLoopExpr {
 		post = postBlock.Expr
 	}
 
+	// the loop variable is a single reference for the whole loop, which is
+	// only right if closures and pointers don't observe the difference
+	if ident != nil && ctx.LoopVarPerIteration {
+		ctx.checkLoopVarCapture(s.Body, ident)
+	}
 	body := ctx.blockStmt(s.Body, ExprValLoop)
 	return coq.ForLoopExpr{
 		Init: init,
@@ -1417,6 +2535,44 @@ func getIdentOrAnonymous(e ast.Expr) (ident string, ok bool) {
 	return getIdent(e)
 }
 
+// checkLoopVarCapture reports an error if a closure in body refers to one of
+// the loop variables vars, or body takes the address of one, for loops where
+// the translation doesn't match how Go scopes loop variables.
+func (ctx Ctx) checkLoopVarCapture(body *ast.BlockStmt, vars ...*ast.Ident) {
+	loopVars := make(map[types.Object]bool)
+	for _, v := range vars {
+		if obj := ctx.info.Defs[v]; obj != nil {
+			loopVars[obj] = true
+		}
+	}
+	semantics := "a variable shared by all iterations"
+	if ctx.LoopVarPerIteration {
+		semantics = "a fresh variable per iteration"
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		if e, ok := n.(*ast.UnaryExpr); ok && e.Op == token.AND {
+			if id, ok := e.X.(*ast.Ident); ok && loopVars[ctx.info.Uses[id]] {
+				ctx.unsupported(e,
+					"address of loop variable %s, which Go makes %s",
+					id.Name, semantics)
+			}
+		}
+		f, ok := n.(*ast.FuncLit)
+		if !ok {
+			return true
+		}
+		ast.Inspect(f.Body, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok && loopVars[ctx.info.Uses[id]] {
+				ctx.unsupported(id,
+					"closure captures loop variable %s, which Go makes %s",
+					id.Name, semantics)
+			}
+			return true
+		})
+		return false
+	})
+}
+
 func (ctx Ctx) mapRangeStmt(s *ast.RangeStmt) coq.Expr {
 	key, ok := getIdentOrAnonymous(s.Key)
 	if !ok {
@@ -1476,17 +2632,282 @@ func (ctx Ctx) sliceRangeStmt(s *ast.RangeStmt) coq.Expr {
 }
 
 func (ctx Ctx) rangeStmt(s *ast.RangeStmt) coq.Expr {
-	switch ctx.typeOf(s.X).(type) {
+	if _, ok := getIntegerType(ctx.typeOf(s.X)); ok {
+		return ctx.intRangeStmt(s)
+	}
+	// range loops bind fresh variables in each iteration
+	if !ctx.LoopVarPerIteration {
+		var vars []*ast.Ident
+		for _, e := range []ast.Expr{s.Key, s.Value} {
+			if id := getIdentOrNil(e); id != nil {
+				vars = append(vars, id)
+			}
+		}
+		ctx.checkLoopVarCapture(s.Body, vars...)
+	}
+	switch ctx.typeOf(s.X).Underlying().(type) {
 	case *types.Map:
 		return ctx.mapRangeStmt(s)
 	case *types.Slice:
 		return ctx.sliceRangeStmt(s)
-	default:
-		ctx.unsupported(s,
-			"range over %v (only maps and slices are supported)",
-			ctx.typeOf(s.X))
-		return nil
 	}
+	ctx.unsupported(s,
+		"range over %v (only maps, slices, and integers are supported)",
+		ctx.typeOf(s.X))
+	return nil
+}
+
+// intRangeStmt translates a range over an integer (for i := range n) to a
+// counted loop.
+//
+// The counter is a hidden variable, and i is bound to its value in each
+// iteration, so like a Go 1.22 range variable it is fresh per iteration.
+func (ctx Ctx) intRangeStmt(s *ast.RangeStmt) coq.Expr {
+	ty := ctx.typeOf(s.X)
+	if info, _ := getIntegerType(ty); info.isUntyped || info.width == 64 {
+		ty = types.Typ[types.Uint64]
+	}
+	coqTy := ctx.coqTypeOfType(s.X, ty)
+	// neither name can conflict with a Go variable
+	counter := coq.IdentExpr("$i")
+	n := coq.IdentExpr("$n")
+	load := coq.DerefExpr{X: counter, Ty: coqTy}
+
+	key := getIdentOrNil(s.Key)
+	if key != nil && key.Name != "_" {
+		ctx.addDef(key, identInfo{
+			IsPtrWrapped: false,
+			IsMacro:      false,
+		})
+	}
+	body := ctx.blockStmt(s.Body, ExprValLoop)
+	if key != nil && key.Name != "_" {
+		body.Bindings = append([]coq.Binding{
+			{Names: []string{key.Name}, Expr: load},
+		}, body.Bindings...)
+	}
+	zero := ctx.intLiteral(s.X, ty, constant.MakeUint64(0))
+	one := ctx.intLiteral(s.X, ty, constant.MakeUint64(1))
+	// n is evaluated only once, before the loop
+	return coq.BlockExpr{Bindings: []coq.Binding{
+		{Names: []string{string(n)}, Expr: ctx.expr(s.X)},
+		coq.NewAnon(coq.ForLoopExpr{
+			Init: coq.Binding{Names: []string{string(counter)},
+				Expr: coq.RefExpr{X: zero, Ty: coqTy}},
+			Cond: coq.BinaryExpr{X: load, Op: coq.OpLessThan, Y: n},
+			Post: coq.StoreStmt{Dst: counter, Ty: coqTy,
+				X: coq.BinaryExpr{X: load, Op: coq.OpPlus, Y: one}},
+			Body: body,
+		}),
+	}}
+}
+
+// conditionalDef recognizes a declaration followed by an if statement that
+// assigns it in both branches, as in
+//
+//	var x T
+//	if c { x = a } else { x = b }
+//
+// and translates the pair to a single immutable binding to an if expression.
+// This only applies when x is not otherwise read or assigned until the
+// conditional is done and is never modified afterward.
+func (ctx Ctx) conditionalDef(decl *ast.DeclStmt, next ast.Stmt, remainder []ast.Stmt) (coq.Binding, bool) {
+	gd, ok := decl.Decl.(*ast.GenDecl)
+	if !ok || gd.Tok != token.VAR || len(gd.Specs) != 1 {
+		return coq.Binding{}, false
+	}
+	spec := gd.Specs[0].(*ast.ValueSpec)
+	if len(spec.Names) != 1 || len(spec.Values) != 0 {
+		return coq.Binding{}, false
+	}
+	ident := spec.Names[0]
+	obj := ctx.info.Defs[ident]
+	s, ok := next.(*ast.IfStmt)
+	if !ok || s.Init != nil {
+		return coq.Binding{}, false
+	}
+	elseBlock, ok := s.Else.(*ast.BlockStmt)
+	if !ok {
+		return coq.Binding{}, false
+	}
+	thenVal, ok := ctx.soleAssignment(s.Body, obj)
+	if !ok {
+		return coq.Binding{}, false
+	}
+	elseVal, ok := ctx.soleAssignment(elseBlock, obj)
+	if !ok {
+		return coq.Binding{}, false
+	}
+	if ctx.usesObject(obj, s.Cond, thenVal, elseVal) ||
+		ctx.isModified(obj, remainder) {
+		return coq.Binding{}, false
+	}
+	ctx.addDef(ident, identInfo{
+		IsPtrWrapped: false,
+		IsMacro:      false,
+	})
+	ty := ctx.typeOf(ident)
+	return coq.Binding{
+		Names: []string{ident.Name},
+		Expr: coq.IfExpr{
+			Cond: ctx.expr(s.Cond),
+			Then: ctx.convertedExpr(ty, thenVal),
+			Else: ctx.convertedExpr(ty, elseVal),
+		},
+	}, true
+}
+
+// soleAssignment returns e if b consists only of the assignment obj = e
+func (ctx Ctx) soleAssignment(b *ast.BlockStmt, obj types.Object) (ast.Expr, bool) {
+	if len(b.List) != 1 {
+		return nil, false
+	}
+	s, ok := b.List[0].(*ast.AssignStmt)
+	if !ok || s.Tok != token.ASSIGN || len(s.Lhs) != 1 || len(s.Rhs) != 1 {
+		return nil, false
+	}
+	lhs, ok := s.Lhs[0].(*ast.Ident)
+	if !ok || ctx.info.Uses[lhs] != obj {
+		return nil, false
+	}
+	return s.Rhs[0], true
+}
+
+func (ctx Ctx) usesObject(obj types.Object, nodes ...ast.Node) bool {
+	found := false
+	for _, n := range nodes {
+		ast.Inspect(n, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok && ctx.info.Uses[id] == obj {
+				found = true
+			}
+			return !found
+		})
+	}
+	return found
+}
+
+// isModified returns true if any of ss assigns to obj or takes its address
+// (see modifiedObjects)
+func (ctx Ctx) isModified(obj types.Object, ss []ast.Stmt) bool {
+	var nodes []ast.Node
+	for _, s := range ss {
+		nodes = append(nodes, s)
+	}
+	return ctx.modifiedObjects(nodes...)[obj]
+}
+
+// modifiedObjects finds the variables that nodes assign to or take the address
+// of, including through a field or index (e.g., x.f = v or x[i] = v) and
+// implicitly by calling or referencing a pointer method on x
+func (ctx Ctx) modifiedObjects(nodes ...ast.Node) map[types.Object]bool {
+	modified := make(map[types.Object]bool)
+	var mark func(e ast.Expr)
+	mark = func(e ast.Expr) {
+		switch e := e.(type) {
+		case *ast.Ident:
+			if obj := ctx.info.Uses[e]; obj != nil {
+				modified[obj] = true
+			}
+		case *ast.ParenExpr:
+			mark(e.X)
+		case *ast.SelectorExpr:
+			mark(e.X)
+		case *ast.IndexExpr:
+			mark(e.X)
+		}
+	}
+	for _, n := range nodes {
+		ast.Inspect(n, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.AssignStmt:
+				for _, lhs := range n.Lhs {
+					mark(lhs)
+				}
+			case *ast.IncDecStmt:
+				mark(n.X)
+			case *ast.UnaryExpr:
+				if n.Op == token.AND {
+					mark(n.X)
+				}
+			case *ast.SliceExpr:
+				// a slice of an array shares the array's storage
+				if _, ok := ctx.typeOf(n.X).Underlying().(*types.Array); ok {
+					mark(n.X)
+				}
+			case *ast.RangeStmt:
+				mark(n.Key)
+				mark(n.Value)
+			case *ast.SelectorExpr:
+				if ctx.isPointerMethodOfValue(n) {
+					mark(n.X)
+				}
+			}
+			return true
+		})
+	}
+	return modified
+}
+
+// isPointerMethodOfValue returns true if sel is a pointer-receiver method of a
+// value, which implicitly takes the value's address
+func (ctx Ctx) isPointerMethodOfValue(sel *ast.SelectorExpr) bool {
+	selection, ok := ctx.info.Selections[sel]
+	if !ok || selection.Kind() != types.MethodVal {
+		return false
+	}
+	recv := selection.Obj().Type().(*types.Signature).Recv()
+	if _, ok := recv.Type().(*types.Pointer); !ok {
+		return false
+	}
+	_, xIsPtr := ctx.typeOf(sel.X).Underlying().(*types.Pointer)
+	return !xIsPtr
+}
+
+func (ctx Ctx) isPanicBlock(b *ast.BlockStmt) bool {
+	if len(b.List) != 1 {
+		return false
+	}
+	s, ok := b.List[0].(*ast.ExprStmt)
+	if !ok {
+		return false
+	}
+	call, ok := s.X.(*ast.CallExpr)
+	return ok && isIdent(call.Fun, "panic")
+}
+
+// shadowsRemainder returns true if moving remainder to the end of b would bind
+// some of its identifiers to declarations in b rather than outside it.
+func (ctx Ctx) shadowsRemainder(b *ast.BlockStmt, remainder []ast.Stmt) bool {
+	var declared []ast.Node
+	for _, s := range b.List {
+		switch s := s.(type) {
+		case *ast.AssignStmt:
+			if s.Tok == token.DEFINE {
+				declared = append(declared, s)
+			}
+		case *ast.DeclStmt:
+			declared = append(declared, s)
+		}
+	}
+	names := make(map[string]bool)
+	for _, d := range declared {
+		ast.Inspect(d, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok && ctx.info.Defs[id] != nil {
+				names[id.Name] = true
+			}
+			return true
+		})
+	}
+	found := false
+	for _, s := range remainder {
+		ast.Inspect(s, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok && names[id.Name] {
+				found = true
+			}
+			return !found
+		})
+	}
+	return found
 }
 
 func (ctx Ctx) referenceTo(rhs ast.Expr) coq.Expr {
@@ -1548,10 +2969,15 @@ func (ctx Ctx) varSpec(s *ast.ValueSpec) coq.Binding {
 	var rhs coq.Expr
 	if len(s.Values) == 0 {
 		ty := ctx.typeOf(lhs)
+		ctx.checkZeroSync(s, ty)
 		rhs = coq.NewCallExpr(coq.GallinaIdent("ref"),
-			coq.NewCallExpr(coq.GallinaIdent("zero_val"), ctx.coqTypeOfType(s, ty)))
+			coq.NewNullaryCall(coq.GallinaIdent("zero_val"), ctx.coqTypeOfType(s, ty)))
 	} else {
-		rhs = ctx.referenceTo(s.Values[0])
+		ty := ctx.typeOf(lhs)
+		rhs = coq.RefExpr{
+			X:  ctx.convertedExpr(ty, s.Values[0]),
+			Ty: ctx.coqTypeOfType(s, ty),
+		}
 	}
 	return coq.Binding{
 		Names: []string{lhs.Name},
@@ -1590,9 +3016,23 @@ func (ctx Ctx) varDeclStmt(s *ast.DeclStmt) coq.Binding {
 func (ctx Ctx) refExpr(s ast.Expr) coq.Expr {
 	switch s := s.(type) {
 	case *ast.Ident:
-		// this is the intended translation even if s is pointer-wrapped
+		if ctx.isMutableGlobal(s) {
+			return ctx.globalLoc(s)
+		}
+		// only a pointer-wrapped variable has an address; other variables
+		// (including a struct bound with :=) are plain values
+		if !ctx.identInfo(s).IsPtrWrapped {
+			ctx.unsupported(s, "address of %s, which is not declared with var", s.Name)
+		}
 		return coq.IdentExpr(s.Name)
 	case *ast.SelectorExpr:
+		// a mutex is already a pointer to its lock
+		if _, ok := syncAllocation(ctx.typeOf(s)); ok {
+			return ctx.expr(s)
+		}
+		// &x.f is a pointer into the struct x points to if x is a pointer,
+		// otherwise into x's own storage, so that stores through it are
+		// visible in x
 		ty := ctx.typeOf(s.X)
 		info, ok := ctx.getStructInfo(ty)
 		if !ok {
@@ -1627,58 +3067,102 @@ func (ctx Ctx) pointerAssign(dst *ast.Ident, x coq.Expr) coq.Binding {
 
 func (ctx Ctx) assignFromTo(s ast.Node,
 	lhs ast.Expr, rhs coq.Expr) coq.Binding {
+	store, _ := ctx.assignTarget(s, lhs, func(e coq.Expr) coq.Expr { return e })
+	return store(rhs)
+}
+
+// assignTarget translates lhs as the target of an assignment, returning a
+// function that stores a value to it and one that loads its current value (for
+// updates like x += y).
+//
+// The operands of lhs that Go evaluates before assigning (for example, the
+// slice and index of s[i] or the pointer of *p) are passed through operand,
+// which can bind them to evaluate them before the store.
+func (ctx Ctx) assignTarget(s ast.Node, lhs ast.Expr,
+	operand func(coq.Expr) coq.Expr) (store func(rhs coq.Expr) coq.Binding, load func() coq.Expr) {
 	// assignments can mean various things
 	switch lhs := lhs.(type) {
 	case *ast.Ident:
 		if lhs.Name == "_" {
-			return coq.NewAnon(rhs)
+			return coq.NewAnon, nil
+		}
+		if ctx.isMutableGlobal(lhs) {
+			dst := ctx.globalLoc(lhs)
+			ty := ctx.coqTypeOfType(lhs, ctx.typeOf(lhs))
+			return func(rhs coq.Expr) coq.Binding {
+					return coq.NewAnon(coq.StoreStmt{Dst: dst, X: rhs, Ty: ty})
+				}, func() coq.Expr {
+					return coq.DerefExpr{X: dst, Ty: ty}
+				}
 		}
 		if ctx.identInfo(lhs).IsPtrWrapped {
-			return ctx.pointerAssign(lhs, rhs)
+			return func(rhs coq.Expr) coq.Binding {
+					return ctx.pointerAssign(lhs, rhs)
+				}, func() coq.Expr {
+					return ctx.expr(lhs)
+				}
 		}
 		ctx.unsupported(s, "variable %s is not assignable\n\t(declare it with 'var' to pointer-wrap in GooseLang and support re-assignment)", lhs.Name)
 	case *ast.IndexExpr:
 		targetTy := ctx.typeOf(lhs.X)
 		switch targetTy := targetTy.(type) {
 		case *types.Slice:
-			value := rhs
-			return coq.NewAnon(coq.NewCallExpr(
-				coq.GallinaIdent("SliceSet"),
-				ctx.coqTypeOfType(lhs, targetTy.Elem()),
-				ctx.expr(lhs.X),
-				ctx.expr(lhs.Index),
-				value))
+			elt := ctx.coqTypeOfType(lhs, targetTy.Elem())
+			x := operand(ctx.expr(lhs.X))
+			index := operand(ctx.expr(lhs.Index))
+			return func(rhs coq.Expr) coq.Binding {
+					return coq.NewAnon(coq.NewCallExpr(
+						coq.GallinaIdent("SliceSet"), elt, x, index, rhs))
+				}, func() coq.Expr {
+					return coq.NewCallExpr(coq.GallinaIdent("SliceGet"), elt, x, index)
+				}
 		case *types.Map:
-			value := rhs
-			return coq.NewAnon(coq.NewCallExpr(
-				coq.GallinaIdent("MapInsert"),
-				ctx.expr(lhs.X),
-				ctx.expr(lhs.Index),
-				value))
+			x := operand(ctx.expr(lhs.X))
+			index := operand(ctx.expr(lhs.Index))
+			return func(rhs coq.Expr) coq.Binding {
+					return coq.NewAnon(coq.NewCallExpr(
+						coq.GallinaIdent("MapInsert"), x, index, rhs))
+				}, func() coq.Expr {
+					return coq.NewCallExpr(coq.GallinaIdent("Fst"),
+						coq.NewCallExpr(coq.GallinaIdent("MapGet"), x, index))
+				}
 		default:
 			ctx.unsupported(s, "index update to unexpected target of type %v", targetTy)
 		}
 	case *ast.StarExpr:
 		info, ok := ctx.getStructInfo(ctx.typeOf(lhs.X))
 		if ok && info.throughPointer {
-			return coq.NewAnon(coq.NewCallExpr(coq.GallinaIdent("struct.store"),
-				coq.StructDesc(info.name),
-				ctx.expr(lhs.X),
-				rhs))
+			ptr := operand(ctx.expr(lhs.X))
+			return func(rhs coq.Expr) coq.Binding {
+					return coq.NewAnon(coq.NewCallExpr(coq.GallinaIdent("struct.store"),
+						coq.StructDesc(info.name), ptr, rhs))
+				}, func() coq.Expr {
+					return coq.NewCallExpr(coq.GallinaIdent("struct.load"),
+						coq.StructDesc(info.name), ptr)
+				}
 		}
 		dstPtrTy, ok := ctx.typeOf(lhs.X).Underlying().(*types.Pointer)
 		if !ok {
 			ctx.unsupported(s,
 				"could not identify element type of assignment through pointer")
 		}
-		return coq.NewAnon(coq.StoreStmt{
-			Dst: ctx.expr(lhs.X),
-			Ty:  ctx.coqTypeOfType(s, dstPtrTy.Elem()),
-			X:   rhs,
-		})
+		ty := ctx.coqTypeOfType(s, dstPtrTy.Elem())
+		ptr := operand(ctx.expr(lhs.X))
+		return func(rhs coq.Expr) coq.Binding {
+				return coq.NewAnon(coq.StoreStmt{Dst: ptr, Ty: ty, X: rhs})
+			}, func() coq.Expr {
+				return coq.DerefExpr{X: ptr, Ty: ty}
+			}
 	case *ast.SelectorExpr:
+		if ctx.isPromotedField(lhs) {
+			ctx.futureWork(s, "assignment to promoted field")
+		}
 		ty := ctx.typeOf(lhs.X)
 		info, ok := ctx.getStructInfo(ty)
+		if !ok {
+			ctx.unsupported(s,
+				"assigning to field of non-struct type %v", ty)
+		}
 		var structExpr coq.Expr
 		// TODO: this adjusts for pointer-wrapping in refExpr, but there should
 		//  be a more systematic way to think about this (perhaps in terms of
@@ -1688,20 +3172,42 @@ func (ctx Ctx) assignFromTo(s ast.Node,
 		} else {
 			structExpr = ctx.refExpr(lhs.X)
 		}
-		if ok {
-			fieldName := lhs.Sel.Name
-			return coq.NewAnon(coq.NewCallExpr(coq.GallinaIdent("struct.storeF"),
-				coq.StructDesc(info.name),
-				coq.GallinaString(fieldName),
-				structExpr,
-				rhs))
-		}
-		ctx.unsupported(s,
-			"assigning to field of non-struct type %v", ty)
+		structExpr = operand(structExpr)
+		fieldName := lhs.Sel.Name
+		return func(rhs coq.Expr) coq.Binding {
+				return coq.NewAnon(coq.NewCallExpr(coq.GallinaIdent("struct.storeF"),
+					coq.StructDesc(info.name),
+					coq.GallinaString(fieldName),
+					structExpr,
+					rhs))
+			}, func() coq.Expr {
+				return coq.NewCallExpr(coq.GallinaIdent("struct.loadF"),
+					coq.StructDesc(info.name),
+					coq.GallinaString(fieldName),
+					structExpr)
+			}
 	default:
 		ctx.unsupported(s, "assigning to complex expression")
 	}
-	return coq.Binding{}
+	return nil, nil
+}
+
+// bindOperands returns an operand function for assignTarget that binds each
+// operand to a fresh name in *bindings, so it is evaluated once, at that
+// point
+func bindOperands(bindings *[]coq.Binding) func(coq.Expr) coq.Expr {
+	return func(e coq.Expr) coq.Expr {
+		switch e.(type) {
+		case coq.IdentExpr, coq.IntLiteral, coq.Int32Literal, coq.ByteLiteral,
+			coq.StringLiteral, coq.BoolLiteral:
+			// an immutable variable or a literal has the same value later
+			return e
+		}
+		// $ cannot appear in Go identifiers, so these names are always fresh
+		name := fmt.Sprintf("$lhs%d", len(*bindings))
+		*bindings = append(*bindings, coq.Binding{Names: []string{name}, Expr: e})
+		return coq.IdentExpr(name)
+	}
 }
 
 func (ctx Ctx) multipleAssignStmt(s *ast.AssignStmt) coq.Binding {
@@ -1715,13 +3221,15 @@ func (ctx Ctx) multipleAssignStmt(s *ast.AssignStmt) coq.Binding {
 	//   c = ret3
 	// }
 	//
+	// Similarly, a, b = e1, e2 evaluates both e1 and e2 before assigning (so
+	// a, b = b, a swaps), and is translated by binding each value first.
+	//
+	// As in Go, the operands on the left-hand side (such as the index in
+	// s[i]) are evaluated before the right-hand side and any of the stores, so
+	// s[i], i = 1, 2 stores to the old index.
+	//
 	// Returns multiple bindings, since there are multiple statements
 
-	if len(s.Rhs) > 1 {
-		ctx.unsupported(s, "multiple assignments on right hand side")
-	}
-	rhs := ctx.expr(s.Rhs[0])
-
 	if s.Tok != token.ASSIGN {
 		// This should be invalid Go syntax anyway
 		ctx.unsupported(s, "%v multiple assignment", s.Tok)
@@ -1731,13 +3239,31 @@ func (ctx Ctx) multipleAssignStmt(s *ast.AssignStmt) coq.Binding {
 	for i := 0; i < len(names); i += 1 {
 		names[i] = fmt.Sprintf("%d_ret", i)
 	}
-	multipleRetBinding := coq.Binding{Names: names, Expr: rhs}
-
-	coqStmts := make([]coq.Binding, len(s.Lhs)+1)
-	coqStmts[0] = multipleRetBinding
+	var coqStmts []coq.Binding
+	operand := bindOperands(&coqStmts)
+	stores := make([]func(coq.Expr) coq.Binding, len(s.Lhs))
+	for i, lhs := range s.Lhs {
+		stores[i], _ = ctx.assignTarget(s, lhs, operand)
+	}
+	if len(s.Rhs) == 1 {
+		// v, ok = m[k] uses the two-result form of the map lookup
+		coqStmts = append(coqStmts,
+			coq.Binding{Names: names, Expr: ctx.exprSpecial(s.Rhs[0], len(s.Lhs) == 2)})
+	} else {
+		for i, rhs := range s.Rhs {
+			var x coq.Expr
+			if isIdent(s.Lhs[i], "_") {
+				x = ctx.expr(rhs)
+			} else {
+				x = ctx.convertedExpr(ctx.typeOf(s.Lhs[i]), rhs)
+			}
+			coqStmts = append(coqStmts,
+				coq.Binding{Names: []string{names[i]}, Expr: x})
+		}
+	}
 
 	for i, name := range names {
-		coqStmts[i+1] = ctx.assignFromTo(s, s.Lhs[i], coq.IdentExpr(name))
+		coqStmts = append(coqStmts, stores[i](coq.IdentExpr(name)))
 	}
 	return coq.Binding{Names: make([]string, 0), Expr: coq.BlockExpr{Bindings: coqStmts}}
 }
@@ -1750,52 +3276,90 @@ func (ctx Ctx) assignStmt(s *ast.AssignStmt) coq.Binding {
 		return ctx.multipleAssignStmt(s)
 	}
 	lhs := s.Lhs[0]
-	rhs := ctx.expr(s.Rhs[0])
+	if s.Tok == token.ASSIGN && isIdent(lhs, "_") && isPureExpr(s.Rhs[0]) {
+		// _ = x only silences an unused variable warning
+		return coq.NewAnon(coq.Skip)
+	}
+	rhs := ctx.convertedExpr(ctx.typeOf(lhs), s.Rhs[0])
 	assignOps := map[token.Token]coq.BinOp{
 		token.ADD_ASSIGN: coq.OpPlus,
 		token.SUB_ASSIGN: coq.OpMinus,
+		token.MUL_ASSIGN: coq.OpMul,
+		token.QUO_ASSIGN: coq.OpQuot,
+		token.REM_ASSIGN: coq.OpRem,
+		token.AND_ASSIGN: coq.OpAnd,
+		token.OR_ASSIGN:  coq.OpOr,
+		token.XOR_ASSIGN: coq.OpXor,
+		token.SHL_ASSIGN: coq.OpShl,
+		token.SHR_ASSIGN: coq.OpShr,
 	}
 	if op, ok := assignOps[s.Tok]; ok {
-		rhs = coq.BinaryExpr{
-			X:  ctx.expr(lhs),
-			Op: op,
-			Y:  rhs,
+		if op == coq.OpPlus && isString(ctx.typeOf(lhs).Underlying()) {
+			op = coq.OpAppend
 		}
+		return ctx.updateStmt(s, lhs, func(old coq.Expr) coq.Expr {
+			return coq.BinaryExpr{X: old, Op: op, Y: rhs}
+		})
 	} else if s.Tok != token.ASSIGN {
 		ctx.unsupported(s, "%v assignment", s.Tok)
 	}
 	return ctx.assignFromTo(s, lhs, rhs)
 }
 
-func (ctx Ctx) incDecStmt(stmt *ast.IncDecStmt) coq.Binding {
-	ident := getIdentOrNil(stmt.X)
-	if ident == nil {
-		ctx.todo(stmt, "cannot inc/dec non-var")
-		return coq.Binding{}
+// isPureExpr checks if e is a variable or literal, which can be evaluated
+// without any effect
+func isPureExpr(e ast.Expr) bool {
+	switch e := e.(type) {
+	case *ast.Ident, *ast.BasicLit:
+		return true
+	case *ast.ParenExpr:
+		return isPureExpr(e.X)
 	}
-	if !ctx.identInfo(ident).IsPtrWrapped {
-		// should also be able to support variables that are of pointer type
-		ctx.todo(stmt, "can only inc/dec pointer-wrapped variables")
+	return false
+}
+
+// updateStmt translates an update like x += y or x++, which loads lhs, applies
+// update to its value, and stores the result. As in Go, the operands of lhs
+// (such as the slice and index in s[i]) are evaluated only once.
+func (ctx Ctx) updateStmt(s ast.Node, lhs ast.Expr,
+	update func(old coq.Expr) coq.Expr) coq.Binding {
+	var coqStmts []coq.Binding
+	store, load := ctx.assignTarget(s, lhs, bindOperands(&coqStmts))
+	coqStmts = append(coqStmts, store(update(load())))
+	if len(coqStmts) == 1 {
+		return coqStmts[0]
 	}
+	return coq.Binding{Names: make([]string, 0), Expr: coq.BlockExpr{Bindings: coqStmts}}
+}
+
+func (ctx Ctx) incDecStmt(stmt *ast.IncDecStmt) coq.Binding {
 	op := coq.OpPlus
 	if stmt.Tok == token.DEC {
 		op = coq.OpMinus
 	}
-	return ctx.pointerAssign(ident, coq.BinaryExpr{
-		X:  ctx.expr(stmt.X),
-		Op: op,
-		Y:  coq.IntLiteral{1},
+	// x++ is a load, add, and store, so it works on anything assignable (a
+	// var, a field, a pointer dereference, or a slice element)
+	one := ctx.intLiteral(stmt.X, ctx.typeOf(stmt.X), constant.MakeUint64(1))
+	return ctx.updateStmt(stmt, stmt.X, func(old coq.Expr) coq.Expr {
+		return coq.BinaryExpr{X: old, Op: op, Y: one}
 	})
 }
 
-func (ctx Ctx) spawnExpr(thread ast.Expr) coq.SpawnExpr {
-	f, ok := thread.(*ast.FuncLit)
-	if !ok {
-		ctx.futureWork(thread,
-			"only function literal spawns are supported")
-		return coq.SpawnExpr{}
+// spawnExpr translates go func() { ... }(), running the body of the function
+// literal in a new thread.
+//
+// The body is translated like the body of a function, so it can return early
+// (ending the thread) and defer calls. Like any closure, it refers to the
+// variables of the spawning function: a variable declared with var is shared
+// with the new thread, while other local variables are immutable.
+func (ctx Ctx) spawnExpr(f *ast.FuncLit) coq.SpawnExpr {
+	if !hasReturn(f.Body) && !hasDefer(f.Body) {
+		// the thread runs to the end of the body, so it is translated as a
+		// block
+		ctx.fn = funcInfo{}
+		return coq.SpawnExpr{Body: ctx.blockStmt(f.Body, ExprValLocal)}
 	}
-	return coq.SpawnExpr{Body: ctx.blockStmt(f.Body, ExprValLocal)}
+	return coq.SpawnExpr{Body: ctx.funcBody(ctx.typeOf(f).(*types.Signature), f.Type, f.Body)}
 }
 
 func (ctx Ctx) branchStmt(s *ast.BranchStmt) coq.Expr {
@@ -1809,12 +3373,124 @@ func (ctx Ctx) branchStmt(s *ast.BranchStmt) coq.Expr {
 	return nil
 }
 
-// getSpawn returns a non-nil spawned thread if the expression is a go call
+// isModeledType returns true if selectorMethod translates methods of t to
+// operations of the model, rather than to calls
+func isModeledType(t types.Type) bool {
+	return isLockRef(t) || isMutex(t) || isRWMutexRef(t) || isRWMutex(t) ||
+		isCFMutexRef(t) || isCondVar(t) || isWaitGroup(t) || isProphId(t) ||
+		isErrorType(t) || isDisk(t)
+}
+
+// boundCall translates call for a go or defer statement (described by stmt),
+// which evaluates the function and its arguments when the statement runs but
+// makes the call later. The returned bindings evaluate the receiver (or
+// function value) and the arguments, and the returned call only refers to the
+// bound names.
+func (ctx Ctx) boundCall(s ast.Node, call *ast.CallExpr, stmt string) ([]coq.Binding, coq.Expr) {
+	var bindings []coq.Binding
+	bind := func(name string, e coq.Expr) coq.Expr {
+		bindings = append(bindings, coq.Binding{Names: []string{name}, Expr: e})
+		return coq.IdentExpr(name)
+	}
+	var fn coq.Expr
+	var recv []coq.Expr
+	var typeArgs []coq.Expr
+	switch f := call.Fun.(type) {
+	case *ast.FuncLit:
+		fn = ctx.expr(f)
+	case *ast.Ident:
+		if _, ok := ctx.info.Uses[f].(*types.Func); !ok {
+			// a variable of function type
+			fn = bind("$fn", ctx.expr(f))
+			break
+		}
+		fn = ctx.expr(f)
+		typeArgs = ctx.typeList(call, ctx.info.Instances[f].TypeArgs)
+	case *ast.SelectorExpr:
+		if name, ok := ctx.externalFunction(f); ok {
+			fn = coq.GallinaIdent(name)
+			if ctx.info.Selections[f] != nil {
+				recv = append(recv, bind("$recv", ctx.externalReceiver(f)))
+			}
+			break
+		}
+		selectorType, ok := ctx.getType(f.X)
+		if !ok {
+			_, modeled := ctx.modeledPackage(f.X)
+			if pkg, ok := f.X.(*ast.Ident); ok && !modeled {
+				fn = coq.GallinaIdent(coq.PackageIdent{Package: pkg.Name, Ident: f.Sel.Name}.Coq(true))
+				typeArgs = ctx.typeList(call, ctx.info.Instances[f.Sel].TypeArgs)
+			}
+			break
+		}
+		if isModeledType(selectorType) {
+			break
+		}
+		switch selectorType.Underlying().(type) {
+		case *types.Interface:
+			if info, ok := ctx.getInterfaceInfo(selectorType); ok {
+				ctx.dep.addDep(info.name)
+				fn = bind("$m", coq.NewCallExpr(coq.GallinaIdent("struct.get"),
+					coq.StructDesc(info.name),
+					coq.GallinaString(f.Sel.Name),
+					ctx.expr(f.X)))
+			}
+		default:
+			info, ok := ctx.getStructInfo(selectorType)
+			if !ok {
+				break
+			}
+			if sel, ok := ctx.info.Selections[f]; ok && sel.Kind() == types.MethodVal {
+				name, r := ctx.methodTarget(info, f)
+				m := coq.StructMethod(name, f.Sel.Name)
+				ctx.dep.addDep(m)
+				fn = coq.GallinaIdent(m)
+				recv = append(recv, bind("$recv", r))
+			} else {
+				// a field of function type
+				fn = bind("$fn", ctx.structSelector(info, f))
+			}
+		}
+	default:
+		fn = bind("$fn", ctx.expr(f))
+	}
+	if fn == nil {
+		// the call is translated to an operation of the model, which cannot
+		// be separated from its arguments
+		if len(call.Args) > 0 {
+			ctx.unsupported(s, "%s statement of %s with arguments", stmt, types.ExprString(call.Fun))
+		}
+		return bindings, ctx.expr(call)
+	}
+	args := recv
+	for i, arg := range ctx.callArgs(call) {
+		args = append(args, bind(fmt.Sprintf("$a%d", i), arg))
+	}
+	c := coq.NewCallExpr(fn, args...)
+	c.TypeArgs = typeArgs
+	return bindings, c
+}
+
+// goStmt translates a go statement to a forked thread.
+//
+// As in Go, the arguments to the call are evaluated (left to right) before
+// forking, so the new thread sees their values at the go statement even if
+// they are later modified.
 func (ctx Ctx) goStmt(e *ast.GoStmt) coq.Expr {
-	if len(e.Call.Args) > 0 {
-		ctx.unsupported(e, "go statement with parameters")
+	if f, ok := e.Call.Fun.(*ast.FuncLit); ok && len(e.Call.Args) == 0 {
+		return ctx.spawnExpr(f)
+	}
+	if ident, ok := e.Call.Fun.(*ast.Ident); ok && ctx.goBuiltin(ident) {
+		ctx.unsupported(e, "go statement with built-in %s", ident.Name)
+	}
+	if sig, ok := ctx.typeOf(e.Call.Fun).Underlying().(*types.Signature); ok && sig.Variadic() {
+		ctx.unsupported(e, "go statement with a variadic function")
 	}
-	return ctx.spawnExpr(e.Call.Fun)
+	bindings, call := ctx.boundCall(e, e.Call, "go")
+	bindings = append(bindings, coq.NewAnon(coq.SpawnExpr{
+		Body: coq.BlockExpr{Bindings: []coq.Binding{coq.NewAnon(call)}},
+	}))
+	return coq.BlockExpr{Bindings: bindings}
 }
 
 // This function also returns whether the expression has been "finalized",
@@ -1839,6 +3515,8 @@ func (ctx Ctx) stmtInBlock(s ast.Stmt, usage ExprValUsage) (coq.Binding, bool) {
 	switch s := s.(type) {
 	case *ast.IfStmt:
 		return ctx.ifStmt(s, []ast.Stmt{}, usage), true
+	case *ast.SwitchStmt:
+		return ctx.switchStmt(s, []ast.Stmt{}, usage), true
 	case *ast.BlockStmt:
 		return coq.NewAnon(ctx.blockStmt(s, usage)), true
 	}
@@ -1847,11 +3525,17 @@ func (ctx Ctx) stmtInBlock(s ast.Stmt, usage ExprValUsage) (coq.Binding, bool) {
 	var binding coq.Binding = coq.Binding{}
 	switch s := s.(type) {
 	case *ast.ReturnStmt:
-		ctx.futureWork(s, "return in unsupported position")
+		if usage == ExprValLoop {
+			ctx.futureWork(s, "return inside a loop")
+		} else {
+			ctx.futureWork(s, "return in unsupported position")
+		}
 	case *ast.BranchStmt:
 		ctx.futureWork(s, "break/continue in unsupported position")
 	case *ast.GoStmt:
 		binding = coq.NewAnon(ctx.goStmt(s))
+	case *ast.DeferStmt:
+		binding = coq.NewAnon(ctx.deferStmt(s))
 	case *ast.ExprStmt:
 		binding = coq.NewAnon(ctx.expr(s.X))
 	case *ast.AssignStmt:
@@ -1866,8 +3550,10 @@ func (ctx Ctx) stmtInBlock(s ast.Stmt, usage ExprValUsage) (coq.Binding, bool) {
 		binding = coq.NewAnon(ctx.forStmt(s))
 	case *ast.RangeStmt:
 		binding = coq.NewAnon(ctx.rangeStmt(s))
-	case *ast.SwitchStmt:
-		ctx.todo(s, "check for switch statement")
+	case *ast.SendStmt:
+		binding = coq.NewAnon(ctx.sendStmt(s))
+	case *ast.SelectStmt:
+		binding = coq.NewAnon(ctx.selectStmt(s))
 	case *ast.TypeSwitchStmt:
 		ctx.todo(s, "check for type switch statement")
 	default:
@@ -1893,16 +3579,198 @@ func (ctx Ctx) stmt(s ast.Stmt) coq.Binding {
 	return binding
 }
 
-func (ctx Ctx) returnExpr(es []ast.Expr) coq.Expr {
-	if len(es) == 0 {
-		// named returns are not supported, so this must return unit
-		return coq.ReturnExpr{coq.UnitLiteral{}}
+func (ctx Ctx) namedResultsExpr() coq.Expr {
+	var loads coq.TupleExpr
+	for _, r := range ctx.fn.namedResults {
+		loads = append(loads, coq.DerefExpr{
+			X:  coq.IdentExpr(r.Name),
+			Ty: ctx.coqTypeOfType(r, ctx.typeOf(r)),
+		})
 	}
+	return coq.NewTuple(loads)
+}
+
+// runDefers calls the deferred functions of the current function
+func runDefers() coq.Binding {
+	return coq.NewAnon(coq.NewCallExpr(coq.DerefExpr{
+		X:  coq.IdentExpr("$defer"),
+		Ty: deferType,
+	}))
+}
+
+// returnExpr translates a return statement with results es.
+//
+// Following Go, a return first sets the results, then runs deferred calls
+// (which may modify named results), and only then returns.
+func (ctx Ctx) returnExpr(es []ast.Expr) coq.Expr {
 	var exprs coq.TupleExpr
-	for _, r := range es {
-		exprs = append(exprs, ctx.expr(r))
+	for i, r := range es {
+		if len(es) == ctx.fn.results.Len() {
+			exprs = append(exprs, ctx.convertedExpr(ctx.fn.results.At(i).Type(), r))
+		} else {
+			exprs = append(exprs, ctx.expr(r))
+		}
+	}
+	if !ctx.fn.hasDefer {
+		if len(es) == 0 {
+			if len(ctx.fn.namedResults) == 0 {
+				return coq.ReturnExpr{coq.UnitLiteral{}}
+			}
+			return coq.ReturnExpr{ctx.namedResultsExpr()}
+		}
+		return coq.ReturnExpr{coq.NewTuple(exprs)}
+	}
+
+	// bind the results to temporaries before running the deferred calls
+	var bindings []coq.Binding
+	var rets coq.TupleExpr
+	for i := 0; i < ctx.fn.results.Len() && len(es) > 0; i++ {
+		rets = append(rets, coq.IdentExpr(fmt.Sprintf("$r%d", i)))
+	}
+	if len(es) == 1 && len(rets) > 1 {
+		// returning a call with multiple results
+		var names []string
+		for _, r := range rets {
+			names = append(names, string(r.(coq.IdentExpr)))
+		}
+		bindings = append(bindings, coq.Binding{Names: names, Expr: exprs[0]})
+	} else {
+		for i, e := range exprs {
+			bindings = append(bindings, coq.Binding{
+				Names: []string{string(rets[i].(coq.IdentExpr))},
+				Expr:  e,
+			})
+		}
+	}
+	if len(ctx.fn.namedResults) > 0 {
+		for i, r := range rets {
+			result := ctx.fn.namedResults[i]
+			bindings = append(bindings, coq.NewAnon(coq.StoreStmt{
+				Dst: coq.IdentExpr(result.Name),
+				Ty:  ctx.coqTypeOfType(result, ctx.typeOf(result)),
+				X:   r,
+			}))
+		}
+		bindings = append(bindings, runDefers(),
+			coq.NewAnon(ctx.namedResultsExpr()))
+		return coq.ReturnExpr{coq.BlockExpr{Bindings: bindings}}
+	}
+	var result coq.Expr = coq.UnitLiteral{}
+	if len(rets) > 0 {
+		result = coq.NewTuple(rets)
+	}
+	bindings = append(bindings, runDefers(), coq.NewAnon(result))
+	return coq.ReturnExpr{coq.BlockExpr{Bindings: bindings}}
+}
+
+// deferType is the type of the chain of deferred calls
+var deferType = coq.ArrowType{ReturnType: coq.TypeIdent("unitT")}
+
+// hasDefer checks if a function body has any defer statements, not counting
+// those in nested function literals
+func hasDefer(body *ast.BlockStmt) bool {
+	found := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n.(type) {
+		case *ast.DeferStmt:
+			found = true
+		case *ast.FuncLit:
+			return false
+		}
+		return !found
+	})
+	return found
+}
+
+// hasReturn checks if body has any return statements (not counting those in
+// nested function literals)
+func hasReturn(body *ast.BlockStmt) bool {
+	found := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n.(type) {
+		case *ast.ReturnStmt:
+			found = true
+		case *ast.FuncLit:
+			return false
+		}
+		return !found
+	})
+	return found
+}
+
+// funcBody translates the body of a function or function literal.
+//
+// Named results are pointer-wrapped variables initialized to their zero
+// values. If the body defers calls, it also allocates "$defer", the chain of
+// deferred calls, which every return runs.
+func (ctx Ctx) funcBody(sig *types.Signature, t *ast.FuncType,
+	body *ast.BlockStmt) coq.BlockExpr {
+	ctx.fn = funcInfo{results: sig.Results(), hasDefer: hasDefer(body)}
+	var bindings []coq.Binding
+	if t.Results != nil {
+		for _, f := range t.Results.List {
+			for _, name := range f.Names {
+				if name.Name == "_" {
+					ctx.unsupported(name, "anonymous named result")
+				}
+				ctx.addDef(name, identInfo{
+					IsPtrWrapped: true,
+					IsMacro:      false,
+				})
+				ctx.fn.namedResults = append(ctx.fn.namedResults, name)
+				ctx.checkZeroSync(name, ctx.typeOf(name))
+				bindings = append(bindings, coq.Binding{
+					Names: []string{name.Name},
+					Expr: coq.NewCallExpr(coq.GallinaIdent("ref"),
+						coq.NewNullaryCall(coq.GallinaIdent("zero_val"),
+							ctx.coqTypeOfType(name, ctx.typeOf(name)))),
+				})
+			}
+		}
+	}
+	if ctx.fn.hasDefer {
+		bindings = append(bindings, coq.Binding{
+			Names: []string{"$defer"},
+			Expr: coq.RefExpr{
+				X:  coq.FuncLit{Body: coq.Tt},
+				Ty: deferType,
+			},
+		})
+	}
+	b := ctx.blockStmt(body, ExprValReturned)
+	return coq.BlockExpr{Bindings: append(bindings, b.Bindings...)}
+}
+
+// deferStmt adds a call to the chain of deferred calls.
+//
+// As in Go, the function and its arguments are evaluated when the defer
+// statement runs, while the call itself happens when the function returns.
+// Deferred calls do not run on a panic.
+func (ctx Ctx) deferStmt(s *ast.DeferStmt) coq.Expr {
+	if !ctx.fn.hasDefer {
+		ctx.unsupported(s, "defer outside of a function body")
+	}
+	var bindings []coq.Binding
+	var call coq.Expr
+	if ident, ok := s.Call.Fun.(*ast.Ident); ok && ctx.goBuiltin(ident) {
+		call = ctx.expr(s.Call)
+	} else {
+		bindings, call = ctx.boundCall(s, s.Call, "defer")
 	}
-	return coq.ReturnExpr{coq.NewTuple(exprs)}
+	bindings = append(bindings,
+		coq.Binding{
+			Names: []string{"$f"},
+			Expr:  coq.DerefExpr{X: coq.IdentExpr("$defer"), Ty: deferType},
+		},
+		coq.NewAnon(coq.StoreStmt{
+			Dst: coq.IdentExpr("$defer"),
+			Ty:  deferType,
+			X: coq.FuncLit{Body: coq.BlockExpr{Bindings: []coq.Binding{
+				coq.NewAnon(call),
+				coq.NewAnon(coq.NewCallExpr(coq.IdentExpr("$f"))),
+			}}},
+		}))
+	return coq.BlockExpr{Bindings: bindings}
 }
 
 // returnType converts an Ast.FuncType's Results to a Coq return type
@@ -1910,27 +3778,24 @@ func (ctx Ctx) returnType(results *ast.FieldList) coq.Type {
 	if results == nil {
 		return coq.TypeIdent("unitT")
 	}
-	rs := results.List
-	for _, r := range rs {
-		if len(r.Names) > 0 {
-			ctx.unsupported(r, "named returned value")
-			return coq.TypeIdent("<invalid>")
-		}
-	}
 	var ts []coq.Type
-	for _, r := range rs {
-		if len(r.Names) > 0 {
-			ctx.unsupported(r, "named returned value")
-			return coq.TypeIdent("<invalid>")
+	for _, r := range results.List {
+		t := ctx.coqType(r.Type)
+		ts = append(ts, t)
+		// named results can list several results of the same type
+		for i := 1; i < len(r.Names); i++ {
+			ts = append(ts, t)
 		}
-		ts = append(ts, ctx.coqType(r.Type))
 	}
 	return coq.NewTupleType(ts)
 }
 
 func (ctx Ctx) funcDecl(d *ast.FuncDecl) coq.FuncDecl {
 
-	fd := coq.FuncDecl{Name: d.Name.Name, AddTypes: ctx.Config.TypeCheck,
+	// functions marked //goose:no-typecheck are excluded from type checking,
+	// for code that runs fine but isn't well-typed in the model
+	addTypes := ctx.Config.TypeCheck && !hasDirective(d.Doc, "no-typecheck")
+	fd := coq.FuncDecl{Name: coq.GallinaName(d.Name.Name), AddTypes: addTypes,
 		TypeParams: ctx.typeParamList(d.Type.TypeParams),
 	}
 	addSourceDoc(d.Doc, &fd.Comment)
@@ -1957,55 +3822,116 @@ func (ctx Ctx) funcDecl(d *ast.FuncDecl) coq.FuncDecl {
 	fd.Args = append(fd.Args, ctx.paramList(d.Type.Params)...)
 
 	fd.ReturnType = ctx.returnType(d.Type.Results)
-	fd.Body = ctx.blockStmt(d.Body, ExprValReturned)
+	fd.Body = ctx.funcBody(ctx.info.Defs[d.Name].Type().(*types.Signature),
+		d.Type, d.Body)
 	ctx.dep.addName(fd.Name)
 	return fd
 }
 
-func (ctx Ctx) constSpec(spec *ast.ValueSpec) coq.ConstDecl {
+func (ctx Ctx) constSpec(doc *ast.CommentGroup, spec *ast.ValueSpec) coq.ConstDecl {
 	ident := spec.Names[0]
 	cd := coq.ConstDecl{
-		Name:     ident.Name,
+		Name:     coq.GallinaName(ident.Name),
 		AddTypes: ctx.Config.TypeCheck,
 	}
 	ctx.addDef(ident, identInfo{
 		IsPtrWrapped: false,
 		IsMacro:      true,
 	})
+	addSourceDoc(doc, &cd.Comment)
 	addSourceDoc(spec.Comment, &cd.Comment)
+	if len(spec.Values) == 0 {
+		// a var without an initializer
+		cd.Type = ctx.coqType(spec.Type)
+		cd.Val = coq.NewNullaryCall(coq.GallinaIdent("zero_val"), cd.Type)
+		return cd
+	}
 	val := spec.Values[0]
-	cd.Val = ctx.expr(val)
+	cd.Val = ctx.constExpr(val)
 	if spec.Type == nil {
-		cd.Type = ctx.coqTypeOfType(spec, ctx.typeOf(val))
+		ty := ctx.typeOf(val)
+		if basic, ok := ty.(*types.Basic); ok && basic.Kind() == types.UntypedInt {
+			// untyped integer constants are used as uint64s
+			cd.Type = coq.TypeIdent("uint64T")
+		} else {
+			cd.Type = ctx.coqTypeOfType(spec, ty)
+		}
 	} else {
 		cd.Type = ctx.coqType(spec.Type)
 	}
-	cd.Val = ctx.expr(spec.Values[0])
 	return cd
 }
 
+// constExpr translates the value of a constant declaration.
+//
+// Arithmetic on integer constants (eg, 4096 / 8) is folded to a single literal,
+// since Go evaluates constant expressions exactly at compile time; the same
+// goes for string concatenation, boolean operators, and conversions of
+// constants.
+func (ctx Ctx) constExpr(e ast.Expr) coq.Expr {
+	switch e.(type) {
+	case *ast.BinaryExpr, *ast.UnaryExpr, *ast.ParenExpr, *ast.CallExpr:
+		tv := ctx.info.Types[e]
+		if tv.Value == nil {
+			break
+		}
+		switch tv.Value.Kind() {
+		case constant.Int:
+			return ctx.intLiteral(e, tv.Type, tv.Value)
+		case constant.String:
+			return coq.StringLiteral{constant.StringVal(tv.Value)}
+		case constant.Bool:
+			return coq.BoolLiteral(constant.BoolVal(tv.Value))
+		}
+	}
+	return ctx.expr(e)
+}
+
+// constDecl translates each spec of d to a constant, or to a location for a
+// package variable that the package modifies.
+//
+// The doc comment of a parenthesized group is emitted as a comment before its
+// constants, while the doc comment of an ungrouped declaration documents its
+// only constant.
 func (ctx Ctx) constDecl(d *ast.GenDecl) []coq.Decl {
 	var specs []coq.Decl
+	grouped := d.Lparen.IsValid()
+	if grouped && d.Doc != nil && d.Doc.Text() != "" {
+		specs = append(specs, coq.NewComment(d.Doc.Text()))
+	}
 	for _, spec := range d.Specs {
 		vs := spec.(*ast.ValueSpec)
-		ctx.dep.addName(vs.Names[0].Name)
-		specs = append(specs, ctx.constSpec(vs))
+		doc := vs.Doc
+		if !grouped {
+			doc = d.Doc
+		}
+		ctx.dep.addName(coq.GallinaName(vs.Names[0].Name))
+		if ctx.mutableGlobals[ctx.info.Defs[vs.Names[0]]] {
+			specs = append(specs, ctx.globalVarSpec(doc, vs))
+			continue
+		}
+		specs = append(specs, ctx.constSpec(doc, vs))
 	}
 	return specs
 }
 
-func (ctx Ctx) globalVarDecl(d *ast.GenDecl) []coq.Decl {
-	// NOTE: this treats globals as constants, which is unsound but used for a
-	// configurable Debug level in goose-nfsd. Configuration variables should
-	// instead be treated as a non-deterministic constant, assuming they aren't
-	// changed after startup.
-	var specs []coq.Decl
-	for _, spec := range d.Specs {
-		vs := spec.(*ast.ValueSpec)
-		ctx.dep.addName(vs.Names[0].Name)
-		specs = append(specs, ctx.constSpec(vs))
+// globalVarSpec translates a package variable that the package modifies to a
+// function that allocates and initializes its location
+func (ctx Ctx) globalVarSpec(doc *ast.CommentGroup, spec *ast.ValueSpec) coq.GlobalVarDecl {
+	cd := ctx.constSpec(doc, spec)
+	return coq.GlobalVarDecl{
+		Name:    cd.Name,
+		Type:    cd.Type,
+		Init:    cd.Val,
+		Comment: cd.Comment,
 	}
-	return specs
+}
+
+func (ctx Ctx) globalVarDecl(d *ast.GenDecl) []coq.Decl {
+	// NOTE: globals that are never modified are treated as constants (eg, a
+	// configurable Debug level in goose-nfsd), while the rest are locations
+	// (see globalVarSpec).
+	return ctx.constDecl(d)
 }
 
 func stringLitValue(lit *ast.BasicLit) string {
@@ -2031,6 +3957,7 @@ var builtinImports = map[string]bool{
 	"sync":                                        true,
 	"log":                                         true,
 	"fmt":                                         true,
+	"errors":                                      true,
 }
 
 var ffiMapping = map[string]string{
@@ -2065,111 +3992,10 @@ func (ctx Ctx) imports(d []ast.Spec) []coq.Decl {
 	return decls
 }
 
-func (ctx Ctx) exprInterface(cvs []coq.Decl, expr ast.Expr, d *ast.FuncDecl) []coq.Decl {
-	switch f := expr.(type) {
-	case *ast.UnaryExpr:
-		if left, ok := f.X.(*ast.BinaryExpr); ok {
-			if call, ok := left.X.(*ast.CallExpr); ok {
-				cvs = ctx.callExprInterface(cvs, call, d)
-			}
-		}
-	case *ast.BinaryExpr:
-		if left, ok := f.X.(*ast.BinaryExpr); ok {
-			if call, ok := left.X.(*ast.CallExpr); ok {
-				cvs = ctx.callExprInterface(cvs, call, d)
-			}
-		}
-		if right, ok := f.Y.(*ast.BinaryExpr); ok {
-			if call, ok := right.X.(*ast.CallExpr); ok {
-				cvs = ctx.callExprInterface(cvs, call, d)
-			}
-		}
-	case *ast.CallExpr:
-		cvs = ctx.callExprInterface(cvs, f, d)
-	}
-	return cvs
-}
-
-func (ctx Ctx) stmtInterface(cvs []coq.Decl, stmt ast.Stmt, d *ast.FuncDecl) []coq.Decl {
-	switch f := stmt.(type) {
-	case *ast.ReturnStmt:
-		for _, result := range f.Results {
-			cvs = ctx.exprInterface(cvs, result, d)
-		}
-		if len(f.Results) > 0 {
-			if results, ok := f.Results[0].(*ast.BinaryExpr); ok {
-				if call, ok := results.X.(*ast.CallExpr); ok {
-					cvs = ctx.callExprInterface(cvs, call, d)
-				}
-			}
-		}
-	case *ast.IfStmt:
-		if call, ok := f.Cond.(*ast.CallExpr); ok {
-			cvs = ctx.callExprInterface(cvs, call, d)
-		}
-	case *ast.ExprStmt:
-		if call, ok := f.X.(*ast.CallExpr); ok {
-			cvs = ctx.callExprInterface(cvs, call, d)
-		}
-	case *ast.AssignStmt:
-		if call, ok := f.Rhs[0].(*ast.CallExpr); ok {
-			cvs = ctx.callExprInterface(cvs, call, d)
-		}
-	}
-	return cvs
-}
-
-// TODO: this is a hack, should have a better scheme for putting
-// interface/implementation types into the conversion name
-func unqualifyName(name string) string {
-	components := strings.Split(name, ".")
-	// return strings.Join(components[1:], "")
-	return components[len(components)-1]
-}
-
-func (ctx Ctx) callExprInterface(cvs []coq.Decl, r *ast.CallExpr, d *ast.FuncDecl) []coq.Decl {
-	interfaceName := ""
-	methods := []string{}
-	if signature, ok := ctx.typeOf(r.Fun).(*types.Signature); ok {
-		params := signature.Params()
-		for j := 0; j < params.Len(); j++ {
-			interfaceName = params.At(j).Type().String()
-			interfaceName = unqualifyName(interfaceName)
-			if v, ok := params.At(j).Type().Underlying().(*types.Interface); ok {
-				for m := 0; m < v.NumMethods(); m++ {
-					methods = append(methods, v.Method(m).Name())
-				}
-			}
-		}
-		for _, arg := range r.Args {
-			structName := ctx.typeOf(arg).String()
-			structName = unqualifyName(structName)
-			if _, ok := ctx.typeOf(arg).Underlying().(*types.Struct); ok {
-				cv := coq.StructToInterface{Struct: structName, Interface: interfaceName, Methods: methods}
-				if len(cv.Coq(true)) > 1 && len(cv.MethodList()) > 0 {
-					cvs = append(cvs, cv)
-				}
-			}
-		}
-	}
-	return cvs
-}
-
 func (ctx Ctx) maybeDecls(d ast.Decl) []coq.Decl {
 	switch d := d.(type) {
 	case *ast.FuncDecl:
-		cvs := []coq.Decl{}
-		for _, stmt := range d.Body.List {
-			cvs = ctx.stmtInterface(cvs, stmt, d)
-		}
-		fd := ctx.funcDecl(d)
-		results := []coq.Decl{}
-		if len(cvs) > 0 {
-			results = append(cvs, fd)
-		} else {
-			results = []coq.Decl{fd}
-		}
-		return results
+		return []coq.Decl{ctx.funcDecl(d)}
 	case *ast.GenDecl:
 		switch d.Tok {
 		case token.IMPORT:
@@ -2183,7 +4009,11 @@ func (ctx Ctx) maybeDecls(d ast.Decl) []coq.Decl {
 				ctx.noExample(d, "multiple specs in a type decl")
 			}
 			spec := d.Specs[0].(*ast.TypeSpec)
-			ctx.dep.addName(spec.Name.Name)
+			if spec.Assign.IsValid() {
+				// uses of an alias are translated to its target (see coqType)
+				return nil
+			}
+			ctx.dep.addName(coq.GallinaName(spec.Name.Name))
 			ty := ctx.typeDecl(d.Doc, spec)
 			return []coq.Decl{ty}
 		default:
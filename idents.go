@@ -64,8 +64,20 @@ func (ctx Ctx) identInfo(ident *ast.Ident) identInfo {
 	if ident.Name == "_" {
 		ctx.unsupported(ident, "unexpected use of anonymous identifier")
 	}
-	scope := ctx.info.Uses[ident].Parent()
-	return ctx.idents.lookupName(scope, ident.Name)
+	obj := ctx.info.Uses[ident]
+	// package-level constants and variables are Gallina definitions even when
+	// they are declared after their use, before any addDef for them has run
+	if isPackageLevel(obj) {
+		switch obj.(type) {
+		case *types.Const, *types.Var:
+			return identInfo{IsPtrWrapped: false, IsMacro: true}
+		}
+	}
+	return ctx.idents.lookupName(obj.Parent(), ident.Name)
+}
+
+func isPackageLevel(obj types.Object) bool {
+	return obj.Pkg() != nil && obj.Parent() == obj.Pkg().Scope()
 }
 
 func (ctx Ctx) doesDefHaveInfo(ident *ast.Ident) bool {
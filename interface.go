@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"go/ast"
 	"go/token"
+	"go/types"
 	"path"
+	"path/filepath"
 	"sort"
 	"strings"
 	"sync"
@@ -28,7 +30,8 @@ func (ctx Ctx) declsOrError(stmt ast.Decl) (decls []coq.Decl, err error) {
 			}
 		}
 	}()
-	return ctx.maybeDecls(stmt), nil
+	decls = ctx.maybeDecls(stmt)
+	return decls, nil
 }
 
 func filterImports(decls []coq.Decl) (nonImports []coq.Decl, imports coq.ImportDecls) {
@@ -43,6 +46,50 @@ func filterImports(decls []coq.Decl) (nonImports []coq.Decl, imports coq.ImportD
 	return
 }
 
+// externalOnlyImports finds the imported packages that fs use only through
+// functions modeled by Config.ExternalFunctions (and pointers to their types,
+// which are translated as ptrT), so the translation never refers to them
+func (ctx Ctx) externalOnlyImports(fs []NamedFile) map[string]bool {
+	external := make(map[string]bool)
+	needed := make(map[string]bool)
+	for _, f := range fs {
+		ptrOnly := make(map[*ast.Ident]bool)
+		ast.Inspect(f.Ast, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.StarExpr:
+				if sel, ok := n.X.(*ast.SelectorExpr); ok {
+					ptrOnly[sel.Sel] = true
+				}
+			case *ast.Ident:
+				obj := ctx.info.Uses[n]
+				if obj == nil || obj.Pkg() == nil || obj.Pkg().Path() == ctx.pkgPath {
+					return true
+				}
+				pkgPath := obj.Pkg().Path()
+				switch obj := obj.(type) {
+				case *types.PkgName:
+					return true
+				case *types.Func:
+					if _, ok := ctx.ExternalFunctions[obj.FullName()]; ok {
+						external[pkgPath] = true
+						return true
+					}
+				case *types.TypeName:
+					if ptrOnly[n] {
+						return true
+					}
+				}
+				needed[pkgPath] = true
+			}
+			return true
+		})
+	}
+	for pkgPath := range needed {
+		delete(external, pkgPath)
+	}
+	return external
+}
+
 type declId struct {
 	fileIdx int
 	declIdx int
@@ -51,6 +98,11 @@ type declId struct {
 type depTracker struct {
 	names []string
 	deps  []string
+	// declarations synthesized by the translation (struct-to-interface
+	// conversions and anonymous structs), emitted before the current declaration
+	// unless an earlier declaration already synthesized them
+	synthesized      []coq.Decl
+	synthesizedNames []string
 }
 
 func (dt *depTracker) addName(s string) {
@@ -61,21 +113,69 @@ func (dt *depTracker) addDep(s string) {
 	dt.deps = append(dt.deps, s)
 }
 
+func (dt *depTracker) addSynthesized(name string, d coq.Decl) {
+	for _, existing := range dt.synthesizedNames {
+		if existing == name {
+			return
+		}
+	}
+	dt.synthesizedNames = append(dt.synthesizedNames, name)
+	dt.synthesized = append(dt.synthesized, d)
+}
+
+// declName gives the identifier declared by d, for reporting errors about the
+// whole declaration
+func declName(d ast.Decl) ast.Node {
+	switch d := d.(type) {
+	case *ast.FuncDecl:
+		return d.Name
+	case *ast.GenDecl:
+		if len(d.Specs) == 1 {
+			switch spec := d.Specs[0].(type) {
+			case *ast.TypeSpec:
+				return spec.Name
+			case *ast.ValueSpec:
+				return spec.Names[0]
+			}
+		}
+	}
+	return d
+}
+
 // Decls converts an entire package (possibly multiple files) to a list of decls
+//
+// Declarations are emitted in source order, except that a declaration's
+// dependencies are emitted before it, since Coq requires definitions to
+// precede their uses. Dependency cycles (other than a function calling itself)
+// are reported as errors.
 func (ctx Ctx) Decls(fs ...NamedFile) (imports coq.ImportDecls, decls []coq.Decl, errs []error) {
 	declGroups := make(map[declId][]coq.Decl)
 	declDeps := make(map[declId][]string)
 	nameDecls := make(map[string]declId)
 	generated := make(map[declId]bool)
-
+	inProgress := make(map[declId]bool)
+	// the declarations synthesized for each declaration, which are emitted
+	// once for the whole package
+	declSynthesized := make(map[declId]*depTracker)
+	emittedSynthesized := make(map[string]bool)
+
+	skipped := ctx.skippedObjects(fs)
+	externalOnly := ctx.externalOnlyImports(fs)
+	ctx.mutableGlobals = ctx.modifiedGlobals(fs)
 	for fi, f := range fs {
 		for di, d := range f.Ast.Decls {
 			ctx.dep = &depTracker{}
 
 			id := declId{fi, di}
+			if isSkipped(d) {
+				continue
+			}
+			errs = append(errs, ctx.skippedRefs(d, skipped)...)
 			newDecls, err := ctx.declsOrError(d)
 			if err != nil {
 				errs = append(errs, err)
+			} else {
+				declSynthesized[id] = ctx.dep
 			}
 
 			// fmt.Printf("Generated %s, depends on %s\n", ctx.dep.names, ctx.dep.deps)
@@ -96,13 +196,22 @@ func (ctx Ctx) Decls(fs ...NamedFile) (imports coq.ImportDecls, decls []coq.Decl
 			return
 		}
 		generated[id] = true
+		inProgress[id] = true
 
 		for _, dep := range declDeps[id] {
 			depid, ok := nameDecls[dep]
-			if ok {
-				processDecl(depid, dep)
+			if !ok || depid == id {
+				continue
+			}
+			if inProgress[depid] {
+				n := declName(fs[id.fileIdx].Ast.Decls[id.declIdx])
+				errs = append(errs, ctx.newError("unsupported", n,
+					"dependency cycle through %s (mutually recursive declarations)", dep))
+				continue
 			}
+			processDecl(depid, dep)
 		}
+		inProgress[id] = false
 
 		if lastFile != id.fileIdx && ident != "" {
 			f := fs[id.fileIdx]
@@ -111,9 +220,21 @@ func (ctx Ctx) Decls(fs ...NamedFile) (imports coq.ImportDecls, decls []coq.Decl
 			lastFile = id.fileIdx
 		}
 
+		if dt := declSynthesized[id]; dt != nil {
+			for i, name := range dt.synthesizedNames {
+				if !emittedSynthesized[name] {
+					emittedSynthesized[name] = true
+					decls = append(decls, dt.synthesized[i])
+				}
+			}
+		}
 		newDecls, newImports := filterImports(declGroups[id])
 		decls = append(decls, newDecls...)
-		imports = append(imports, newImports...)
+		for _, imp := range newImports {
+			if !externalOnly[imp.Path] {
+				imports = append(imports, imp)
+			}
+		}
 	}
 
 	for fi, f := range fs {
@@ -129,9 +250,113 @@ func (ctx Ctx) Decls(fs ...NamedFile) (imports coq.ImportDecls, decls []coq.Decl
 			processDecl(declId{fi, di}, "")
 		}
 	}
+	// the package's initialization allocates its modified variables, in the
+	// order they are declared (after the declarations they depend on)
+	var globals []coq.GlobalVarDecl
+	for _, d := range decls {
+		if d, ok := d.(coq.GlobalVarDecl); ok {
+			globals = append(globals, d)
+		}
+	}
+	if len(globals) > 0 {
+		decls = append(decls, coq.NewGlobalsInit(globals))
+	}
 	return
 }
 
+// isSkipped checks if d is marked with a //goose:skip directive, which omits
+// it from the translation
+func isSkipped(d ast.Decl) bool {
+	switch d := d.(type) {
+	case *ast.FuncDecl:
+		return hasDirective(d.Doc, "skip")
+	case *ast.GenDecl:
+		return hasDirective(d.Doc, "skip")
+	}
+	return false
+}
+
+// skippedObjects gathers the objects declared by skipped declarations
+func (ctx Ctx) skippedObjects(fs []NamedFile) map[types.Object]bool {
+	skipped := make(map[types.Object]bool)
+	for _, f := range fs {
+		for _, d := range f.Ast.Decls {
+			if !isSkipped(d) {
+				continue
+			}
+			ast.Inspect(d, func(n ast.Node) bool {
+				if id, ok := n.(*ast.Ident); ok {
+					if obj := ctx.info.Defs[id]; obj != nil && isPackageLevel(obj) {
+						skipped[obj] = true
+					}
+				}
+				// declarations inside function bodies are not package-level
+				_, isBody := n.(*ast.BlockStmt)
+				return !isBody
+			})
+			if d, ok := d.(*ast.FuncDecl); ok && d.Recv != nil {
+				// methods are not in the package scope
+				skipped[ctx.info.Defs[d.Name]] = true
+			}
+		}
+	}
+	return skipped
+}
+
+// modifiedGlobals finds the package variables that fs modify, which are
+// translated as locations rather than as constants.
+//
+// Initialized error variables (e.g., var ErrNotFound = errors.New("not found"))
+// are also locations, since creating an error allocates it (see isErrorType)
+// and every use of the variable must be the same error.
+func (ctx Ctx) modifiedGlobals(fs []NamedFile) map[types.Object]bool {
+	var nodes []ast.Node
+	for _, f := range fs {
+		nodes = append(nodes, f.Ast)
+	}
+	globals := make(map[types.Object]bool)
+	for obj := range ctx.modifiedObjects(nodes...) {
+		if _, ok := obj.(*types.Var); ok && isPackageLevel(obj) &&
+			obj.Pkg().Path() == ctx.pkgPath {
+			globals[obj] = true
+		}
+	}
+	for _, f := range fs {
+		for _, d := range f.Ast.Decls {
+			d, ok := d.(*ast.GenDecl)
+			if !ok || d.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range d.Specs {
+				spec := spec.(*ast.ValueSpec)
+				for _, name := range spec.Names {
+					if len(spec.Values) > 0 && isErrorType(ctx.typeOf(name)) {
+						globals[ctx.info.Defs[name]] = true
+					}
+				}
+			}
+		}
+	}
+	return globals
+}
+
+// skippedRefs reports references in d to declarations that were skipped, which
+// would otherwise be dangling references in the translation
+func (ctx Ctx) skippedRefs(d ast.Decl, skipped map[types.Object]bool) []error {
+	var errs []error
+	if len(skipped) == 0 {
+		return nil
+	}
+	ast.Inspect(d, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && skipped[ctx.info.Uses[id]] {
+			errs = append(errs, ctx.newError("unsupported", id,
+				"reference to %s, which is marked //goose:skip", id.Name))
+		}
+		return true
+	})
+	return errs
+}
+
 type MultipleErrors []error
 
 func (es MultipleErrors) Error() string {
@@ -177,6 +402,30 @@ func sortedFiles(fileNames []string, fileAsts []*ast.File) []NamedFile {
 type Translator struct {
 	TypeCheck             bool
 	AddSourceFileComments bool
+	// ImportPrefix is the logical path of the GooseLang library
+	// (coq.DefaultImportPrefix if empty)
+	ImportPrefix string
+	// PackagePrefix is the logical path where imports of other translated
+	// packages are required from (coq.DefaultPackagePrefix if empty)
+	PackagePrefix string
+	// RequireExport re-exports the prelude and imports from generated files
+	RequireExport bool
+	// AssertFunctions names functions whose calls are translated as assertions
+	// (see Config.AssertFunctions)
+	AssertFunctions []string
+	// NoSprintf reports fmt.Sprintf as unsupported rather than translating
+	// its format string
+	NoSprintf bool
+	// ExternalFunctions maps the full names of functions and methods from
+	// other packages to the Gallina names of their models (see
+	// Config.ExternalFunctions)
+	ExternalFunctions map[string]string
+	// IncludeTests translates _test.go files along with the rest of each
+	// package (by default they are skipped), as well as external test
+	// packages
+	IncludeTests bool
+	// Printer is the layout of the generated code (see coq.PrinterConfig)
+	Printer coq.PrinterConfig
 }
 
 func pkgErrors(errors []packages.Error) error {
@@ -187,93 +436,288 @@ func pkgErrors(errors []packages.Error) error {
 	return MultipleErrors(errs)
 }
 
+// Result is the translation of a package, with information about the
+// translation for tools that post-process it or embed goose.
+type Result struct {
+	// PkgPath is the Go import path of the package
+	PkgPath string
+	// File is the translation, which is partial if there are Errors
+	File coq.File
+	// Imports are the import paths of the Go packages the translation
+	// requires (not including those built in to GooseLang)
+	Imports []string
+	// Errors are the reasons the translation is incomplete, each a
+	// *ConversionError with its source position
+	Errors []error
+	// Warnings are caveats about code that was translated
+	Warnings []Warning
+	// Renamed maps the Go names of the package's declarations that are
+	// reserved in Coq to their Gallina names (see coq.GallinaName)
+	Renamed map[string]string
+	// LoadErr is non-nil if the package could not be loaded, in which case
+	// nothing was translated
+	LoadErr error
+}
+
+// Err summarizes the problems with the translation as a single error, or
+// returns nil if it succeeded.
+func (r Result) Err() error {
+	if r.LoadErr != nil {
+		return r.LoadErr
+	}
+	if len(r.Errors) != 0 {
+		return errors.Wrap(MultipleErrors(r.Errors), "conversion failed")
+	}
+	return nil
+}
+
+// renamedDecls finds the package-level names in pkg that are renamed in Coq
+func renamedDecls(pkg *types.Package) map[string]string {
+	renamed := make(map[string]string)
+	for _, name := range pkg.Scope().Names() {
+		if coqName := coq.GallinaName(name); coqName != name {
+			renamed[name] = coqName
+		}
+	}
+	return renamed
+}
+
 // translatePackage translates an entire package to a single Coq file.
 //
 // If the source directory has multiple source files, these are processed in
-// alphabetical order; this must be a topological sort of the definitions or the
-// Coq code will be out-of-order. Sorting ensures the results are stable
-// and not dependent on map or directory iteration order.
-func (tr Translator) translatePackage(pkg *packages.Package) (coq.File, error) {
+// alphabetical order, and declarations are emitted in source order except where
+// one is moved after the declarations it depends on (even from a later file).
+// Sorting ensures the results are stable and not dependent on map or directory
+// iteration order.
+func (tr Translator) translatePackage(pkg *packages.Package) Result {
 	if len(pkg.Errors) > 0 {
-		return coq.File{}, errors.Errorf(
-			"could not load package %v:\n%v", pkg.PkgPath,
-			pkgErrors(pkg.Errors))
+		return Result{
+			PkgPath: pkg.PkgPath,
+			LoadErr: errors.Errorf(
+				"could not load package %v:\n%v", pkg.PkgPath,
+				pkgErrors(pkg.Errors)),
+		}
 	}
 	ctx := NewPkgCtx(pkg, tr)
 	files := sortedFiles(pkg.CompiledGoFiles, pkg.Syntax)
+	return tr.translateFiles(ctx, pkg, files, nil)
+}
 
+// translateFiles translates some files of a package to a single Coq file,
+// reporting errs along with any translation errors
+func (tr Translator) translateFiles(ctx Ctx, pkg *packages.Package,
+	files []NamedFile, errs []error) Result {
 	coqFile := coq.File{
-		PkgPath:   pkg.PkgPath,
-		GoPackage: pkg.Name,
+		ImportPrefix:  ctx.Config.ImportPrefix,
+		PackagePrefix: ctx.Config.PackagePrefix,
+		RequireExport: ctx.Config.RequireExport,
+		PkgPath:       pkg.PkgPath,
+		GoPackage:     pkg.Name,
+		Printer:       ctx.Config.Printer,
 	}
-	coqFile.ImportHeader, coqFile.Footer = ffiHeaderFooter(ctx.Config.Ffi)
+	coqFile.ImportHeader, coqFile.Footer = ffiHeaderFooter(ctx.Config.Ffi,
+		ctx.Config.ImportPrefix, ctx.Config.RequireExport)
 
-	imports, decls, errs := ctx.Decls(files...)
+	imports, decls, declErrs := ctx.Decls(files...)
 	coqFile.Imports = imports
 	coqFile.Decls = decls
-	if len(errs) != 0 {
-		return coqFile, errors.Wrap(MultipleErrors(errs),
-			"conversion failed")
+	errs = append(errs, declErrs...)
+	r := Result{
+		PkgPath:  pkg.PkgPath,
+		File:     coqFile,
+		Errors:   errs,
+		Warnings: ctx.Warnings(),
 	}
-	return coqFile, nil
+	for _, imp := range imports {
+		r.Imports = append(r.Imports, imp.Path)
+	}
+	r.Renamed = renamedDecls(pkg.Types)
+	return r
 }
 
-func ffiHeaderFooter(ffi string) (header string, footer string) {
+func ffiHeaderFooter(ffi string, prefix string,
+	export bool) (header string, footer string) {
 	if ffi == "none" {
 		header = "Section code.\n" +
 			"Context `{ext_ty: ext_types}.\n" +
 			"Local Coercion Var' s: expr := Var s."
 		footer = "\nEnd code.\n"
 	} else {
-		header = fmt.Sprintf("From Perennial.goose_lang Require Import ffi."+
-			"%s_prelude.", ffi)
+		require := "Import"
+		if export {
+			require = "Export"
+		}
+		header = fmt.Sprintf("From %s Require %s ffi.%s_prelude.",
+			prefix, require, ffi)
 	}
 	return
 }
 
 // newPackageConfig creates a package loading configuration suitable for
 // Goose translation.
-func newPackageConfig(modDir string) *packages.Config {
+func newPackageConfig(modDir string, tests bool) *packages.Config {
 	mode := packages.NeedName | packages.NeedCompiledGoFiles
-	mode |= packages.NeedImports
+	mode |= packages.NeedImports | packages.NeedModule
 	mode |= packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo
 	return &packages.Config{
 		Dir:        modDir,
 		Mode:       mode,
+		Tests:      tests,
 		BuildFlags: []string{"-tags", "goose"},
 		Fset:       token.NewFileSet(),
 	}
 }
 
-// TranslatePackages loads packages by a list of patterns and translates them
-// all, producing one file per matched package.
+// TranslateFile translates a single file of a package, for example to quickly
+// check a translation while working on it.
 //
-// The errs list contains errors corresponding to each package (in parallel with
-// the files list). patternErr is only non-nil if the patterns themselves have
-// a syntax error.
-func (tr Translator) TranslatePackages(modDir string,
-	pkgPattern ...string) (files []coq.File, errs []error, patternErr error) {
-	pkgs, err := packages.Load(newPackageConfig(modDir), pkgPattern...)
+// The rest of the package is loaded to type check the file, but only the
+// file's declarations are translated, so references to declarations in other
+// files of the package are reported as errors. A relative file is interpreted
+// relative to modDir.
+func (tr Translator) TranslateFile(modDir string, file string) (coq.File, error) {
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(modDir, file)
+	}
+	file, err := filepath.Abs(file)
 	if err != nil {
-		return nil, nil, err
+		return coq.File{}, err
+	}
+	pkgs, err := packages.Load(newPackageConfig(modDir, false), "file="+file)
+	if err != nil {
+		return coq.File{}, err
+	}
+	if len(pkgs) != 1 {
+		return coq.File{}, errors.Errorf("%s matched %d packages", file, len(pkgs))
+	}
+	pkg := pkgs[0]
+	if len(pkg.Errors) > 0 {
+		return coq.File{}, errors.Errorf(
+			"could not load package %v:\n%v", pkg.PkgPath,
+			pkgErrors(pkg.Errors))
+	}
+	ctx := NewPkgCtx(pkg, tr)
+	for i, name := range pkg.CompiledGoFiles {
+		if name == file {
+			f := NamedFile{Path: name, Ast: pkg.Syntax[i]}
+			r := tr.translateFiles(ctx, pkg, []NamedFile{f}, ctx.otherFileRefs(f))
+			return r.File, r.Err()
+		}
+	}
+	return coq.File{}, errors.Errorf("%s is not a source file of %s",
+		file, pkg.PkgPath)
+}
+
+// otherFileRefs reports references in f to package-level declarations from
+// other files of the same package, which are not translated along with f
+func (ctx Ctx) otherFileRefs(f NamedFile) []error {
+	var errs []error
+	reported := make(map[types.Object]bool)
+	ast.Inspect(f.Ast, func(n ast.Node) bool {
+		id, ok := n.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		obj := ctx.info.Uses[id]
+		if obj == nil || obj.Pkg() == nil || obj.Pkg().Path() != ctx.pkgPath {
+			return true
+		}
+		switch obj.(type) {
+		case *types.Func:
+			// functions and methods
+		case *types.TypeName, *types.Const, *types.Var:
+			if obj.Parent() != obj.Pkg().Scope() {
+				return true
+			}
+		default:
+			return true
+		}
+		declFile := ctx.Fset.Position(obj.Pos()).Filename
+		if declFile == f.Path || reported[obj] {
+			return true
+		}
+		reported[obj] = true
+		errs = append(errs, ctx.newError("unsupported", id,
+			"reference to %s from %s, which is not being translated",
+			id.Name, path.Base(declFile)))
+		return true
+	})
+	return errs
+}
+
+// testVariants selects the packages to translate when loading with tests: a
+// package with in-package test files is loaded both with and without them, and
+// only the variant with tests is kept. The generated test main packages are
+// dropped.
+func testVariants(pkgs []*packages.Package) []*packages.Package {
+	hasTests := make(map[string]bool)
+	for _, pkg := range pkgs {
+		// test variants have IDs like "path [path.test]"
+		if pkg.ID != pkg.PkgPath {
+			hasTests[pkg.PkgPath] = true
+		}
+	}
+	var selected []*packages.Package
+	for _, pkg := range pkgs {
+		if strings.HasSuffix(pkg.PkgPath, ".test") {
+			continue
+		}
+		if pkg.ID == pkg.PkgPath && hasTests[pkg.PkgPath] {
+			continue
+		}
+		selected = append(selected, pkg)
+	}
+	return selected
+}
+
+// Translate loads packages by a list of patterns and translates them all,
+// producing one Result per matched package.
+//
+// err is only non-nil if the patterns themselves could not be loaded; problems
+// with individual packages are reported in their Result.
+func (tr Translator) Translate(modDir string,
+	pkgPattern ...string) (results []Result, err error) {
+	pkgs, err := packages.Load(newPackageConfig(modDir, tr.IncludeTests), pkgPattern...)
+	if err != nil {
+		return nil, err
+	}
+	if tr.IncludeTests {
+		pkgs = testVariants(pkgs)
 	}
 	if len(pkgs) == 0 {
 		// consider matching nothing to be an error, unlike packages.Load
-		return nil, nil,
-			errors.New("patterns matched no packages")
+		return nil, errors.New("patterns matched no packages")
 	}
-	files = make([]coq.File, len(pkgs))
-	errs = make([]error, len(pkgs))
+	results = make([]Result, len(pkgs))
 	var wg sync.WaitGroup
 	wg.Add(len(pkgs))
 	for i, pkg := range pkgs {
 		go func(i int, pkg *packages.Package) {
-			f, err := tr.translatePackage(pkg)
-			files[i] = f
-			errs[i] = err
+			results[i] = tr.translatePackage(pkg)
 			wg.Done()
 		}(i, pkg)
 	}
 	wg.Wait()
+	return results, nil
+}
+
+// TranslatePackages loads packages by a list of patterns and translates them
+// all, producing one file per matched package.
+//
+// The errs list contains errors corresponding to each package (in parallel with
+// the files list). patternErr is only non-nil if the patterns themselves have
+// a syntax error.
+func (tr Translator) TranslatePackages(modDir string,
+	pkgPattern ...string) (files []coq.File, errs []error, patternErr error) {
+	results, err := tr.Translate(modDir, pkgPattern...)
+	if err != nil {
+		return nil, nil, err
+	}
+	files = make([]coq.File, len(results))
+	errs = make([]error, len(results))
+	for i, r := range results {
+		files[i] = r.File
+		errs[i] = r.Err()
+	}
 	return
 }
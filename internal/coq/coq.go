@@ -1,12 +1,15 @@
 package coq
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"fmt"
 	"io"
 	"path"
 	"path/filepath"
 	"sort"
 	"strings"
+	"unicode/utf8"
 )
 
 func addParens(needs_paren bool, expr string) string {
@@ -18,9 +21,14 @@ func addParens(needs_paren bool, expr string) string {
 }
 
 // buffer is a simple indenting pretty printer
+//
+// Indentation always uses spaces. Lines are otherwise added as is, since a tab
+// might be part of a string literal, but comments have their tabs expanded
+// (see expandTabs) so they line up with the surrounding code.
 type buffer struct {
 	lines       []string
 	indentLevel int
+	cfg         PrinterConfig
 }
 
 func (pp buffer) indentation() string {
@@ -52,16 +60,36 @@ func (pp *buffer) Indent(spaces int) {
 	pp.indentLevel += spaces
 }
 
+// Block adds prefix followed by the formatted text, indenting subsequent lines
+// to line up after the prefix, which stays in effect for later lines until the
+// returned amount is removed with Indent.
+//
+// If prefix has several lines, the text lines up after its last line.
 func (pp *buffer) Block(prefix string, format string, args ...interface{}) int {
-	pp.AddLine(prefix + indent(len(prefix), fmt.Sprintf(format, args...)))
-	pp.Indent(len(prefix))
-	return len(prefix)
+	width := len(prefix) - (strings.LastIndex(prefix, "\n") + 1)
+	pp.AddLine(prefix + indent(width, fmt.Sprintf(format, args...)))
+	pp.Indent(width)
+	return width
 }
 
 func (pp buffer) Build() string {
 	return strings.Join(pp.lines, "\n")
 }
 
+// WriteLines writes the same output as Build to w, without building the whole
+// string first
+func (pp buffer) WriteLines(w io.Writer) error {
+	for i, line := range pp.lines {
+		if i > 0 {
+			line = "\n" + line
+		}
+		if _, err := io.WriteString(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func indent(spaces int, s string) string {
 	lines := strings.Split(s, "\n")
 	indentation := strings.Repeat(" ", spaces)
@@ -81,12 +109,135 @@ func (pp *buffer) AddComment(c string) {
 	// these hacks ensure that Go comments don't insert stray Coq comments
 	c = strings.ReplaceAll(c, "(*", "( *")
 	c = strings.ReplaceAll(c, "*)", "* )")
+	c = expandTabs(c)
 	indent := pp.Block("(* ", "%s *)", c)
 	pp.Indent(-indent)
 }
 
+// PrinterConfig controls the layout of the printed Coq code.
+//
+// The zero value gives the default layout.
+type PrinterConfig struct {
+	// IndentWidth is the number of spaces for each level of nesting (2 if
+	// zero). Continuation lines that line up with something on the previous
+	// line are aligned regardless.
+	IndentWidth int
+	// MaxWidth is the longest a function literal or if branch can be and still
+	// be printed on the same line as its header; longer ones are wrapped onto
+	// their own lines. If zero, they are only wrapped when they take multiple
+	// lines anyway.
+	MaxWidth int
+}
+
+func (cfg PrinterConfig) indent() int {
+	if cfg.IndentWidth == 0 {
+		return 2
+	}
+	return cfg.IndentWidth
+}
+
+// fits checks if code can be printed compactly, on a single line
+func (cfg PrinterConfig) fits(code string) bool {
+	if strings.ContainsRune(code, '\n') {
+		return false
+	}
+	return cfg.MaxWidth == 0 || utf8.RuneCountInString(code) <= cfg.MaxWidth
+}
+
+// layoutExpr is implemented by expressions whose output depends on the
+// PrinterConfig (generally because they have subexpressions)
+type layoutExpr interface {
+	coq(cfg PrinterConfig, needs_paren bool) string
+}
+
+// expr prints e using the layout in cfg
+func (cfg PrinterConfig) expr(e Expr, needs_paren bool) string {
+	if e, ok := e.(layoutExpr); ok {
+		return e.coq(cfg, needs_paren)
+	}
+	return e.Coq(needs_paren)
+}
+
+// layoutDecl is the equivalent of layoutExpr for declarations
+type layoutDecl interface {
+	writeCoq(cfg PrinterConfig, w io.Writer) error
+}
+
+// WriteDecl writes d to w using the layout in cfg
+func (cfg PrinterConfig) WriteDecl(w io.Writer, d Decl) error {
+	if d, ok := d.(layoutDecl); ok {
+		return d.writeCoq(cfg, w)
+	}
+	return d.WriteCoq(w)
+}
+
+// Decl prints d using the layout in cfg
+func (cfg PrinterConfig) Decl(d Decl) string {
+	var b strings.Builder
+	// writing to a strings.Builder cannot fail
+	_ = cfg.WriteDecl(&b, d)
+	return b.String()
+}
+
+// tabWidth is the width of a tab stop for expandTabs
+const tabWidth = 4
+
+// expandTabs replaces tabs in each line of s with spaces up to the next tab
+// stop
+func expandTabs(s string) string {
+	if !strings.ContainsRune(s, '\t') {
+		return s
+	}
+	var b strings.Builder
+	col := 0
+	for _, r := range s {
+		switch r {
+		case '\t':
+			n := tabWidth - col%tabWidth
+			b.WriteString(strings.Repeat(" ", n))
+			col += n
+		case '\n':
+			b.WriteRune(r)
+			col = 0
+		default:
+			b.WriteRune(r)
+			col++
+		}
+	}
+	return b.String()
+}
+
+// quote makes a Coq string literal for s, such as the name of a GooseLang
+// variable
 func quote(s string) string {
-	return `"` + s + `"`
+	// Coq escapes a quote in a string by doubling it
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// reservedWords are the Gallina keywords, along with words that are reserved by
+// the GooseLang notations, which cannot be used as identifiers
+var reservedWords = map[string]bool{
+	"as": true, "at": true, "cofix": true, "else": true, "end": true,
+	"exists": true, "exists2": true, "fix": true, "for": true, "forall": true,
+	"fun": true, "if": true, "IF": true, "in": true, "let": true, "match": true,
+	"return": true, "then": true, "where": true, "with": true,
+	"Prop": true, "SProp": true, "Set": true, "Type": true,
+	"Axiom": true, "CoFixpoint": true, "Definition": true, "Fixpoint": true,
+	"Hypothesis": true, "Parameter": true, "Theorem": true, "Variable": true,
+	"rec": true,
+}
+
+// GallinaName gives the Gallina identifier for the Go name of a definition,
+// such as a function or type, adding a prime to names that are reserved in
+// Coq. Go names cannot contain a prime, so a renamed definition cannot collide
+// with another one.
+//
+// Variables and fields are strings in GooseLang, so they keep their Go names.
+func GallinaName(name string) string {
+	if reservedWords[name] {
+		return name + "'"
+	}
+	return name
 }
 
 func binder(s string) string {
@@ -111,6 +262,10 @@ func (d FieldDecl) Coq(needs_paren bool) string {
 }
 
 // StructDecl is a Coq record for a Go struct
+//
+// The Go zero value of the struct is derived from the field types (by
+// zero_val), so slice, map, and pointer fields are nil and nested structs are
+// recursively zero.
 type StructDecl struct {
 	Name    string
 	Fields  []FieldDecl
@@ -119,14 +274,23 @@ type StructDecl struct {
 
 // CoqDecl implements the Decl interface
 //
-// A struct declaration simply consists of the struct descriptor
-// (wrapped in a module in case we eventually want to add more things related
-// to the struct).
+// A struct declaration simply consists of the struct descriptor, a flat
+// top-level definition named after the struct; fields are accessed with the
+// generic struct.get and struct.loadF rather than per-struct accessors.
 func (d StructDecl) CoqDecl() string {
-	var pp buffer
+	return PrinterConfig{}.Decl(d)
+}
+
+// WriteCoq implements the Decl interface
+func (d StructDecl) WriteCoq(w io.Writer) error {
+	return d.writeCoq(PrinterConfig{}, w)
+}
+
+func (d StructDecl) writeCoq(cfg PrinterConfig, w io.Writer) error {
+	pp := buffer{cfg: cfg}
 	pp.AddComment(d.Comment)
 	pp.Add("Definition %s := struct.decl [", d.Name)
-	pp.Indent(2)
+	pp.Indent(cfg.indent())
 	for i, fd := range d.Fields {
 		sep := ";"
 		if i == len(d.Fields)-1 {
@@ -134,9 +298,9 @@ func (d StructDecl) CoqDecl() string {
 		}
 		pp.Add("%s :: %s%s", quote(fd.Name), fd.Type.Coq(false), sep)
 	}
-	pp.Indent(-2)
+	pp.Indent(-cfg.indent())
 	pp.AddLine("].")
-	return pp.Build()
+	return pp.WriteLines(w)
 }
 
 type InterfaceDecl struct {
@@ -146,10 +310,19 @@ type InterfaceDecl struct {
 }
 
 func (d InterfaceDecl) CoqDecl() string {
-	var pp buffer
+	return PrinterConfig{}.Decl(d)
+}
+
+// WriteCoq implements the Decl interface
+func (d InterfaceDecl) WriteCoq(w io.Writer) error {
+	return d.writeCoq(PrinterConfig{}, w)
+}
+
+func (d InterfaceDecl) writeCoq(cfg PrinterConfig, w io.Writer) error {
+	pp := buffer{cfg: cfg}
 	pp.AddComment(d.Comment)
 	pp.Add("Definition %s := struct.decl [", d.Name)
-	pp.Indent(2)
+	pp.Indent(cfg.indent())
 	for i, fd := range d.Methods {
 		sep := ";"
 		if i == len(d.Methods)-1 {
@@ -157,9 +330,9 @@ func (d InterfaceDecl) CoqDecl() string {
 		}
 		pp.Add("%s :: %s%s", quote(fd.Name), fd.Type.Coq(false), sep)
 	}
-	pp.Indent(-2)
+	pp.Indent(-cfg.indent())
 	pp.AddLine("].")
-	return pp.Build()
+	return pp.WriteLines(w)
 }
 
 func (d InterfaceDecl) Coq(needs_paren bool) string {
@@ -179,96 +352,24 @@ func (d InterfaceDecl) Coq(needs_paren bool) string {
 	return pp.Build()
 }
 
-type StructToInterface struct {
-	Struct    string
-	Interface string
-	Methods   []string
-}
-
-func (d StructToInterface) Coq(needs_paren bool) string {
-	var pp buffer
-	if len(d.Struct) > 0 && len(d.Interface) > 0 {
-		pp.Add("Definition %s__to__%s: val :=", d.Struct, d.Interface)
-		pp.Indent(2)
-		pp.Add("rec: \"%s_to_%s\" \"t\" :=", d.Struct, d.Interface)
-		pp.Indent(2)
-		if len(d.Methods) == 1 {
-			pp.Add("struct.mk %s [\"%s\" ::= %s__%s \"t\"].", d.Interface, d.Methods[0], d.Struct, d.Methods[0])
-		} else {
-			pp.Add("struct.mk %s [", d.Interface)
-			pp.Indent(2)
-			for i, method := range d.Methods {
-				if i == len(d.Methods)-1 {
-					pp.Add("\"%s\" ::= %s__%s \"t\"", method, d.Struct, method)
-				} else {
-					pp.Add("\"%s\" ::= %s__%s \"t\";", method, d.Struct, method)
-				}
-			}
-			pp.Indent(-2)
-			pp.Add("].")
-		}
-	}
-	return pp.Build()
-}
-
-func (d StructToInterface) MethodList() []string {
-	return d.Methods
-}
-
-func (d StructToInterface) Name() string {
-	var pp buffer
-	pp.Add("%s__to__%s", d.Struct, d.Interface)
-	return pp.Build()
-}
-
-func (d StructToInterface) CoqDecl() string {
-	var pp buffer
-	if len(d.Struct) > 0 && len(d.Interface) > 0 {
-		pp.Add("Definition %s__to__%s: val :=", d.Struct, d.Interface)
-		pp.Indent(2)
-		pp.Add("rec: \"%s_to_%s\" \"t\" :=", d.Struct, d.Interface)
-		pp.Indent(2)
-		if len(d.Methods) == 1 {
-			pp.Add("struct.mk %s [\"%s\" ::= %s__%s \"t\"].", d.Interface, d.Methods[0], d.Struct, d.Methods[0])
-		} else {
-			pp.Add("struct.mk %s [", d.Interface)
-			pp.Indent(2)
-			for i, method := range d.Methods {
-				if i == len(d.Methods)-1 {
-					pp.Add("\"%s\" ::= %s__%s \"t\"", method, d.Struct, method)
-				} else {
-					pp.Add("\"%s\" ::= %s__%s \"t\";", method, d.Struct, method)
-				}
-			}
-			pp.Indent(-2)
-			pp.Add("].")
-		}
-	}
-	return pp.Build()
-}
-
-type StructToInterfaceDecl struct {
-	Fun       string
-	Struct    string
-	Interface string
-	Arg       string
-}
-
-func (d StructToInterfaceDecl) Coq(needs_paren bool) string {
-	var pp buffer
-	pp.Add("(%s (%s__to__%s %s))", d.Fun, d.Struct, d.Interface, d.Arg)
-	return pp.Build()
-}
-
+// TypeDecl defines a named type other than a struct (for example, type Bytes
+// []byte) in terms of the type it is declared with.
 type TypeDecl struct {
-	Name string
-	Body Type
+	Name    string
+	Body    Type
+	Comment string
 }
 
 func (d TypeDecl) CoqDecl() string {
+	return PrinterConfig{}.Decl(d)
+}
+
+// WriteCoq implements the Decl interface
+func (d TypeDecl) WriteCoq(w io.Writer) error {
 	var pp buffer
+	pp.AddComment(d.Comment)
 	pp.Add("Definition %s: ty := %s.", d.Name, d.Body.Coq(false))
-	return pp.Build()
+	return pp.WriteLines(w)
 }
 
 // Type represents some Coq type.
@@ -308,6 +409,19 @@ func (t MapType) Coq(needs_paren bool) string {
 	return NewCallExpr(GallinaIdent("mapT"), t.Value).Coq(needs_paren)
 }
 
+// ChanType is the type of a channel with elements of type Elem.
+//
+// Channels keep Go's buffering: make(chan T, n) has room for n elements before
+// a send blocks, and an unbuffered channel (n = 0) makes each send wait for a
+// receiver.
+type ChanType struct {
+	Elem Type
+}
+
+func (t ChanType) Coq(needs_paren bool) string {
+	return NewCallExpr(GallinaIdent("chanT"), t.Elem).Coq(needs_paren)
+}
+
 type FuncType struct {
 	Params  []string
 	Results []string
@@ -347,18 +461,30 @@ func (t ArrayType) Coq(needs_paren bool) string {
 	return NewCallExpr(GallinaIdent("arrayT"), t.Elt).Coq(needs_paren)
 }
 
+// ArrowType is the type of a function value.
 type ArrowType struct {
-	ArgTypes   []Type // Must be non-empty; if no arguments, consists of unitT
+	ArgTypes   []Type // if empty, the function takes a unitT argument
 	ReturnType Type
 }
 
-func (t ArrowType) Coq(needs_paren bool) string {
+// arrowTypes renders the components of a function type, in order.
+//
+// A function with no arguments is modeled as taking unit, so its type is
+// unitT -> ReturnType.
+func arrowTypes(argTypes []Type, returnType Type) []string {
 	types := []string{}
-	for _, a := range t.ArgTypes {
+	for _, a := range argTypes {
 		types = append(types, a.Coq(true))
 	}
-	types = append(types, t.ReturnType.Coq(true))
-	return "(" + strings.Join(types, " -> ") + ")%ht"
+	if len(argTypes) == 0 {
+		types = append(types, TypeIdent("unitT").Coq(true))
+	}
+	types = append(types, returnType.Coq(true))
+	return types
+}
+
+func (t ArrowType) Coq(needs_paren bool) string {
+	return "(" + strings.Join(arrowTypes(t.ArgTypes, t.ReturnType), " -> ") + ")%ht"
 }
 
 type Expr interface {
@@ -383,7 +509,7 @@ type PackageIdent struct {
 }
 
 func (e PackageIdent) Coq(needs_paren bool) string {
-	return fmt.Sprintf("%s.%s", e.Package, e.Ident)
+	return fmt.Sprintf("%s.%s", e.Package, GallinaName(e.Ident))
 }
 
 var Skip Expr = GallinaIdent("Skip")
@@ -426,6 +552,9 @@ type CallExpr struct {
 
 // NewCallExpr is a convenience to construct a CallExpr statically, especially
 // for a fixed number of arguments.
+//
+// A call with no arguments passes #(), since functions (including all
+// translated Go functions) always take at least one argument.
 func NewCallExpr(name Expr, args ...Expr) CallExpr {
 	if len(args) == 0 {
 		args = []Expr{Tt}
@@ -433,15 +562,29 @@ func NewCallExpr(name Expr, args ...Expr) CallExpr {
 	return CallExpr{MethodName: name, Args: args}
 }
 
+// NewNullaryCall constructs a reference to a Gallina value that is not a
+// function, instantiated with typeArgs. Unlike NewCallExpr, it does not apply
+// the value to #().
+func NewNullaryCall(name Expr, typeArgs ...Expr) CallExpr {
+	return CallExpr{MethodName: name, TypeArgs: typeArgs}
+}
+
 func (s CallExpr) Coq(needs_paren bool) string {
-	comps := []string{s.MethodName.Coq(true)}
+	return s.coq(PrinterConfig{}, needs_paren)
+}
+
+func (s CallExpr) coq(cfg PrinterConfig, needs_paren bool) string {
+	if len(s.TypeArgs) == 0 && len(s.Args) == 0 {
+		return cfg.expr(s.MethodName, needs_paren)
+	}
+	comps := []string{cfg.expr(s.MethodName, true)}
 
 	for _, a := range s.TypeArgs {
-		comps = append(comps, a.Coq(true))
+		comps = append(comps, cfg.expr(a, true))
 	}
 
 	for _, a := range s.Args {
-		comps = append(comps, a.Coq(true))
+		comps = append(comps, cfg.expr(a, true))
 	}
 	return addParens(needs_paren, strings.Join(comps, " "))
 }
@@ -460,12 +603,16 @@ func StructDesc(name string) Expr {
 }
 
 func (e StructFieldAccessExpr) Coq(needs_paren bool) string {
+	return e.coq(PrinterConfig{}, needs_paren)
+}
+
+func (e StructFieldAccessExpr) coq(cfg PrinterConfig, needs_paren bool) string {
 	if e.ThroughPointer {
 		return NewCallExpr(GallinaIdent("struct.loadF"),
-			StructDesc(e.Struct), GallinaString(e.Field), e.X).Coq(needs_paren)
+			StructDesc(e.Struct), GallinaString(e.Field), e.X).coq(cfg, needs_paren)
 	}
 	return NewCallExpr(GallinaIdent("struct.get"), StructDesc(e.Struct),
-		GallinaString(e.Field), e.X).Coq(needs_paren)
+		GallinaString(e.Field), e.X).coq(cfg, needs_paren)
 }
 
 type ReturnExpr struct {
@@ -473,7 +620,11 @@ type ReturnExpr struct {
 }
 
 func (e ReturnExpr) Coq(needs_paren bool) string {
-	return e.Value.Coq(needs_paren)
+	return e.coq(PrinterConfig{}, needs_paren)
+}
+
+func (e ReturnExpr) coq(cfg PrinterConfig, needs_paren bool) string {
+	return cfg.expr(e.Value, needs_paren)
 }
 
 // Binding is a Coq binding (a part of a Bind expression)
@@ -526,26 +677,141 @@ func (sl *StructLiteral) AddField(field string, value Expr) {
 	sl.elts = append(sl.elts, fieldVal{field, value})
 }
 
+// HasField checks if field has been given a value in the literal.
+func (sl StructLiteral) HasField(field string) bool {
+	for _, f := range sl.elts {
+		if f.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
 func (sl StructLiteral) Coq(needs_paren bool) string {
-	var pp buffer
+	return sl.coq(PrinterConfig{}, needs_paren)
+}
+
+func (sl StructLiteral) coq(cfg PrinterConfig, needs_paren bool) string {
+	pp := buffer{cfg: cfg}
 	method := "struct.mk"
 	if sl.Allocation {
 		method = "struct.new"
 	}
 	pp.Add("%s %s [", method, StructDesc(sl.StructName).Coq(true))
-	pp.Indent(2)
+	pp.Indent(cfg.indent())
 	for i, f := range sl.elts {
 		terminator := ";"
 		if i == len(sl.elts)-1 {
 			terminator = ""
 		}
-		pp.Add("%s ::= %s%s", quote(f.Field), f.Value.Coq(false), terminator)
+		pp.Add("%s ::= %s%s", quote(f.Field), cfg.expr(f.Value, false), terminator)
 	}
-	pp.Indent(-2)
+	pp.Indent(-cfg.indent())
 	pp.Add("]")
 	return addParens(needs_paren, pp.Build())
 }
 
+// ArrayLiteral is a Go array literal, listing every element of the array.
+//
+// Arrays are values in Go, so like struct.mk this constructs a value rather
+// than an allocation; assigning an array copies it.
+type ArrayLiteral struct {
+	Elt  Type
+	Elts []Expr
+}
+
+func (al ArrayLiteral) Coq(needs_paren bool) string {
+	return al.coq(PrinterConfig{}, needs_paren)
+}
+
+func (al ArrayLiteral) coq(cfg PrinterConfig, needs_paren bool) string {
+	var elts []string
+	for _, e := range al.Elts {
+		elts = append(elts, cfg.expr(e, false))
+	}
+	expr := fmt.Sprintf("array.mk %s [%s]",
+		cfg.expr(al.Elt, true), strings.Join(elts, "; "))
+	return addParens(needs_paren, expr)
+}
+
+// SliceLiteral is a slice composite literal.
+//
+// This allocates a new slice of length Len, initially zero, and then sets each
+// of the listed elements in order.
+type SliceLiteral struct {
+	Elt  Type
+	Len  uint64
+	Elts []IndexedElt
+}
+
+// IndexedElt is an element of a slice literal at a particular index.
+type IndexedElt struct {
+	Index uint64
+	Value Expr
+}
+
+func (sl SliceLiteral) Coq(needs_paren bool) string {
+	return sl.coq(PrinterConfig{}, needs_paren)
+}
+
+func (sl SliceLiteral) coq(cfg PrinterConfig, needs_paren bool) string {
+	if sl.Len == 0 {
+		// an empty but non-nil slice
+		return NewCallExpr(GallinaIdent("NewSlice"), sl.Elt, IntLiteral{0}).
+			coq(cfg, needs_paren)
+	}
+	if sl.Len == 1 && len(sl.Elts) == 1 {
+		return NewCallExpr(GallinaIdent("SliceSingleton"), sl.Elts[0].Value).
+			coq(cfg, needs_paren)
+	}
+	// $ cannot appear in Go identifiers, so this name is always fresh
+	s := IdentExpr("$s")
+	bindings := []Binding{{Names: []string{string(s)},
+		Expr: NewCallExpr(GallinaIdent("NewSlice"), sl.Elt, IntLiteral{sl.Len})}}
+	for _, e := range sl.Elts {
+		bindings = append(bindings, NewAnon(
+			NewCallExpr(GallinaIdent("SliceSet"), sl.Elt, s, IntLiteral{e.Index}, e.Value)))
+	}
+	bindings = append(bindings, NewAnon(s))
+	return "(" + indent(1, BlockExpr{Bindings: bindings}.coq(cfg, false)) + ")"
+}
+
+// MapLiteral is a map composite literal.
+//
+// Maps are references, so this allocates a new map and inserts each entry in
+// order.
+type MapLiteral struct {
+	Key     Type
+	Value   Type
+	Entries []MapEntry
+}
+
+type MapEntry struct {
+	Key   Expr
+	Value Expr
+}
+
+func (ml MapLiteral) Coq(needs_paren bool) string {
+	return ml.coq(PrinterConfig{}, needs_paren)
+}
+
+func (ml MapLiteral) coq(cfg PrinterConfig, needs_paren bool) string {
+	newMap := NewCallExpr(GallinaIdent("NewMap"), ml.Key, ml.Value, Tt)
+	if len(ml.Entries) == 0 {
+		return cfg.expr(newMap, needs_paren)
+	}
+	// $ cannot appear in Go identifiers, so this name is always fresh
+	m := IdentExpr("$m")
+	bindings := []Binding{{Names: []string{string(m)}, Expr: newMap}}
+	for _, e := range ml.Entries {
+		bindings = append(bindings, NewAnon(
+			NewCallExpr(GallinaIdent("MapInsert"), m, e.Key, e.Value)))
+	}
+	bindings = append(bindings, NewAnon(m))
+	// always parenthesized, so the let is clearly delimited wherever it appears
+	return "(" + indent(1, BlockExpr{Bindings: bindings}.coq(cfg, false)) + ")"
+}
+
 type BoolLiteral bool
 
 var (
@@ -598,7 +864,7 @@ type StringLiteral struct {
 }
 
 func (l StringLiteral) Coq(needs_paren bool) string {
-	return fmt.Sprintf(`#(str"%s")`, l.Value)
+	return fmt.Sprintf(`#(str%s)`, quote(l.Value))
 }
 
 type nullLiteral struct{}
@@ -610,6 +876,29 @@ func (l nullLiteral) Coq(needs_paren bool) string {
 // Null represents a nil pointer in Go
 var Null = nullLiteral{}
 
+// NilError represents a nil error in Go (see NewErrorExpr)
+var NilError = GallinaIdent("NONE")
+
+// NewErrorExpr creates a new error with the message msg.
+//
+// An error is an option: a nil error is NONE, and any other error is SOME of
+// the location holding its message, so two errors are equal only if they were
+// created by the same call, as in Go.
+func NewErrorExpr(msg Expr) CallExpr {
+	return NewCallExpr(GallinaIdent("SOME"), RefExpr{X: msg, Ty: TypeIdent("stringT")})
+}
+
+// ErrorMessageExpr is the message of the error X, panicking if X is nil.
+type ErrorMessageExpr struct {
+	X Expr
+}
+
+func (e ErrorMessageExpr) Coq(needs_paren bool) string {
+	return addParens(needs_paren,
+		fmt.Sprintf(`match: %s with NONE => Panic "nil error" | SOME "$msg" => ![stringT] "$msg" end`,
+			e.X.Coq(false)))
+}
+
 // BinOp is an enum for a Coq binary operator
 type BinOp int
 
@@ -623,7 +912,7 @@ const (
 	OpGreaterThan
 	OpLessEq
 	OpGreaterEq
-	OpAppend
+	OpAppend // string concatenation, which GooseLang overloads onto +
 	OpMul
 	OpQuot
 	OpRem
@@ -642,7 +931,23 @@ type BinaryExpr struct {
 	Y  Expr
 }
 
+// binaryOperand prints an operand to a binary operator.
+//
+// Function application binds more tightly than any infix operator, so calls
+// need no parentheses; everything else is parenthesized unless it is atomic.
+func binaryOperand(cfg PrinterConfig, e Expr) string {
+	switch e.(type) {
+	case CallExpr, StructFieldAccessExpr:
+		return cfg.expr(e, false)
+	}
+	return cfg.expr(e, true)
+}
+
 func (be BinaryExpr) Coq(needs_paren bool) string {
+	return be.coq(PrinterConfig{}, needs_paren)
+}
+
+func (be BinaryExpr) coq(cfg PrinterConfig, needs_paren bool) string {
 	coqBinOp := map[BinOp]string{
 		OpPlus:        "+",
 		OpMinus:       "-",
@@ -666,7 +971,7 @@ func (be BinaryExpr) Coq(needs_paren bool) string {
 	}
 	if binop, ok := coqBinOp[be.Op]; ok {
 		expr := fmt.Sprintf("%s %s %s",
-			be.X.Coq(true), binop, be.Y.Coq(true))
+			binaryOperand(cfg, be.X), binop, binaryOperand(cfg, be.Y))
 		return addParens(needs_paren, expr)
 	}
 
@@ -678,15 +983,23 @@ type NotExpr struct {
 }
 
 func (e NotExpr) Coq(needs_paren bool) string {
-	return fmt.Sprintf("(~ %s)", e.X.Coq(true))
+	return e.coq(PrinterConfig{}, needs_paren)
+}
+
+func (e NotExpr) coq(cfg PrinterConfig, needs_paren bool) string {
+	return addParens(needs_paren, fmt.Sprintf("~ %s", cfg.expr(e.X, true)))
 }
 
 type TupleExpr []Expr
 
 func (te TupleExpr) Coq(needs_paren bool) string {
+	return te.coq(PrinterConfig{}, needs_paren)
+}
+
+func (te TupleExpr) coq(cfg PrinterConfig, needs_paren bool) string {
 	var comps []string
 	for _, t := range te {
-		comps = append(comps, t.Coq(false))
+		comps = append(comps, cfg.expr(t, false))
 	}
 	return fmt.Sprintf("(%s)",
 		indent(1, strings.Join(comps, ", ")))
@@ -707,47 +1020,61 @@ type BlockExpr struct {
 
 // AddTo adds a binding as a non-terminal line to a block
 func (b Binding) AddTo(pp *buffer) {
+	cfg := pp.cfg
 	if e, ok := b.Expr.(LoggingStmt); ok {
-		pp.Add("%s", e.Coq(true))
+		pp.Add("%s", cfg.expr(e, true))
 		return
 	}
 	// Printing for anonymous and multiple return values
 	if b.isAnonymous() {
-		pp.Add("%s;;", b.Expr.Coq(false))
+		pp.Add("%s;;", cfg.expr(b.Expr, false))
 	} else if len(b.Names) == 1 {
-		pp.Add("let: %s := %s in", binder(b.Names[0]), b.Expr.Coq(false))
+		code := cfg.expr(b.Expr, false)
+		if _, ok := b.Expr.(IfExpr); ok {
+			// keep the branches visibly inside the binding
+			code = indent(cfg.indent(), code)
+		}
+		pp.Add("let: %s := %s in", binder(b.Names[0]), code)
 	} else if len(b.Names) == 2 {
 		pp.Add("let: (%s, %s) := %s in",
 			binder(b.Names[0]),
 			binder(b.Names[1]),
-			b.Expr.Coq(false))
+			cfg.expr(b.Expr, false))
 	} else if len(b.Names) == 3 {
 		pp.Add("let: ((%s, %s), %s) := %s in",
 			binder(b.Names[0]),
 			binder(b.Names[1]),
 			binder(b.Names[2]),
-			b.Expr.Coq(false))
+			cfg.expr(b.Expr, false))
 	} else if len(b.Names) == 4 {
 		pp.Add("let: (((%s, %s), %s), %s) := %s in",
 			binder(b.Names[0]),
 			binder(b.Names[1]),
 			binder(b.Names[2]),
 			binder(b.Names[3]),
-			b.Expr.Coq(false))
+			cfg.expr(b.Expr, false))
 	} else {
 		panic("no support for destructuring more than 4 return values")
 	}
 }
 
 func (be BlockExpr) Coq(needs_paren bool) string {
-	var pp buffer
+	return be.coq(PrinterConfig{}, needs_paren)
+}
+
+func (be BlockExpr) coq(cfg PrinterConfig, needs_paren bool) string {
+	if len(be.Bindings) == 0 {
+		// an empty block does nothing
+		return cfg.expr(Skip, needs_paren)
+	}
+	pp := buffer{cfg: cfg}
 	for n, b := range be.Bindings {
 		if n == len(be.Bindings)-1 {
 			if _, ok := b.Expr.(LoggingStmt); ok {
-				pp.AddLine(b.Expr.Coq(false))
+				pp.AddLine(cfg.expr(b.Expr, false))
 				pp.AddLine(UnitLiteral{}.Coq(true))
 			} else {
-				pp.AddLine(b.Expr.Coq(false))
+				pp.AddLine(cfg.expr(b.Expr, false))
 			}
 			continue
 		}
@@ -762,7 +1089,11 @@ type DerefExpr struct {
 }
 
 func (e DerefExpr) Coq(needs_paren bool) string {
-	expr := fmt.Sprintf("![%s] %s", e.Ty.Coq(false), e.X.Coq(true))
+	return e.coq(PrinterConfig{}, needs_paren)
+}
+
+func (e DerefExpr) coq(cfg PrinterConfig, needs_paren bool) string {
+	expr := fmt.Sprintf("![%s] %s", cfg.expr(e.Ty, false), cfg.expr(e.X, true))
 	return addParens(needs_paren, expr)
 }
 
@@ -772,7 +1103,11 @@ type RefExpr struct {
 }
 
 func (e RefExpr) Coq(needs_paren bool) string {
-	return NewCallExpr(GallinaIdent("ref_to"), e.Ty, e.X).Coq(needs_paren)
+	return e.coq(PrinterConfig{}, needs_paren)
+}
+
+func (e RefExpr) coq(cfg PrinterConfig, needs_paren bool) string {
+	return NewCallExpr(GallinaIdent("ref_to"), e.Ty, e.X).coq(cfg, needs_paren)
 }
 
 type StoreStmt struct {
@@ -782,19 +1117,25 @@ type StoreStmt struct {
 }
 
 func (e StoreStmt) Coq(needs_paren bool) string {
-	expr := fmt.Sprintf("%s <-[%s] %s", e.Dst.Coq(true), e.Ty.Coq(false), e.X.Coq(true))
+	return e.coq(PrinterConfig{}, needs_paren)
+}
+
+func (e StoreStmt) coq(cfg PrinterConfig, needs_paren bool) string {
+	expr := fmt.Sprintf("%s <-[%s] %s", cfg.expr(e.Dst, true), cfg.expr(e.Ty, false), cfg.expr(e.X, true))
 	return addParens(needs_paren, expr)
 }
 
 type IfExpr struct {
 	Cond Expr
 	Then Expr
+	// Else is Skip if nil
 	Else Expr
 }
 
 func flowBranch(pp *buffer, prefix string, e Expr, suffix string) {
-	code := e.Coq(false) + suffix
-	if !strings.ContainsRune(code, '\n') {
+	cfg := pp.cfg
+	code := cfg.expr(e, false) + suffix
+	if cfg.fits(code) {
 		// compact, single-line form
 		indent := pp.Block(prefix+" ", "%s", code)
 		pp.Indent(-indent)
@@ -802,16 +1143,45 @@ func flowBranch(pp *buffer, prefix string, e Expr, suffix string) {
 	}
 	// full multiline, nicely indented form
 	pp.AddLine(prefix)
-	pp.Indent(2)
+	pp.Indent(cfg.indent())
 	pp.AddLine(code)
-	pp.Indent(-2)
+	pp.Indent(-cfg.indent())
+}
+
+// asIfExpr returns e as an IfExpr if e is one, possibly as the only binding in
+// a block
+func asIfExpr(e Expr) (IfExpr, bool) {
+	if be, ok := e.(BlockExpr); ok && len(be.Bindings) == 1 {
+		if inner, ok := be.Bindings[0].Unwrap(); ok {
+			e = inner
+		}
+	}
+	ife, ok := e.(IfExpr)
+	return ife, ok
 }
 
 func (ife IfExpr) Coq(needs_paren bool) string {
-	var pp buffer
-	// Since we are parenthesesizing all if, we don't need to parenthesize the things inside the if
-	pp.Add("(if: %s", ife.Cond.Coq(false))
+	return ife.coq(PrinterConfig{}, needs_paren)
+}
+
+func (ife IfExpr) coq(cfg PrinterConfig, needs_paren bool) string {
+	pp := buffer{cfg: cfg}
+	// Since we are parenthesesizing all if, we don't need to parenthesize the
+	// things inside the if, except for a let: in the condition, which would
+	// otherwise extend into the branches
+	_, condIsBlock := ife.Cond.(BlockExpr)
+	pp.Add("(if: %s", cfg.expr(ife.Cond, condIsBlock))
 	flowBranch(&pp, "then", ife.Then, "")
+	if ife.Else == nil {
+		flowBranch(&pp, "else", Skip, ")")
+		return pp.Build()
+	}
+	if elseIf, ok := asIfExpr(ife.Else); ok {
+		// chain else-if without indenting, so a sequence of guards (or an
+		// else-if chain) stays flat rather than nesting ever deeper
+		pp.Add("else %s)", cfg.expr(elseIf, false))
+		return pp.Build()
+	}
 	flowBranch(&pp, "else", ife.Else, ")")
 	return pp.Build()
 }
@@ -829,13 +1199,19 @@ type HashTableInsert struct {
 }
 
 func (e HashTableInsert) Coq(needs_paren bool) string {
-	return fmt.Sprintf("(fun _ => Some %s)", e.Value.Coq(true))
+	return e.coq(PrinterConfig{}, needs_paren)
+}
+
+func (e HashTableInsert) coq(cfg PrinterConfig, needs_paren bool) string {
+	return fmt.Sprintf("(fun _ => Some %s)", cfg.expr(e.Value, true))
 }
 
 var LoopContinue = GallinaIdent("Continue")
 var LoopBreak = GallinaIdent("Break")
 
 type ForLoopExpr struct {
+	// Init runs before the loop; it is omitted if it has no Expr (as for a
+	// while-style loop)
 	Init Binding
 	Cond Expr
 	Post Expr
@@ -844,11 +1220,17 @@ type ForLoopExpr struct {
 }
 
 func (e ForLoopExpr) Coq(needs_paren bool) string {
-	var pp buffer
-	e.Init.AddTo(&pp)
-	pp.Add("(for: (λ: <>, %s); (λ: <>, %s) := λ: <>,", e.Cond.Coq(false), e.Post.Coq(false))
-	pp.Indent(2)
-	pp.Add("%s)", e.Body.Coq(false))
+	return e.coq(PrinterConfig{}, needs_paren)
+}
+
+func (e ForLoopExpr) coq(cfg PrinterConfig, needs_paren bool) string {
+	pp := buffer{cfg: cfg}
+	if e.Init.Expr != nil {
+		e.Init.AddTo(&pp)
+	}
+	pp.Add("(for: (λ: <>, %s); (λ: <>, %s) := λ: <>,", cfg.expr(e.Cond, false), cfg.expr(e.Post, false))
+	pp.Indent(cfg.indent())
+	pp.Add("%s)", cfg.expr(e.Body, false))
 	return pp.Build()
 }
 
@@ -870,13 +1252,17 @@ type SliceLoopExpr struct {
 }
 
 func (e SliceLoopExpr) Coq(needs_paren bool) string {
-	var pp buffer
+	return e.coq(PrinterConfig{}, needs_paren)
+}
+
+func (e SliceLoopExpr) coq(cfg PrinterConfig, needs_paren bool) string {
+	pp := buffer{cfg: cfg}
 	pp.Add("ForSlice %v %s %s %s",
-		e.Ty.Coq(true),
+		cfg.expr(e.Ty, true),
 		binderToCoq(e.Key), binderToCoq(e.Val),
-		e.Slice.Coq(true))
-	pp.Indent(2)
-	pp.Add("%s", e.Body.Coq(true))
+		cfg.expr(e.Slice, true))
+	pp.Indent(cfg.indent())
+	pp.Add("%s", cfg.expr(e.Body, true))
 	return addParens(needs_paren, pp.Build())
 }
 
@@ -891,12 +1277,16 @@ type MapIterExpr struct {
 }
 
 func (e MapIterExpr) Coq(needs_paren bool) string {
-	var pp buffer
+	return e.coq(PrinterConfig{}, needs_paren)
+}
+
+func (e MapIterExpr) coq(cfg PrinterConfig, needs_paren bool) string {
+	pp := buffer{cfg: cfg}
 	pp.Add("MapIter %s (λ: %s %s,",
-		e.Map.Coq(true),
+		cfg.expr(e.Map, true),
 		binder(e.KeyIdent), binder(e.ValueIdent))
-	pp.Indent(2)
-	pp.Add("%s)", e.Body.Coq(false))
+	pp.Indent(cfg.indent())
+	pp.Add("%s)", cfg.expr(e.Body, false))
 	return addParens(needs_paren, pp.Build())
 }
 
@@ -908,8 +1298,73 @@ type SpawnExpr struct {
 }
 
 func (e SpawnExpr) Coq(needs_paren bool) string {
-	var pp buffer
-	pp.Block("Fork (", "%s)", e.Body.Coq(false))
+	return e.coq(PrinterConfig{}, needs_paren)
+}
+
+func (e SpawnExpr) coq(cfg PrinterConfig, needs_paren bool) string {
+	pp := buffer{cfg: cfg}
+	pp.Block("Fork (", "%s)", cfg.expr(e.Body, false))
+	return addParens(needs_paren, pp.Build())
+}
+
+// SelectExpr is a select statement over channel operations.
+//
+// The select runs the body of one ready case, waiting until some case is ready
+// if there is no Default.
+type SelectExpr struct {
+	Cases []SelectCase
+	// Default is the body of the default case, or nil if there is none
+	Default *FuncLit
+}
+
+// SelectCase is a send or receive case of a select.
+type SelectCase struct {
+	Chan Expr
+	// Value is the value to send, or nil for a receive
+	Value Expr
+	// Body runs when the case is chosen. For a receive, it takes the received
+	// value and whether it was sent (as in v, ok := <-ch).
+	Body FuncLit
+}
+
+func (c SelectCase) Coq(needs_paren bool) string {
+	return c.coq(PrinterConfig{}, needs_paren)
+}
+
+func (c SelectCase) coq(cfg PrinterConfig, needs_paren bool) string {
+	if c.Value != nil {
+		return NewCallExpr(GallinaIdent("chan.select_send"),
+			c.Chan, c.Value, c.Body).coq(cfg, needs_paren)
+	}
+	return NewCallExpr(GallinaIdent("chan.select_receive"),
+		c.Chan, c.Body).coq(cfg, needs_paren)
+}
+
+func (e SelectExpr) Coq(needs_paren bool) string {
+	return e.coq(PrinterConfig{}, needs_paren)
+}
+
+func (e SelectExpr) coq(cfg PrinterConfig, needs_paren bool) string {
+	pp := buffer{cfg: cfg}
+	if e.Default == nil {
+		pp.Add("chan.select_blocking [")
+	} else {
+		pp.Add("chan.select_nonblocking [")
+	}
+	pp.Indent(cfg.indent())
+	for i, c := range e.Cases {
+		terminator := ";"
+		if i == len(e.Cases)-1 {
+			terminator = ""
+		}
+		pp.Add("%s%s", cfg.expr(c, false), terminator)
+	}
+	pp.Indent(-cfg.indent())
+	if e.Default == nil {
+		pp.Add("]")
+	} else {
+		pp.Add("] %s", cfg.expr(e.Default, true))
+	}
 	return addParens(needs_paren, pp.Build())
 }
 
@@ -922,7 +1377,11 @@ type FuncLit struct {
 }
 
 func (e FuncLit) Coq(needs_paren bool) string {
-	var pp buffer
+	return e.coq(PrinterConfig{}, needs_paren)
+}
+
+func (e FuncLit) coq(cfg PrinterConfig, needs_paren bool) string {
+	pp := buffer{cfg: cfg}
 
 	var args []string
 	for _, a := range e.Args {
@@ -933,10 +1392,21 @@ func (e FuncLit) Coq(needs_paren bool) string {
 	}
 	sig := strings.Join(args, " ")
 
+	body := cfg.expr(e.Body, false)
+	if compact := fmt.Sprintf("(λ: %s, %s)", sig, body); cfg.fits(compact) {
+		// compact, single-line form
+		return compact
+	}
+
 	pp.Add("(λ: %s,", sig)
-	pp.Indent(2)
-	defer pp.Indent(-2)
-	pp.AddLine(e.Body.Coq(false))
+	pp.Indent(cfg.indent())
+	defer pp.Indent(-cfg.indent())
+	if !strings.Contains(body, "\n") {
+		// a body wrapped only to fit MaxWidth is closed on its line
+		pp.AddLine(body + ")")
+		return pp.Build()
+	}
+	pp.AddLine(body)
 	pp.Add(")")
 
 	return pp.Build()
@@ -967,15 +1437,11 @@ func (d FuncDecl) Signature() string {
 
 func (d FuncDecl) Type() string {
 	// FIXME: doesn't deal with type parameters
-	types := []string{}
+	var argTypes []Type
 	for _, a := range d.Args {
-		types = append(types, a.Type.Coq(true))
+		argTypes = append(argTypes, a.Type)
 	}
-	if len(d.Args) == 0 {
-		types = append(types, TypeIdent("unitT").Coq(true))
-	}
-	types = append(types, d.ReturnType.Coq(true))
-	return strings.Join(types, " -> ")
+	return strings.Join(arrowTypes(argTypes, d.ReturnType), " -> ")
 }
 
 // CoqDecl implements the Decl interface
@@ -983,7 +1449,16 @@ func (d FuncDecl) Type() string {
 // For FuncDecl this emits the Coq vernacular Definition that defines the whole
 // function.
 func (d FuncDecl) CoqDecl() string {
-	var pp buffer
+	return PrinterConfig{}.Decl(d)
+}
+
+// WriteCoq implements the Decl interface
+func (d FuncDecl) WriteCoq(w io.Writer) error {
+	return d.writeCoq(PrinterConfig{}, w)
+}
+
+func (d FuncDecl) writeCoq(cfg PrinterConfig, w io.Writer) error {
+	pp := buffer{cfg: cfg}
 	pp.AddComment(d.Comment)
 
 	typeParams := make([]string, 0)
@@ -993,19 +1468,19 @@ func (d FuncDecl) CoqDecl() string {
 
 	pp.Add("Definition %s%s: val :=", d.Name, strings.Join(typeParams, ""))
 	func() {
-		pp.Indent(2)
-		defer pp.Indent(-2)
+		pp.Indent(cfg.indent())
+		defer pp.Indent(-cfg.indent())
 		pp.Add("rec: \"%s\" %s :=", d.Name, d.Signature())
-		pp.Indent(2)
-		defer pp.Indent(-2)
-		pp.AddLine(d.Body.Coq(false) + ".")
+		pp.Indent(cfg.indent())
+		defer pp.Indent(-cfg.indent())
+		pp.AddLine(cfg.expr(d.Body, false) + ".")
 	}()
 	if d.AddTypes {
 		pp.Add("Theorem %s_t: ⊢ %s : (%s).", d.Name, d.Name, d.Type())
 		pp.AddLine("Proof. typecheck. Qed.")
 		pp.Add("Hint Resolve %s_t : types.", d.Name)
 	}
-	return pp.Build()
+	return pp.WriteLines(w)
 }
 
 // CommentDecl is a top-level comment
@@ -1023,9 +1498,14 @@ func NewComment(s string) CommentDecl {
 //
 // For CommentDecl this emits a Coq top-level comment.
 func (d CommentDecl) CoqDecl() string {
+	return PrinterConfig{}.Decl(d)
+}
+
+// WriteCoq implements the Decl interface
+func (d CommentDecl) WriteCoq(w io.Writer) error {
 	var pp buffer
 	pp.AddComment(string(d))
-	return pp.Build()
+	return pp.WriteLines(w)
 }
 
 type ConstDecl struct {
@@ -1037,22 +1517,91 @@ type ConstDecl struct {
 }
 
 func (d ConstDecl) CoqDecl() string {
-	var pp buffer
+	return PrinterConfig{}.Decl(d)
+}
+
+// WriteCoq implements the Decl interface
+func (d ConstDecl) WriteCoq(w io.Writer) error {
+	return d.writeCoq(PrinterConfig{}, w)
+}
+
+func (d ConstDecl) writeCoq(cfg PrinterConfig, w io.Writer) error {
+	pp := buffer{cfg: cfg}
 	pp.AddComment(d.Comment)
 	indent := pp.Block("Definition ", "%s : expr := %s.",
-		d.Name, d.Val.Coq(false))
+		d.Name, cfg.expr(d.Val, false))
 	pp.Indent(-indent)
 	if d.AddTypes {
 		pp.Add("Theorem %s_t Γ : Γ ⊢ %s : %s.",
 			d.Name, d.Name, d.Type.Coq(true))
 		pp.AddLine("Proof. typecheck. Qed.")
 	}
-	return pp.Build()
+	return pp.WriteLines(w)
 }
 
-// Decl is a FuncDecl, StructDecl, CommentDecl, or ConstDecl
+// GlobalVarDecl declares a package variable that the package modifies.
+//
+// The variable is a location in the package's table of globals, which is
+// allocated and initialized by a function that the package's initialization
+// (see NewGlobalsInit) calls.
+type GlobalVarDecl struct {
+	Name    string
+	Type    Type
+	Init    Expr
+	Comment string
+}
+
+// InitName is the name of the function that allocates and initializes the
+// variable
+func (d GlobalVarDecl) InitName() string {
+	return d.Name + "__init"
+}
+
+func (d GlobalVarDecl) CoqDecl() string {
+	return PrinterConfig{}.Decl(d)
+}
+
+// WriteCoq implements the Decl interface
+func (d GlobalVarDecl) WriteCoq(w io.Writer) error {
+	return d.writeCoq(PrinterConfig{}, w)
+}
+
+func (d GlobalVarDecl) writeCoq(cfg PrinterConfig, w io.Writer) error {
+	return FuncDecl{
+		Name:    d.InitName(),
+		Comment: d.Comment,
+		Body: NewCallExpr(GallinaIdent("globals.put"), StringLiteral{d.Name},
+			RefExpr{X: d.Init, Ty: d.Type}),
+	}.writeCoq(cfg, w)
+}
+
+// GlobalsInitName is the name of the function that initializes a package's
+// modified package variables
+const GlobalsInitName = "globals__init"
+
+// NewGlobalsInit declares the function that initializes the package variables
+// vars, in order
+func NewGlobalsInit(vars []GlobalVarDecl) FuncDecl {
+	var body []Binding
+	for _, v := range vars {
+		body = append(body, NewAnon(NewCallExpr(GallinaIdent(v.InitName()))))
+	}
+	body = append(body, NewAnon(Tt))
+	return FuncDecl{Name: GlobalsInitName, Body: BlockExpr{Bindings: body}}
+}
+
+// GlobalLoc is the location of a package variable declared by a GlobalVarDecl
+type GlobalLoc string
+
+func (e GlobalLoc) Coq(needs_paren bool) string {
+	return NewCallExpr(GallinaIdent("globals.get"), StringLiteral{string(e)}).Coq(needs_paren)
+}
+
+// Decl is a FuncDecl, StructDecl, CommentDecl, ConstDecl, or GlobalVarDecl
 type Decl interface {
 	CoqDecl() string
+	// WriteCoq writes the same output as CoqDecl to w
+	WriteCoq(w io.Writer) error
 }
 
 type TupleType []Type
@@ -1084,13 +1633,13 @@ func StructMethod(structName string, methodName string) string {
 	return fmt.Sprintf("%s__%s", structName, methodName)
 }
 
-func InterfaceMethod(interfaceName string, methodName string) string {
-	return fmt.Sprintf("(struct.get %s \"%s\")", interfaceName, methodName)
-}
+// DefaultImportPrefix is the logical path of the GooseLang library, where the
+// prelude and trusted imports are required from
+const DefaultImportPrefix = "Perennial.goose_lang"
 
-const importHeader string = `
-From Perennial.goose_lang Require Import prelude.
-`
+// DefaultPackagePrefix is the logical path where other translated packages are
+// required from
+const DefaultPackagePrefix = "Goose"
 
 // These will not end up in `File.Decls`, they are put into `File.Imports` by `translatePackage`.
 type ImportDecl struct {
@@ -1117,25 +1666,50 @@ func ImportToPath(pkgPath, pkgName string) string {
 }
 
 func (decl ImportDecl) CoqDecl() string {
+	return PrinterConfig{}.Decl(decl)
+}
+
+// WriteCoq implements the Decl interface
+func (decl ImportDecl) WriteCoq(w io.Writer) error {
+	_, err := io.WriteString(w, decl.coqImport(DefaultImportPrefix, DefaultPackagePrefix, false))
+	return err
+}
+
+// requireKind is the kind of Require for imports that are re-exported if
+// export is true
+func requireKind(export bool) string {
+	if export {
+		return "Export"
+	}
+	return "Import"
+}
+
+func (decl ImportDecl) coqImport(prefix string, pkgPrefix string, export bool) string {
 	coqPath := pathToCoqPath(decl.Path)
 	coqImportPath := strings.ReplaceAll(path.Dir(coqPath), "/", ".")
 	name := path.Base(decl.Path)
 	if decl.Trusted {
-		return fmt.Sprintf("From Perennial.goose_lang.trusted Require Import %s.%s.", coqImportPath, name)
-	} else {
-		return fmt.Sprintf("From Goose Require %s.%s.", coqImportPath, name)
+		return fmt.Sprintf("From %s.trusted Require %s %s.%s.",
+			prefix, requireKind(export), coqImportPath, name)
 	}
+	if export {
+		return fmt.Sprintf("From %s Require Export %s.%s.", pkgPrefix, coqImportPath, name)
+	}
+	return fmt.Sprintf("From %s Require %s.%s.", pkgPrefix, coqImportPath, name)
 }
 
 // ImportDecls groups imports into one declaration so they can be printed
 // without intervening blank spaces.
 type ImportDecls []ImportDecl
 
-func (decls ImportDecls) PrintImports() string {
+// PrintImports prints one Require per line, requiring trusted imports from
+// the GooseLang library at prefix and other translated packages from
+// pkgPrefix. If export is true, the imports are re-exported.
+func (decls ImportDecls) PrintImports(prefix string, pkgPrefix string, export bool) string {
 	seen := make(map[string]bool)
 	var ss []string
 	for _, decl := range decls {
-		coqdecl := decl.CoqDecl()
+		coqdecl := decl.coqImport(prefix, pkgPrefix, export)
 		if !seen[coqdecl] {
 			ss = append(ss, coqdecl)
 			seen[coqdecl] = true
@@ -1147,12 +1721,43 @@ func (decls ImportDecls) PrintImports() string {
 
 // File represents a complete Coq file (a sequence of declarations).
 type File struct {
-	ImportHeader string
-	Footer       string
-	PkgPath      string
-	GoPackage    string
-	Imports      ImportDecls
-	Decls        []Decl
+	// ImportPrefix is the logical path of the GooseLang library
+	// (DefaultImportPrefix if empty)
+	ImportPrefix string
+	// PackagePrefix is the logical path of other translated packages
+	// (DefaultPackagePrefix if empty)
+	PackagePrefix string
+	// RequireExport re-exports the prelude and imports (with Require Export
+	// rather than Require Import)
+	RequireExport bool
+	ImportHeader  string
+	Footer        string
+	PkgPath       string
+	GoPackage     string
+	Imports       ImportDecls
+	Decls         []Decl
+	// Printer is the layout used for the declarations
+	Printer PrinterConfig
+}
+
+// Filename is a suggested name for the file on its own, named after the Go
+// package (ImportToPath gives a path that is unique among packages)
+func (f File) Filename() string {
+	return pathToCoqPath(f.GoPackage) + ".v"
+}
+
+func (f File) importPrefix() string {
+	if f.ImportPrefix == "" {
+		return DefaultImportPrefix
+	}
+	return f.ImportPrefix
+}
+
+func (f File) packagePrefix() string {
+	if f.PackagePrefix == "" {
+		return DefaultPackagePrefix
+	}
+	return f.PackagePrefix
 }
 
 func (f File) autogeneratedNotice() CommentDecl {
@@ -1169,31 +1774,73 @@ func stringInSlice(a string, list []string) bool {
 	return false
 }
 
+// trimNewlinesWriter passes output through to w, except for newlines at the
+// end, which are replaced with exactly one newline by Close
+type trimNewlinesWriter struct {
+	w io.Writer
+	// pending is the number of newlines written but not yet passed on
+	pending int
+}
+
+func (tw *trimNewlinesWriter) Write(p []byte) (int, error) {
+	text := bytes.TrimRight(p, "\n")
+	if len(text) > 0 {
+		if _, err := io.WriteString(tw.w, strings.Repeat("\n", tw.pending)); err != nil {
+			return 0, err
+		}
+		tw.pending = 0
+		if _, err := tw.w.Write(text); err != nil {
+			return 0, err
+		}
+	}
+	tw.pending += len(p) - len(text)
+	return len(p), nil
+}
+
+func (tw *trimNewlinesWriter) Close() error {
+	_, err := io.WriteString(tw.w, "\n")
+	return err
+}
+
 // Write outputs the Coq source for a File.
+//
+// The output always ends in exactly one newline, and depends only on f, so
+// re-translating unchanged code gives identical output. Declarations are
+// written as they are printed, so the whole file is never in memory at once.
 // noinspection GoUnhandledErrorResult
 func (f File) Write(w io.Writer) {
-	fmt.Fprintln(w, f.autogeneratedNotice().CoqDecl())
-	fmt.Fprintln(w, strings.Trim(importHeader, "\n"))
-	fmt.Fprintln(w, f.Imports.PrintImports())
+	b := &trimNewlinesWriter{w: w}
+	defer b.Close()
+	fmt.Fprintln(b, f.autogeneratedNotice().CoqDecl())
+	fmt.Fprintf(b, "From %s Require %s prelude.\n",
+		f.importPrefix(), requireKind(f.RequireExport))
+	fmt.Fprintln(b, f.Imports.PrintImports(f.importPrefix(), f.packagePrefix(), f.RequireExport))
 	if len(f.Imports) > 0 {
-		fmt.Fprintln(w)
+		fmt.Fprintln(b)
 	}
-	fmt.Fprintln(w, f.ImportHeader)
-	fmt.Fprintln(w)
-	decls := make(map[string]bool)
-	for i, d := range f.Decls {
-		decl := d.CoqDecl()
+	fmt.Fprintln(b, f.ImportHeader)
+	fmt.Fprintln(b)
+	// hashes of the declarations written so far
+	decls := make(map[[sha256.Size]byte]bool)
+	var decl bytes.Buffer
+	first := true
+	for _, d := range f.Decls {
+		decl.Reset()
+		f.Printer.WriteDecl(&decl, d)
 		// don't translate the same thing twice (which the interface translation
 		// can currently do)
 		_, isComment := d.(CommentDecl)
-		if isComment || !decls[decl] {
-			fmt.Fprintln(w, decl)
-			decls[decl] = true
-
-			if i != len(f.Decls)-1 {
-				fmt.Fprintln(w)
-			}
+		key := sha256.Sum256(decl.Bytes())
+		if !isComment && decls[key] {
+			continue
+		}
+		decls[key] = true
+		if !first {
+			fmt.Fprintln(b)
 		}
+		decl.WriteString("\n")
+		decl.WriteTo(b)
+		first = false
 	}
-	fmt.Fprint(w, f.Footer)
+	fmt.Fprint(b, f.Footer)
 }
@@ -1,6 +1,7 @@
 package coq
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -27,9 +28,320 @@ func TestPp(t *testing.T) {
 final line`, pp.Build())
 }
 
+func TestPpBlockMultilinePrefix(t *testing.T) {
+	var pp buffer
+	pp.Indent(2)
+	width := pp.Block("let: \"x\" :=\n  f (", "%s)", "a\nb")
+	pp.AddLine("c")
+	pp.Indent(-width)
+	pp.AddLine("d")
+	assert.Equal(t, 5, width)
+	assert.Equal(t, `  let: "x" :=
+    f (a
+       b)
+       c
+  d`, pp.Build())
+}
+
+func TestPpCommentTabs(t *testing.T) {
+	var pp buffer
+	pp.Indent(2)
+	pp.AddComment("example:\n\tx := 1\t// one\nab\tc")
+	assert.Equal(t, `  (* example:
+         x := 1  // one
+     ab  c *)`, pp.Build())
+}
+
+func TestQuote(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(`"x"`, quote("x"))
+	assert.Equal(`"$type"`, quote("$type"))
+	assert.Equal(`"say ""hi"""`, quote(`say "hi"`))
+	assert.Equal(`#(str"say ""hi""")`, StringLiteral{`say "hi"`}.Coq(false))
+}
+
+func TestGallinaName(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("end'", GallinaName("end"))
+	assert.Equal("Type'", GallinaName("Type"))
+	assert.Equal("End", GallinaName("End"))
+	assert.Equal("x", GallinaName("x"))
+	assert.Equal("pkg.fun'", PackageIdent{Package: "pkg", Ident: "fun"}.Coq(false))
+}
+
 func TestImportToPath(t *testing.T) {
 	// this is the current behavior, which doesn't accurately reflect the
 	// difference between a package's path and its name
 	assert.Equal(t, "github_com/mit_pdos/go_journal.v",
 		ImportToPath("github.com/mit-pdos/go-journal", "jrnl"))
 }
+
+func TestArrowType(t *testing.T) {
+	assert := assert.New(t)
+	u64 := TypeIdent("uint64T")
+	thunk := ArrowType{ReturnType: u64}
+	assert.Equal("(unitT -> uint64T)%ht", thunk.Coq(false))
+	nested := ArrowType{ArgTypes: []Type{thunk}, ReturnType: u64}
+	assert.Equal("((unitT -> uint64T)%ht -> uint64T)%ht", nested.Coq(false))
+	f := FuncDecl{Name: "f", ReturnType: u64}
+	assert.Equal("unitT -> uint64T", f.Type())
+	f.Args = []FieldDecl{{Name: "g", Type: nested}}
+	assert.Equal("((unitT -> uint64T)%ht -> uint64T)%ht -> uint64T", f.Type())
+}
+
+func TestFileImportPrefix(t *testing.T) {
+	assert := assert.New(t)
+	f := File{
+		PkgPath: "example.com/pkg",
+		Imports: ImportDecls{
+			{Path: "github.com/tchajed/goose/machine/filesys", Trusted: true},
+			{Path: "example.com/other", Trusted: false},
+		},
+	}
+	var b strings.Builder
+	f.Write(&b)
+	assert.Contains(b.String(), "From Perennial.goose_lang Require Import prelude.\n")
+	assert.Contains(b.String(),
+		"From Perennial.goose_lang.trusted Require Import github_com.tchajed.goose.machine.filesys.\n")
+
+	f.ImportPrefix = "MyLib.goose"
+	b.Reset()
+	f.Write(&b)
+	assert.Contains(b.String(), "From MyLib.goose Require Import prelude.\n")
+	assert.Contains(b.String(),
+		"From MyLib.goose.trusted Require Import github_com.tchajed.goose.machine.filesys.\n")
+	assert.NotContains(b.String(), "Perennial")
+	// imports of other translated packages have their own prefix
+	assert.Contains(b.String(), "From Goose Require example_com.other.\n")
+
+	f.PackagePrefix = "MyProj.code"
+	b.Reset()
+	f.Write(&b)
+	assert.Contains(b.String(), "From MyProj.code Require example_com.other.\n")
+	assert.Contains(b.String(), "From MyLib.goose Require Import prelude.\n")
+	assert.NotContains(b.String(), "Goose")
+}
+
+func TestFileRequireExport(t *testing.T) {
+	assert := assert.New(t)
+	f := File{
+		PkgPath: "example.com/pkg",
+		Imports: ImportDecls{
+			{Path: "github.com/tchajed/goose/machine/filesys", Trusted: true},
+			{Path: "example.com/other", Trusted: false},
+		},
+		RequireExport: true,
+	}
+	var b strings.Builder
+	f.Write(&b)
+	assert.Contains(b.String(), "From Perennial.goose_lang Require Export prelude.\n")
+	assert.Contains(b.String(),
+		"From Perennial.goose_lang.trusted Require Export github_com.tchajed.goose.machine.filesys.\n")
+	assert.Contains(b.String(), "From Goose Require Export example_com.other.\n")
+	assert.NotContains(b.String(), "Require Import")
+}
+
+func TestBinaryExprParens(t *testing.T) {
+	assert := assert.New(t)
+	x := IdentExpr("x")
+	lenX := NewCallExpr(GallinaIdent("slice.len"), x)
+	assert.Equal(`slice.len "x" = #0`,
+		BinaryExpr{X: lenX, Op: OpEquals, Y: IntLiteral{0}}.Coq(false))
+	sum := BinaryExpr{X: x, Op: OpPlus, Y: IntLiteral{1}}
+	assert.Equal(`("x" + #1) < slice.len "x"`,
+		BinaryExpr{X: sum, Op: OpLessThan, Y: lenX}.Coq(false))
+	assert.Equal(`(slice.len "x" = #0)`,
+		BinaryExpr{X: lenX, Op: OpEquals, Y: IntLiteral{0}}.Coq(true))
+	assert.Equal(`f (slice.len "x")`,
+		NewCallExpr(GallinaIdent("f"), lenX).Coq(false))
+}
+
+func TestNotExprParens(t *testing.T) {
+	assert := assert.New(t)
+	x := IdentExpr("x")
+	assert.Equal(`~ "x"`, NotExpr{x}.Coq(false))
+	assert.Equal(`(~ "x")`, NotExpr{x}.Coq(true))
+	assert.Equal(`~ (f "x")`, NotExpr{NewCallExpr(GallinaIdent("f"), x)}.Coq(false))
+}
+
+func TestFileWriteTrailingNewline(t *testing.T) {
+	assert := assert.New(t)
+	x := ConstDecl{Name: "x", Val: IntLiteral{1}}
+	for _, f := range []File{
+		{PkgPath: "example.com/pkg"},
+		{PkgPath: "example.com/pkg", Decls: []Decl{x}},
+		// the duplicate is omitted without leaving an extra blank line
+		{PkgPath: "example.com/pkg", Decls: []Decl{x, x}, Footer: "\nEnd code.\n\n"},
+	} {
+		var b1, b2 strings.Builder
+		f.Write(&b1)
+		f.Write(&b2)
+		out := b1.String()
+		assert.Equal(out, b2.String(), "output should be deterministic")
+		assert.True(strings.HasSuffix(out, "\n"), "output should end in a newline")
+		assert.False(strings.HasSuffix(out, "\n\n"), "output should end in one newline")
+	}
+	var b strings.Builder
+	File{PkgPath: "example.com/pkg", Decls: []Decl{x, x}}.Write(&b)
+	assert.True(strings.HasSuffix(b.String(), "Definition x : expr := #1.\n"))
+}
+
+func TestFilePrinterConfig(t *testing.T) {
+	assert := assert.New(t)
+	x := IdentExpr("x")
+	body := IfExpr{
+		Cond: BinaryExpr{X: x, Op: OpEquals, Y: IntLiteral{0}},
+		Then: FuncLit{Args: []FieldDecl{{Name: "y"}},
+			Body: BinaryExpr{X: IdentExpr("y"), Op: OpPlus, Y: IntLiteral{1}}},
+		Else: Null,
+	}
+	f := File{
+		PkgPath: "example.com/pkg",
+		Decls: []Decl{FuncDecl{Name: "f",
+			Args: []FieldDecl{{Name: "x"}},
+			Body: body}},
+	}
+	var b strings.Builder
+	f.Write(&b)
+	assert.Contains(b.String(), `Definition f: val :=
+  rec: "f" "x" :=
+    (if: "x" = #0
+    then (λ: "y", "y" + #1)
+    else #null).
+`)
+
+	f.Printer = PrinterConfig{IndentWidth: 4, MaxWidth: 16}
+	b.Reset()
+	f.Write(&b)
+	assert.Contains(b.String(), `Definition f: val :=
+    rec: "f" "x" :=
+        (if: "x" = #0
+        then
+            (λ: "y",
+                "y" + #1)
+        else #null).
+`)
+}
+
+func TestDeclWriteCoq(t *testing.T) {
+	assert := assert.New(t)
+	x := IdentExpr("x")
+	decls := []Decl{
+		NewComment("a comment\nover two lines"),
+		StructDecl{Name: "S", Fields: []FieldDecl{
+			{Name: "a", Type: TypeIdent("uint64T")},
+			{Name: "b", Type: TypeIdent("boolT")}}},
+		InterfaceDecl{Name: "I", Methods: []FieldDecl{
+			{Name: "M", Type: ArrowType{ReturnType: TypeIdent("uint64T")}}}},
+		TypeDecl{Name: "T", Body: SliceType{TypeIdent("byteT")}, Comment: "T is bytes"},
+		ConstDecl{Name: "c", Type: TypeIdent("uint64T"), Val: IntLiteral{3}},
+		FuncDecl{Name: "f", Args: []FieldDecl{{Name: "x"}},
+			Body:    IfExpr{Cond: x, Then: IntLiteral{1}, Else: IntLiteral{2}},
+			Comment: "f is a function"},
+		ImportDecl{Path: "example.com/other"},
+	}
+	for _, d := range decls {
+		var b strings.Builder
+		assert.NoError(d.WriteCoq(&b))
+		assert.Equal(d.CoqDecl(), b.String())
+	}
+}
+
+func TestFileWriteStreamed(t *testing.T) {
+	f := File{
+		PkgPath:      "example.com/pkg",
+		ImportHeader: "Section code.",
+		Footer:       "End code.\n",
+		Decls: []Decl{
+			NewComment("first"),
+			ConstDecl{Name: "x", Val: IntLiteral{1}},
+			ConstDecl{Name: "x", Val: IntLiteral{1}},
+			ConstDecl{Name: "y", Val: IntLiteral{2}},
+		},
+	}
+	var b strings.Builder
+	f.Write(&b)
+	assert.Equal(t, `(* autogenerated from example.com/pkg *)
+From Perennial.goose_lang Require Import prelude.
+
+Section code.
+
+(* first *)
+
+Definition x : expr := #1.
+
+Definition y : expr := #2.
+End code.
+`, b.String())
+}
+
+func TestIfExprCondParens(t *testing.T) {
+	assert := assert.New(t)
+	a, b := IdentExpr("a"), IdentExpr("b")
+	lt := BinaryExpr{X: a, Op: OpLessThan, Y: b}
+	assert.Equal(`(if: "a" < "b"
+then "a"
+else "b")`, IfExpr{Cond: lt, Then: a, Else: b}.Coq(false))
+	cond := BinaryExpr{X: lt, Op: OpLAnd, Y: NotExpr{lt}}
+	assert.Equal(`(if: ("a" < "b") && (~ ("a" < "b"))
+then "a"
+else "b")`, IfExpr{Cond: cond, Then: a, Else: b}.Coq(false))
+	let := BlockExpr{Bindings: []Binding{
+		{Names: []string{"x"}, Expr: a},
+		NewAnon(IdentExpr("x")),
+	}}
+	assert.Equal(`(if: (let: "x" := "a" in
+"x")
+then "a"
+else "b")`, IfExpr{Cond: let, Then: a, Else: b}.Coq(false))
+}
+
+func TestNullaryCall(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("lock.new #()", NewCallExpr(GallinaIdent("lock.new")).Coq(false))
+	assert.Equal("(lock.new #())", NewCallExpr(GallinaIdent("lock.new")).Coq(true))
+	assert.Equal("Skip", NewNullaryCall(GallinaIdent("Skip")).Coq(true))
+	assert.Equal("(zero_val uint64T)",
+		NewNullaryCall(GallinaIdent("zero_val"), TypeIdent("uint64T")).Coq(true))
+	assert.Equal(`f (zero_val uint64T)`,
+		NewCallExpr(GallinaIdent("f"),
+			NewNullaryCall(GallinaIdent("zero_val"), TypeIdent("uint64T"))).Coq(false))
+}
+
+func TestEmptyBranches(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("Skip", BlockExpr{}.Coq(true))
+	assert.Equal(`(if: "a"
+then "b"
+else Skip)`, IfExpr{Cond: IdentExpr("a"), Then: IdentExpr("b")}.Coq(false))
+	assert.Equal(`(if: "a"
+then Skip
+else Skip)`, IfExpr{Cond: IdentExpr("a"), Then: BlockExpr{}}.Coq(false))
+	assert.Contains(FuncDecl{Name: "f", Body: BlockExpr{}}.CoqDecl(),
+		"rec: \"f\" <> :=\n    Skip.")
+}
+
+func TestStringAppend(t *testing.T) {
+	assert := assert.New(t)
+	s := BinaryExpr{X: StringLiteral{"a"}, Op: OpAppend, Y: IdentExpr("s")}
+	assert.Equal(`#(str"a") + "s"`, s.Coq(false))
+	assert.Equal(`"s" + (#(str"a") + "s")`,
+		BinaryExpr{X: IdentExpr("s"), Op: OpAppend, Y: s}.Coq(false))
+}
+
+func TestStructDeclFlat(t *testing.T) {
+	assert := assert.New(t)
+	d := StructDecl{
+		Name: "Foo",
+		Fields: []FieldDecl{
+			{Name: "a", Type: TypeIdent("uint64T")},
+			{Name: "b", Type: TypeIdent("boolT")},
+		},
+	}
+	assert.Equal(`Definition Foo := struct.decl [
+  "a" :: uint64T;
+  "b" :: boolT
+].`, d.CoqDecl())
+	assert.Equal(`struct.get Foo "a" "x"`,
+		StructFieldAccessExpr{Struct: "Foo", Field: "a", X: IdentExpr("x")}.Coq(false))
+}
@@ -0,0 +1,14 @@
+// assert is translated with Assert configured as an assertion function
+package assert
+
+// Assert panics if b is false.
+func Assert(b bool) {
+	if !b {
+		panic("assertion failed")
+	}
+}
+
+func checkedIndex(s []uint64, i uint64) uint64 {
+	Assert(i < uint64(len(s)))
+	return s[i]
+}
@@ -0,0 +1,21 @@
+(* autogenerated from github.com/tchajed/goose/internal/examples/assert *)
+From Perennial.goose_lang Require Import prelude.
+
+Section code.
+Context `{ext_ty: ext_types}.
+Local Coercion Var' s: expr := Var s.
+
+(* assert is translated with Assert configured as an assertion function *)
+
+(* Assert panics if b is false. *)
+Definition Assert: val :=
+  rec: "Assert" "b" :=
+    control.impl.Assert "b";;
+    #().
+
+Definition checkedIndex: val :=
+  rec: "checkedIndex" "s" "i" :=
+    control.impl.Assert ("i" < slice.len "s");;
+    SliceGet uint64T "s" "i".
+
+End code.
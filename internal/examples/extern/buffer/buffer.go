@@ -0,0 +1,15 @@
+// Package buffer is used by the extern example, which gives models for its
+// methods
+package buffer
+
+type Buffer struct {
+	data []byte
+}
+
+func (b *Buffer) Append(x byte) {
+	b.data = append(b.data, x)
+}
+
+func (b Buffer) Len() uint64 {
+	return uint64(len(b.data))
+}
@@ -0,0 +1,32 @@
+// extern tests calls to functions from other packages with models given in
+// the configuration
+package extern
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/tchajed/goose/internal/examples/extern/buffer"
+)
+
+func hasVersionPrefix(s string) bool {
+	return strings.HasPrefix(s, "v")
+}
+
+func writeGreeting(b *strings.Builder, name string) {
+	b.WriteString("hello ")
+	b.WriteString(name)
+}
+
+func waitAll(wg *sync.WaitGroup) {
+	wg.Done()
+	wg.Wait()
+}
+
+// the receiver is adjusted to the method, as for any method call
+func appendAndLen(x byte) uint64 {
+	var b buffer.Buffer
+	b.Append(x)
+	p := &b
+	return p.Len()
+}
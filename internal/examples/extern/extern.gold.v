@@ -0,0 +1,36 @@
+(* autogenerated from github.com/tchajed/goose/internal/examples/extern *)
+From Perennial.goose_lang Require Import prelude.
+From Goose Require github_com.tchajed.goose.internal.examples.extern.buffer.
+
+Section code.
+Context `{ext_ty: ext_types}.
+Local Coercion Var' s: expr := Var s.
+
+(* extern tests calls to functions from other packages with models given in
+   the configuration *)
+
+Definition hasVersionPrefix: val :=
+  rec: "hasVersionPrefix" "s" :=
+    StringHasPrefix "s" #(str"v").
+
+Definition writeGreeting: val :=
+  rec: "writeGreeting" "b" "name" :=
+    StringBuilder.WriteString "b" #(str"hello ");;
+    StringBuilder.WriteString "b" "name";;
+    #().
+
+Definition waitAll: val :=
+  rec: "waitAll" "wg" :=
+    wg.Done "wg";;
+    wg.Wait "wg";;
+    #().
+
+(* the receiver is adjusted to the method, as for any method call *)
+Definition appendAndLen: val :=
+  rec: "appendAndLen" "x" :=
+    let: "b" := ref (zero_val (struct.t buffer.Buffer)) in
+    Buffer.Append "b" "x";;
+    let: "p" := "b" in
+    Buffer.Len (struct.load buffer.Buffer "p").
+
+End code.
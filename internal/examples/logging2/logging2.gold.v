@@ -11,7 +11,7 @@ Definition LOGSTART : expr := #1.
 
 Definition LOGMAXBLK : expr := #510.
 
-Definition LOGEND : expr := LOGMAXBLK + LOGSTART.
+Definition LOGEND : expr := #511.
 
 Definition Log := struct.decl [
   "logLock" :: ptrT;
@@ -80,13 +80,13 @@ Definition Log__memWrite: val :=
 Definition Log__memAppend: val :=
   rec: "Log__memAppend" "log" "l" :=
     lock.acquire (struct.get Log "memLock" "log");;
-    (if: ((![uint64T] (struct.get Log "memLen" "log")) + (slice.len "l")) ≥ (struct.get Log "logSz" "log")
+    (if: ((![uint64T] (struct.get Log "memLen" "log")) + slice.len "l") ≥ struct.get Log "logSz" "log"
     then
       lock.release (struct.get Log "memLock" "log");;
       (#false, #0)
     else
       let: "txn" := ![uint64T] (struct.get Log "memTxnNxt" "log") in
-      let: "n" := (![uint64T] (struct.get Log "memLen" "log")) + (slice.len "l") in
+      let: "n" := (![uint64T] (struct.get Log "memLen" "log")) + slice.len "l" in
       (struct.get Log "memLen" "log") <-[uint64T] "n";;
       (struct.get Log "memTxnNxt" "log") <-[uint64T] ((![uint64T] (struct.get Log "memTxnNxt" "log")) + #1);;
       lock.release (struct.get Log "memLock" "log");;
@@ -102,7 +102,6 @@ Definition Log__readLogTxnNxt: val :=
 
 Definition Log__diskAppendWait: val :=
   rec: "Log__diskAppendWait" "log" "txn" :=
-    Skip;;
     (for: (λ: <>, #true); (λ: <>, Skip) := λ: <>,
       let: "logtxn" := Log__readLogTxnNxt "log" in
       (if: "txn" < "logtxn"
@@ -146,7 +145,6 @@ Definition Log__diskAppend: val :=
 
 Definition Log__Logger: val :=
   rec: "Log__Logger" "log" :=
-    Skip;;
     (for: (λ: <>, #true); (λ: <>, Skip) := λ: <>,
       Log__diskAppend "log";;
       Continue);;
@@ -175,7 +173,7 @@ Definition Txn__Write: val :=
     (if: "ok"
     then MapInsert (struct.get Txn "blks" "txn") "addr" (![slice.T byteT] "blk")
     else #());;
-    (if: (~ "ok")
+    (if: ~ "ok"
     then
       (if: "addr" = LOGMAXBLK
       then "ret" <-[boolT] #false
@@ -23,3 +23,39 @@ func testClosureBasic() bool {
 	}
 	return false
 }
+
+func applyClosure(f func(uint64) uint64, x uint64) uint64 {
+	return f(x)
+}
+
+func testStoredClosure() bool {
+	double := func(x uint64) uint64 {
+		return 2 * x
+	}
+	return double(3) == 6
+}
+
+func testClosureArgument() bool {
+	var n = uint64(4)
+	return applyClosure(func(x uint64) uint64 {
+		return x + n
+	}, 3) == 7
+}
+
+func testClosureCapture() bool {
+	var count = uint64(0)
+	incr := func() {
+		count += 1
+	}
+	incr()
+	incr()
+	return count == 2
+}
+
+func testClosureMultipleReturn() bool {
+	divmod := func(x uint64, y uint64) (uint64, uint64) {
+		return x / y, x % y
+	}
+	q, r := divmod(7, 2)
+	return q == 3 && r == 1
+}
@@ -27,3 +27,15 @@ func testByteSliceToString() bool {
 	x[2] = 67
 	return byteSliceToString(x) == "ABC"
 }
+
+func testStringToByteSlice() bool {
+	s := "ABC"
+	p := stringToByteSlice(s)
+	var ok = len(p) == 3
+	ok = ok && p[0] == 65 && p[2] == 67
+	// the slice is a copy, so writing it does not change s
+	p[0] = 90
+	ok = ok && s == "ABC"
+	ok = ok && byteSliceToString(p) == "ZBC"
+	return ok
+}
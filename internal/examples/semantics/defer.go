@@ -0,0 +1,51 @@
+package semantics
+
+func deferIncrement() (n uint64) {
+	defer func() {
+		n++
+	}()
+	return 5
+}
+
+func deferOrder() (n uint64) {
+	defer func() {
+		n = n * 2
+	}()
+	defer func() {
+		n = n + 1
+	}()
+	return 3
+}
+
+func testDeferModifiesNamedResult() bool {
+	return deferIncrement() == 6
+}
+
+func testDeferOrder() bool {
+	// deferred calls run last-in, first-out: (3 + 1) * 2
+	return deferOrder() == 8
+}
+
+type deferAdder struct {
+	total *uint64
+}
+
+func (a deferAdder) add(x uint64) {
+	*a.total = *a.total + x
+}
+
+// the receiver and arguments of a deferred call are evaluated by the defer
+// statement
+func deferArgs(total *uint64) {
+	var x uint64 = 1
+	var a = deferAdder{total: total}
+	defer a.add(x)
+	x = 10
+	a = deferAdder{total: new(uint64)}
+}
+
+func testDeferEvaluatesArguments() bool {
+	total := new(uint64)
+	deferArgs(total)
+	return *total == 1
+}
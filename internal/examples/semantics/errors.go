@@ -0,0 +1,36 @@
+package semantics
+
+import "errors"
+
+// helpers
+func errorIfZero(x uint64) error {
+	if x == 0 {
+		return errors.New("zero")
+	}
+	return nil
+}
+
+// tests
+func testErrorReturn() bool {
+	var ok = true
+	ok = ok && errorIfZero(1) == nil
+	ok = ok && errorIfZero(0) != nil
+	ok = ok && errorIfZero(0).Error() == "zero"
+	return ok
+}
+
+func testErrorIdentity() bool {
+	err1 := errors.New("same")
+	err2 := errors.New("same")
+	var ok = true
+	ok = ok && err1 == err1
+	ok = ok && err1 != err2
+	ok = ok && err1.Error() == err2.Error()
+	ok = ok && errors.New("") != nil
+	return ok
+}
+
+func testErrorZeroValue() bool {
+	var err error
+	return err == nil
+}
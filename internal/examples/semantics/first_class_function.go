@@ -11,3 +11,22 @@ func ApplyF(a uint64, f func(uint64) uint64) uint64 {
 func testFirstClassFunction() bool {
 	return ApplyF(1, FirstClassFunction) == 11
 }
+
+func makeMultiplier(n uint64) func(uint64) uint64 {
+	return func(x uint64) uint64 {
+		return x * n
+	}
+}
+
+func testFunctionParameter() bool {
+	return ApplyF(3, makeMultiplier(4)) == 12
+}
+
+func testCallReturnedFunction() bool {
+	return makeMultiplier(2)(5) == 10
+}
+
+func testCallFunctionSlice() bool {
+	fs := []func(uint64) uint64{FirstClassFunction, makeMultiplier(3)}
+	return fs[0](1) == 11 && fs[1](2) == 6
+}
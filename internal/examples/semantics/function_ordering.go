@@ -31,8 +31,8 @@ type Pair struct {
 func failing_testFunctionOrdering() bool {
 	var arr = make([]uint64, 5)
 
-	e1 := Editor{s: arr[0:], next_val: 1}
-	e2 := Editor{s: arr[0:], next_val: 101}
+	var e1 = Editor{s: arr[0:], next_val: 1}
+	var e2 = Editor{s: arr[0:], next_val: 101}
 
 	if e1.AdvanceReturn(2)+e2.AdvanceReturn(102) != 102 {
 		return false
@@ -30,6 +30,30 @@ func (suite *GoTestSuite) TestClosureBasic() {
 	suite.Equal(true, testClosureBasic())
 }
 
+func (suite *GoTestSuite) TestStoredClosure() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testStoredClosure())
+}
+
+func (suite *GoTestSuite) TestClosureArgument() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testClosureArgument())
+}
+
+func (suite *GoTestSuite) TestClosureCapture() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testClosureCapture())
+}
+
+func (suite *GoTestSuite) TestClosureMultipleReturn() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testClosureMultipleReturn())
+}
+
 func (suite *GoTestSuite) TestCompareAll() {
 	d := disk.NewMemDisk(30)
 	disk.Init(d)
@@ -66,6 +90,12 @@ func (suite *GoTestSuite) TestByteSliceToString() {
 	suite.Equal(true, testByteSliceToString())
 }
 
+func (suite *GoTestSuite) TestStringToByteSlice() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testStringToByteSlice())
+}
+
 func (suite *GoTestSuite) TestCopySimple() {
 	d := disk.NewMemDisk(30)
 	disk.Init(d)
@@ -84,6 +114,24 @@ func (suite *GoTestSuite) TestCopyShorterSrc() {
 	suite.Equal(true, testCopyShorterSrc())
 }
 
+func (suite *GoTestSuite) TestDeferModifiesNamedResult() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testDeferModifiesNamedResult())
+}
+
+func (suite *GoTestSuite) TestDeferOrder() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testDeferOrder())
+}
+
+func (suite *GoTestSuite) TestDeferEvaluatesArguments() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testDeferEvaluatesArguments())
+}
+
 func (suite *GoTestSuite) TestEncDec32Simple() {
 	d := disk.NewMemDisk(30)
 	disk.Init(d)
@@ -108,12 +156,48 @@ func (suite *GoTestSuite) TestEncDec64() {
 	suite.Equal(true, testEncDec64())
 }
 
+func (suite *GoTestSuite) TestErrorReturn() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testErrorReturn())
+}
+
+func (suite *GoTestSuite) TestErrorIdentity() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testErrorIdentity())
+}
+
+func (suite *GoTestSuite) TestErrorZeroValue() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testErrorZeroValue())
+}
+
 func (suite *GoTestSuite) TestFirstClassFunction() {
 	d := disk.NewMemDisk(30)
 	disk.Init(d)
 	suite.Equal(true, testFirstClassFunction())
 }
 
+func (suite *GoTestSuite) TestFunctionParameter() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testFunctionParameter())
+}
+
+func (suite *GoTestSuite) TestCallReturnedFunction() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testCallReturnedFunction())
+}
+
+func (suite *GoTestSuite) TestCallFunctionSlice() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testCallFunctionSlice())
+}
+
 func (suite *GoTestSuite) TestFunctionOrdering() {
 	d := disk.NewMemDisk(30)
 	disk.Init(d)
@@ -147,7 +231,25 @@ func (suite *GoTestSuite) TestU32Len() {
 func (suite *GoTestSuite) TestU32NewtypeLen() {
 	d := disk.NewMemDisk(30)
 	disk.Init(d)
-	suite.Equal(true, failing_testU32NewtypeLen())
+	suite.Equal(true, testU32NewtypeLen())
+}
+
+func (suite *GoTestSuite) TestByteConversions() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testByteConversions())
+}
+
+func (suite *GoTestSuite) TestU64ToU8() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testU64ToU8())
+}
+
+func (suite *GoTestSuite) TestU32ToU8Newtype() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testU32ToU8Newtype())
 }
 
 func (suite *GoTestSuite) TestBasicInterface() {
@@ -180,6 +282,24 @@ func (suite *GoTestSuite) TestIfStmtInterface() {
 	suite.Equal(true, testIfStmtInterface())
 }
 
+func (suite *GoTestSuite) TestParamsInterface() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testParamsInterface())
+}
+
+func (suite *GoTestSuite) TestTwoImplementors() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testTwoImplementors())
+}
+
+func (suite *GoTestSuite) TestInterfaceNil() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testInterfaceNil())
+}
+
 func (suite *GoTestSuite) TestsUseLocks() {
 	d := disk.NewMemDisk(30)
 	disk.Init(d)
@@ -246,6 +366,18 @@ func (suite *GoTestSuite) TestNestedGoStyleLoopsNoComparison() {
 	suite.Equal(true, testNestedGoStyleLoopsNoComparison())
 }
 
+func (suite *GoTestSuite) TestImplicitContinueUntilBreak() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testImplicitContinueUntilBreak())
+}
+
+func (suite *GoTestSuite) TestInfiniteLoopBreak() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testInfiniteLoopBreak())
+}
+
 func (suite *GoTestSuite) TestIterateMap() {
 	d := disk.NewMemDisk(30)
 	disk.Init(d)
@@ -258,6 +390,30 @@ func (suite *GoTestSuite) TestMapSize() {
 	suite.Equal(true, testMapSize())
 }
 
+func (suite *GoTestSuite) TestMapAsSet() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testMapAsSet())
+}
+
+func (suite *GoTestSuite) TestMapDelete() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testMapDelete())
+}
+
+func (suite *GoTestSuite) TestMapCommaOk() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testMapCommaOk())
+}
+
+func (suite *GoTestSuite) TestMapLiteral() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testMapLiteral())
+}
+
 func (suite *GoTestSuite) TestAssignTwo() {
 	d := disk.NewMemDisk(30)
 	disk.Init(d)
@@ -276,6 +432,30 @@ func (suite *GoTestSuite) TestMultipleAssignToMap() {
 	suite.Equal(true, testMultipleAssignToMap())
 }
 
+func (suite *GoTestSuite) TestSwap() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testSwap())
+}
+
+func (suite *GoTestSuite) TestFibStep() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testFibStep())
+}
+
+func (suite *GoTestSuite) TestAssignIndexBeforeStores() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testAssignIndexBeforeStores())
+}
+
+func (suite *GoTestSuite) TestAssignSliceBeforeStores() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testAssignSliceBeforeStores())
+}
+
 func (suite *GoTestSuite) TestReturnTwo() {
 	d := disk.NewMemDisk(30)
 	disk.Init(d)
@@ -300,6 +480,12 @@ func (suite *GoTestSuite) TestReturnFour() {
 	suite.Equal(true, testReturnFour())
 }
 
+func (suite *GoTestSuite) TestMutexField() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testMutexField())
+}
+
 func (suite *GoTestSuite) TestCompareSliceToNil() {
 	d := disk.NewMemDisk(30)
 	disk.Init(d)
@@ -330,6 +516,12 @@ func (suite *GoTestSuite) TestComparePointerWrappedDefaultToNil() {
 	suite.Equal(true, testComparePointerWrappedDefaultToNil())
 }
 
+func (suite *GoTestSuite) TestCompareNilOnLeft() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testCompareNilOnLeft())
+}
+
 func (suite *GoTestSuite) TestReverseAssignOps64() {
 	d := disk.NewMemDisk(30)
 	disk.Init(d)
@@ -396,6 +588,54 @@ func (suite *GoTestSuite) TestPlusTimes() {
 	suite.Equal(true, testPlusTimes())
 }
 
+func (suite *GoTestSuite) TestIncDec() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testIncDec())
+}
+
+func (suite *GoTestSuite) TestCompoundAssign() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testCompoundAssign())
+}
+
+func (suite *GoTestSuite) TestBoolEquality() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testBoolEquality())
+}
+
+func (suite *GoTestSuite) TestConditionalDef() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testConditionalDef())
+}
+
+func (suite *GoTestSuite) TestEarlyReturnBranches() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testEarlyReturnBranches())
+}
+
+func (suite *GoTestSuite) TestConditionalDefModified() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testConditionalDefModified())
+}
+
+func (suite *GoTestSuite) TestCompoundAssignIndexOnce() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testCompoundAssignIndexOnce())
+}
+
+func (suite *GoTestSuite) TestIncIndexOnce() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testIncIndexOnce())
+}
+
 func (suite *GoTestSuite) TestOrCompareSimple() {
 	d := disk.NewMemDisk(30)
 	disk.Init(d)
@@ -468,6 +708,60 @@ func (suite *GoTestSuite) TestOverwriteArray() {
 	suite.Equal(true, testOverwriteArray())
 }
 
+func (suite *GoTestSuite) TestDoubleSliceInPlace() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testDoubleSliceInPlace())
+}
+
+func (suite *GoTestSuite) TestAppendCallResult() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testAppendCallResult())
+}
+
+func (suite *GoTestSuite) TestSliceLiteral() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testSliceLiteral())
+}
+
+func (suite *GoTestSuite) TestSparseSliceLiteral() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testSparseSliceLiteral())
+}
+
+func (suite *GoTestSuite) TestSliceElementPointer() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testSliceElementPointer())
+}
+
+func (suite *GoTestSuite) TestArraySliceSharesStorage() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testArraySliceSharesStorage())
+}
+
+func (suite *GoTestSuite) TestGoArgCapture() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, failing_testGoArgCapture())
+}
+
+func (suite *GoTestSuite) TestGoClosureCapture() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, failing_testGoClosureCapture())
+}
+
+func (suite *GoTestSuite) TestGoMethodArgCapture() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, failing_testGoMethodArgCapture())
+}
+
 func (suite *GoTestSuite) TestStringAppend() {
 	d := disk.NewMemDisk(30)
 	disk.Init(d)
@@ -480,10 +774,46 @@ func (suite *GoTestSuite) TestStringLength() {
 	suite.Equal(true, failing_testStringLength())
 }
 
+func (suite *GoTestSuite) TestStringConcatLoop() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testStringConcatLoop())
+}
+
+func (suite *GoTestSuite) TestStringEquality() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testStringEquality())
+}
+
+func (suite *GoTestSuite) TestStringConcat() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testStringConcat())
+}
+
+func (suite *GoTestSuite) TestStringIndex() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testStringIndex())
+}
+
 func (suite *GoTestSuite) TestFooBarMutation() {
 	d := disk.NewMemDisk(30)
 	disk.Init(d)
-	suite.Equal(true, failing_testFooBarMutation())
+	suite.Equal(true, testFooBarMutation())
+}
+
+func (suite *GoTestSuite) TestNewUint64() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testNewUint64())
+}
+
+func (suite *GoTestSuite) TestNewStruct() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testNewStruct())
 }
 
 func (suite *GoTestSuite) TestStructUpdates() {
@@ -540,6 +870,72 @@ func (suite *GoTestSuite) TestStructFieldFunc() {
 	suite.Equal(true, testStructFieldFunc())
 }
 
+func (suite *GoTestSuite) TestZeroStructFields() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testZeroStructFields())
+}
+
+func (suite *GoTestSuite) TestPartialStructLiteral() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testPartialStructLiteral())
+}
+
+func (suite *GoTestSuite) TestFieldOfCallResult() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testFieldOfCallResult())
+}
+
+func (suite *GoTestSuite) TestFieldPointer() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testFieldPointer())
+}
+
+func (suite *GoTestSuite) TestNestedFieldChains() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testNestedFieldChains())
+}
+
+func (suite *GoTestSuite) TestReceiverRules() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testReceiverRules())
+}
+
+func (suite *GoTestSuite) TestPromotedMethods() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testPromotedMethods())
+}
+
+func (suite *GoTestSuite) TestStructEquality() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testStructEquality())
+}
+
+func (suite *GoTestSuite) TestStructEqualityEvaluatesOnce() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testStructEqualityEvaluatesOnce())
+}
+
+func (suite *GoTestSuite) TestSwitchWithInit() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testSwitchWithInit())
+}
+
+func (suite *GoTestSuite) TestSwitchInLoop() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testSwitchInLoop())
+}
+
 func (suite *GoTestSuite) TestPointerAssignment() {
 	d := disk.NewMemDisk(30)
 	disk.Init(d)
@@ -558,6 +954,24 @@ func (suite *GoTestSuite) TestAnonymousAssign() {
 	suite.Equal(true, testAnonymousAssign())
 }
 
+func (suite *GoTestSuite) TestIfWithInit() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testIfWithInit())
+}
+
+func (suite *GoTestSuite) TestDiscardCall() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testDiscardCall())
+}
+
+func (suite *GoTestSuite) TestGlobalVariableMutation() {
+	d := disk.NewMemDisk(30)
+	disk.Init(d)
+	suite.Equal(true, testGlobalVariableMutation())
+}
+
 func TestSuite(t *testing.T) {
 	suite.Run(t, new(GoTestSuite))
 }
@@ -16,8 +16,34 @@ func testU32Len() bool {
 
 type Uint32 uint32
 
-// https://github.com/tchajed/goose/issues/14
-func failing_testU32NewtypeLen() bool {
+func testU32NewtypeLen() bool {
 	s := make([]byte, 20)
 	return Uint32(len(s)) == Uint32(20)
 }
+
+func testByteConversions() bool {
+	var ok = true
+	x := uint64(0x1ff)
+	var b byte = byte(x)
+	ok = ok && b == byte(0xff)
+	ok = ok && uint64(b) == 0xff
+	var r rune = 'a'
+	ok = ok && uint32(r) == uint32(97)
+	return ok
+}
+
+func testU64ToU8() bool {
+	var ok = true
+	x := uint64(0x1234)
+	ok = ok && uint8(x) == uint8(0x34)
+	ok = ok && uint32(uint8(x)) == uint32(0x34)
+	return ok
+}
+
+func testU32ToU8Newtype() bool {
+	var ok = true
+	x := Uint32(0x1ff)
+	ok = ok && uint8(x) == uint8(0xff)
+	ok = ok && Uint32(uint8(x)) == Uint32(0xff)
+	return ok
+}
@@ -33,6 +33,32 @@ func (t SquareStruct) Volume() uint64 {
 	return t.Side * t.Side * t.Side
 }
 
+type shape interface {
+	area() uint64
+}
+
+type rectangle struct {
+	width  uint64
+	height uint64
+}
+
+func (r rectangle) area() uint64 {
+	return r.width * r.height
+}
+
+type triangle struct {
+	base   uint64
+	height uint64
+}
+
+func (t triangle) area() uint64 {
+	return t.base * t.height / 2
+}
+
+func totalArea(s1 shape, s2 shape) uint64 {
+	return s1.area() + s2.area()
+}
+
 // ----------------------------
 // TESTS
 // ----------------------------
@@ -79,3 +105,31 @@ func testIfStmtInterface() bool {
 	}
 	return false
 }
+
+func testParamsInterface() bool {
+	s := SquareStruct{
+		Side: 3,
+	}
+	volume := measureVolumePlusNM(s, 1, 2)
+	return volume == 30
+}
+
+func testTwoImplementors() bool {
+	r := rectangle{width: 2, height: 3}
+	t := triangle{base: 4, height: 5}
+	return totalArea(r, t) == 16 && totalArea(t, t) == 20
+}
+
+func areaOrZero(s shape) uint64 {
+	if s == nil {
+		return 0
+	}
+	return s.area()
+}
+
+func testInterfaceNil() bool {
+	var s shape
+	ok := areaOrZero(s) == 0 && areaOrZero(nil) == 0
+	s = rectangle{width: 2, height: 2}
+	return ok && s != nil && areaOrZero(s) == 4
+}
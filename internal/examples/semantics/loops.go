@@ -160,3 +160,29 @@ func testNestedGoStyleLoopsNoComparison() bool {
 	}
 	return ok
 }
+
+func testImplicitContinueUntilBreak() bool {
+	var i uint64 = 0
+	var steps uint64 = 0
+	for {
+		steps = steps + 1
+		if i >= 3 {
+			break
+		}
+		i = i + 1
+	}
+	return i == 3 && steps == 4
+}
+
+func testInfiniteLoopBreak() bool {
+	var c = false
+	var n uint64 = 0
+	for {
+		if c {
+			break
+		}
+		n = n + 1
+		c = n == 5
+	}
+	return n == 5
+}
@@ -47,3 +47,73 @@ func testMapSize() bool {
 
 	return ok
 }
+
+func testMapAsSet() bool {
+	var ok = true
+
+	s := make(map[uint64]bool)
+	s[2] = true
+	s[5] = true
+
+	// membership of added elements
+	ok = ok && s[2]
+	ok = ok && s[5]
+
+	// missing elements read as false
+	ok = ok && !s[3]
+	if s[0] {
+		return false
+	}
+
+	return ok
+}
+
+func testMapDelete() bool {
+	var ok = true
+
+	m := make(map[uint64]uint64)
+	m[1] = 2
+	m[3] = 4
+	delete(m, 1)
+	ok = ok && uint64(len(m)) == 1
+
+	// deleted keys read as the zero value
+	_, present := m[1]
+	ok = ok && !present && m[1] == 0
+	ok = ok && m[3] == 4
+
+	// deleting an absent key has no effect
+	delete(m, 5)
+	ok = ok && uint64(len(m)) == 1
+	return ok
+}
+
+func testMapCommaOk() bool {
+	var ok = true
+
+	m := make(map[uint64]uint64)
+	m[1] = 0
+	v, present := m[1]
+	ok = ok && present && v == 0
+	v2, present2 := m[2]
+	ok = ok && !present2 && v2 == 0
+
+	var v3 uint64
+	var present3 bool
+	m[3] = 7
+	v3, present3 = m[3]
+	ok = ok && present3 && v3 == 7
+	return ok && m[3] == 7
+}
+
+func testMapLiteral() bool {
+	var ok = true
+
+	empty := map[uint64]uint64{}
+	ok = ok && uint64(len(empty)) == 0
+
+	m := map[uint64]uint64{1: 2, 3: 4}
+	ok = ok && uint64(len(m)) == 2
+	ok = ok && m[1] == 2 && m[3] == 4
+	return ok
+}
@@ -29,3 +29,34 @@ func testMultipleAssignToMap() bool {
 	x, m[0] = multReturnTwo()
 	return x == 2 && m[0] == 3
 }
+
+func testSwap() bool {
+	var x uint64 = 1
+	var y uint64 = 2
+	x, y = y, x
+	return x == 2 && y == 1
+}
+
+func testFibStep() bool {
+	var x uint64 = 0
+	var y uint64 = 1
+	for i := uint64(0); i < 5; i++ {
+		x, y = y, x+y
+	}
+	return x == 5 && y == 8
+}
+
+// the index on the left is evaluated before any assignment
+func testAssignIndexBeforeStores() bool {
+	a := make([]uint64, 3)
+	var i uint64 = 0
+	a[i], i = 1, 2
+	return a[0] == 1 && a[2] == 0 && i == 2
+}
+
+func testAssignSliceBeforeStores() bool {
+	var a = make([]uint64, 1)
+	old := a
+	a, a[0] = make([]uint64, 1), 5
+	return old[0] == 5 && a[0] == 0
+}
@@ -0,0 +1,22 @@
+package semantics
+
+import "sync"
+
+type lockedCounter struct {
+	mu sync.Mutex
+	n  uint64
+}
+
+func (c *lockedCounter) add(x uint64) {
+	c.mu.Lock()
+	c.n += x
+	c.mu.Unlock()
+}
+
+// tests
+func testMutexField() bool {
+	c := &lockedCounter{n: 1}
+	c.add(2)
+	c.add(3)
+	return c.n == 6
+}
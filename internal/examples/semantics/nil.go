@@ -25,3 +25,9 @@ func testComparePointerWrappedDefaultToNil() bool {
 	var s []byte
 	return s == nil
 }
+
+func testCompareNilOnLeft() bool {
+	var p *uint64
+	q := new(uint64)
+	return nil == p && nil != q
+}
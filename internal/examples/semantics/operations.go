@@ -124,3 +124,151 @@ func testManyParentheses() bool {
 func testPlusTimes() bool {
 	return (2+5)*2 == 14
 }
+
+type incCounter struct {
+	n uint64
+}
+
+func testIncDec() bool {
+	var ok = true
+	var x uint32 = 3
+	x++
+	ok = ok && x == 4
+	x--
+	x--
+	ok = ok && x == 2
+	c := &incCounter{n: 10}
+	c.n++
+	c.n++
+	ok = ok && c.n == 12
+	c.n--
+	return ok && c.n == 11
+}
+
+type flagSet struct {
+	bits uint32
+}
+
+func testCompoundAssign() bool {
+	var ok = true
+	var x uint64 = 3
+	x += 4
+	ok = ok && x == 7
+	x *= 3
+	ok = ok && x == 21
+	x %= 8
+	ok = ok && x == 5
+	x <<= 2
+	ok = ok && x == 20
+	f := &flagSet{}
+	f.bits |= 1
+	f.bits |= 4
+	ok = ok && f.bits == 5
+	f.bits &= 4
+	return ok && f.bits == 4
+}
+
+func testBoolEquality() bool {
+	var ok = true
+	t := true
+	f := false
+	ok = ok && t == t && f == f
+	ok = ok && t != f && f != t
+	ok = ok && !(t == f)
+	ok = ok && (t == !f)
+	return ok
+}
+
+func testConditionalDef() bool {
+	var ok = true
+	for i := uint64(0); i < 2; i++ {
+		var x uint64
+		if i == 0 {
+			x = 10
+		} else {
+			x = 20
+		}
+		ok = ok && x == 10*(i+1)
+	}
+	return ok
+}
+
+func earlyReturnBranches(x uint64) uint64 {
+	var y = x
+	if x == 1 {
+		return 1
+	} else if x == 0 {
+		y = 10
+	} else {
+		y += 2
+	}
+	if y > 5 {
+		return y
+	} else {
+		y = 0
+	}
+	return y
+}
+
+func testEarlyReturnBranches() bool {
+	var ok = true
+	ok = ok && earlyReturnBranches(0) == 10
+	ok = ok && earlyReturnBranches(1) == 1
+	ok = ok && earlyReturnBranches(2) == 0
+	ok = ok && earlyReturnBranches(4) == 6
+	return ok
+}
+
+type condCounter struct {
+	f uint64
+}
+
+func (c *condCounter) inc() {
+	c.f += 1
+}
+
+func testConditionalDefModified() bool {
+	n := uint64(2)
+	var s condCounter
+	if n == 2 {
+		s = condCounter{f: 1}
+	} else {
+		s = condCounter{f: 5}
+	}
+	s.inc()
+	var t condCounter
+	if s.f == 2 {
+		t = s
+	} else {
+		t = condCounter{f: 0}
+	}
+	t.f = 7
+	return s.f == 2 && t.f == 7
+}
+
+type indexCounter struct {
+	calls uint64
+}
+
+func (c *indexCounter) next() uint64 {
+	c.calls++
+	return c.calls - 1
+}
+
+// the index of a compound assignment is evaluated once
+func testCompoundAssignIndexOnce() bool {
+	s := make([]uint64, 3)
+	c := &indexCounter{}
+	s[c.next()] += 5
+	m := make(map[uint64]uint64)
+	m[c.next()] += 2
+	return s[0] == 5 && m[1] == 2 && c.calls == 2
+}
+
+func testIncIndexOnce() bool {
+	s := make([]uint64, 2)
+	c := &indexCounter{}
+	s[c.next()]++
+	s[c.next()]++
+	return s[0] == 1 && s[1] == 1 && c.calls == 2
+}
@@ -13,7 +13,7 @@ Definition findKey: val :=
     let: "found" := ref_to uint64T #0 in
     let: "ok" := ref_to boolT #false in
     MapIter "m" (λ: "k" <>,
-      (if: (~ (![boolT] "ok"))
+      (if: ~ (![boolT] "ok")
       then
         "found" <-[uint64T] "k";;
         "ok" <-[boolT] #true
@@ -53,10 +53,6 @@ Definition testAllocateFull: val :=
 
 (* closures.go *)
 
-Definition AdderType: ty := (uint64T -> uint64T)%ht.
-
-Definition MultipleArgsType: ty := (uint64T -> boolT -> uint64T)%ht.
-
 Definition adder: val :=
   rec: "adder" <> :=
     let: "sum" := ref_to uint64T #0 in
@@ -74,10 +70,41 @@ Definition testClosureBasic: val :=
       "pos" (![uint64T] "i");;
       "doub" (#2 * (![uint64T] "i"));;
       Continue);;
-    (if: (("pos" #0) = #45) && (("doub" #0) = #90)
+    (if: ("pos" #0 = #45) && ("doub" #0 = #90)
     then #true
     else #false).
 
+Definition applyClosure: val :=
+  rec: "applyClosure" "f" "x" :=
+    "f" "x".
+
+Definition testStoredClosure: val :=
+  rec: "testStoredClosure" <> :=
+    let: "double" := (λ: "x", #2 * "x") in
+    "double" #3 = #6.
+
+Definition testClosureArgument: val :=
+  rec: "testClosureArgument" <> :=
+    let: "n" := ref_to uint64T #4 in
+    applyClosure (λ: "x", "x" + (![uint64T] "n")) #3 = #7.
+
+Definition testClosureCapture: val :=
+  rec: "testClosureCapture" <> :=
+    let: "count" := ref_to uint64T #0 in
+    let: "incr" := (λ: <>,
+      "count" <-[uint64T] ((![uint64T] "count") + #1);;
+      #()
+      ) in
+    "incr" #();;
+    "incr" #();;
+    (![uint64T] "count") = #2.
+
+Definition testClosureMultipleReturn: val :=
+  rec: "testClosureMultipleReturn" <> :=
+    let: "divmod" := (λ: "x" "y", ("x" `quot` "y", "x" `rem` "y")) in
+    let: ("q", "r") := "divmod" #7 #2 in
+    ("q" = #3) && ("r" = #1).
+
 (* comparisons.go *)
 
 Definition testCompareAll: val :=
@@ -158,7 +185,18 @@ Definition testByteSliceToString: val :=
     SliceSet byteT "x" #0 #(U8 65);;
     SliceSet byteT "x" #1 #(U8 66);;
     SliceSet byteT "x" #2 #(U8 67);;
-    (byteSliceToString "x") = #(str"ABC").
+    byteSliceToString "x" = #(str"ABC").
+
+Definition testStringToByteSlice: val :=
+  rec: "testStringToByteSlice" <> :=
+    let: "s" := #(str"ABC") in
+    let: "p" := stringToByteSlice "s" in
+    let: "ok" := ref_to boolT (slice.len "p" = #3) in
+    "ok" <-[boolT] (((![boolT] "ok") && (SliceGet byteT "p" #0 = #(U8 65))) && (SliceGet byteT "p" #2 = #(U8 67)));;
+    SliceSet byteT "p" #0 #(U8 90);;
+    "ok" <-[boolT] ((![boolT] "ok") && ("s" = #(str"ABC")));;
+    "ok" <-[boolT] ((![boolT] "ok") && (byteSliceToString "p" = #(str"ZBC")));;
+    ![boolT] "ok".
 
 (* copy.go *)
 
@@ -168,7 +206,7 @@ Definition testCopySimple: val :=
     SliceSet byteT "x" #3 #(U8 1);;
     let: "y" := NewSlice byteT #10 in
     SliceCopy byteT "y" "x";;
-    (SliceGet byteT "y" #3) = #(U8 1).
+    SliceGet byteT "y" #3 = #(U8 1).
 
 Definition testCopyShorterDst: val :=
   rec: "testCopyShorterDst" <> :=
@@ -177,7 +215,7 @@ Definition testCopyShorterDst: val :=
     SliceSet byteT "x" #12 #(U8 2);;
     let: "y" := NewSlice byteT #10 in
     let: "n" := SliceCopy byteT "y" "x" in
-    ("n" = #10) && ((SliceGet byteT "y" #3) = #(U8 1)).
+    ("n" = #10) && (SliceGet byteT "y" #3 = #(U8 1)).
 
 Definition testCopyShorterSrc: val :=
   rec: "testCopyShorterSrc" <> :=
@@ -186,7 +224,97 @@ Definition testCopyShorterSrc: val :=
     SliceSet byteT "x" #3 #(U8 1);;
     SliceSet byteT "y" #12 #(U8 2);;
     let: "n" := SliceCopy byteT "y" "x" in
-    (("n" = #10) && ((SliceGet byteT "y" #3) = #(U8 1))) && ((SliceGet byteT "y" #12) = #(U8 2)).
+    (("n" = #10) && (SliceGet byteT "y" #3 = #(U8 1))) && (SliceGet byteT "y" #12 = #(U8 2)).
+
+(* defer.go *)
+
+Definition deferIncrement: val :=
+  rec: "deferIncrement" <> :=
+    let: "n" := ref (zero_val uint64T) in
+    let: "$defer" := ref_to (unitT -> unitT)%ht (λ: <>, #()) in
+    let: "$f" := ![(unitT -> unitT)%ht] "$defer" in
+    "$defer" <-[(unitT -> unitT)%ht] (λ: <>,
+      (λ: <>,
+        "n" <-[uint64T] ((![uint64T] "n") + #1);;
+        #()
+        ) #();;
+      "$f" #()
+      );;
+    let: "$r0" := #5 in
+    "n" <-[uint64T] "$r0";;
+    (![(unitT -> unitT)%ht] "$defer") #();;
+    ![uint64T] "n".
+
+Definition deferOrder: val :=
+  rec: "deferOrder" <> :=
+    let: "n" := ref (zero_val uint64T) in
+    let: "$defer" := ref_to (unitT -> unitT)%ht (λ: <>, #()) in
+    let: "$f" := ![(unitT -> unitT)%ht] "$defer" in
+    "$defer" <-[(unitT -> unitT)%ht] (λ: <>,
+      (λ: <>,
+        "n" <-[uint64T] ((![uint64T] "n") * #2);;
+        #()
+        ) #();;
+      "$f" #()
+      );;
+    let: "$f" := ![(unitT -> unitT)%ht] "$defer" in
+    "$defer" <-[(unitT -> unitT)%ht] (λ: <>,
+      (λ: <>,
+        "n" <-[uint64T] ((![uint64T] "n") + #1);;
+        #()
+        ) #();;
+      "$f" #()
+      );;
+    let: "$r0" := #3 in
+    "n" <-[uint64T] "$r0";;
+    (![(unitT -> unitT)%ht] "$defer") #();;
+    ![uint64T] "n".
+
+Definition testDeferModifiesNamedResult: val :=
+  rec: "testDeferModifiesNamedResult" <> :=
+    deferIncrement #() = #6.
+
+Definition testDeferOrder: val :=
+  rec: "testDeferOrder" <> :=
+    deferOrder #() = #8.
+
+Definition deferAdder := struct.decl [
+  "total" :: ptrT
+].
+
+Definition deferAdder__add: val :=
+  rec: "deferAdder__add" "a" "x" :=
+    (struct.get deferAdder "total" "a") <-[uint64T] ((![uint64T] (struct.get deferAdder "total" "a")) + "x");;
+    #().
+
+(* the receiver and arguments of a deferred call are evaluated by the defer
+   statement *)
+Definition deferArgs: val :=
+  rec: "deferArgs" "total" :=
+    let: "$defer" := ref_to (unitT -> unitT)%ht (λ: <>, #()) in
+    let: "x" := ref_to uint64T #1 in
+    let: "a" := ref_to (struct.t deferAdder) (struct.mk deferAdder [
+      "total" ::= "total"
+    ]) in
+    let: "$recv" := ![struct.t deferAdder] "a" in
+    let: "$a0" := ![uint64T] "x" in
+    let: "$f" := ![(unitT -> unitT)%ht] "$defer" in
+    "$defer" <-[(unitT -> unitT)%ht] (λ: <>,
+      deferAdder__add "$recv" "$a0";;
+      "$f" #()
+      );;
+    "x" <-[uint64T] #10;;
+    "a" <-[struct.t deferAdder] (struct.mk deferAdder [
+      "total" ::= ref (zero_val uint64T)
+    ]);;
+    (![(unitT -> unitT)%ht] "$defer") #();;
+    #().
+
+Definition testDeferEvaluatesArguments: val :=
+  rec: "testDeferEvaluatesArguments" <> :=
+    let: "total" := ref (zero_val uint64T) in
+    deferArgs "total";;
+    (![uint64T] "total") = #1.
 
 (* encoding.go *)
 
@@ -239,43 +367,77 @@ Definition roundtripEncDec64: val :=
 Definition testEncDec32Simple: val :=
   rec: "testEncDec32Simple" <> :=
     let: "ok" := ref_to boolT #true in
-    "ok" <-[boolT] ((![boolT] "ok") && ((roundtripEncDec32 #(U32 0)) = #(U32 0)));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((roundtripEncDec32 #(U32 1)) = #(U32 1)));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((roundtripEncDec32 #(U32 1231234)) = #(U32 1231234)));;
+    "ok" <-[boolT] ((![boolT] "ok") && (roundtripEncDec32 #(U32 0) = #(U32 0)));;
+    "ok" <-[boolT] ((![boolT] "ok") && (roundtripEncDec32 #(U32 1) = #(U32 1)));;
+    "ok" <-[boolT] ((![boolT] "ok") && (roundtripEncDec32 #(U32 1231234) = #(U32 1231234)));;
     ![boolT] "ok".
 
 Definition failing_testEncDec32: val :=
   rec: "failing_testEncDec32" <> :=
     let: "ok" := ref_to boolT #true in
-    "ok" <-[boolT] ((![boolT] "ok") && ((roundtripEncDec32 #(U32 3434807466)) = #(U32 3434807466)));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((roundtripEncDec32 (#1 ≪ #20)) = (#1 ≪ #20)));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((roundtripEncDec32 (#1 ≪ #18)) = (#1 ≪ #18)));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((roundtripEncDec32 (#1 ≪ #10)) = (#1 ≪ #10)));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((roundtripEncDec32 (#1 ≪ #0)) = (#1 ≪ #0)));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((roundtripEncDec32 ((#1 ≪ #32) - #1)) = ((#1 ≪ #32) - #1)));;
+    "ok" <-[boolT] ((![boolT] "ok") && (roundtripEncDec32 #(U32 3434807466) = #(U32 3434807466)));;
+    "ok" <-[boolT] ((![boolT] "ok") && (roundtripEncDec32 (#1 ≪ #20) = (#1 ≪ #20)));;
+    "ok" <-[boolT] ((![boolT] "ok") && (roundtripEncDec32 (#1 ≪ #18) = (#1 ≪ #18)));;
+    "ok" <-[boolT] ((![boolT] "ok") && (roundtripEncDec32 (#1 ≪ #10) = (#1 ≪ #10)));;
+    "ok" <-[boolT] ((![boolT] "ok") && (roundtripEncDec32 (#1 ≪ #0) = (#1 ≪ #0)));;
+    "ok" <-[boolT] ((![boolT] "ok") && (roundtripEncDec32 ((#1 ≪ #32) - #1) = ((#1 ≪ #32) - #1)));;
     ![boolT] "ok".
 
 Definition testEncDec64Simple: val :=
   rec: "testEncDec64Simple" <> :=
     let: "ok" := ref_to boolT #true in
-    "ok" <-[boolT] ((![boolT] "ok") && ((roundtripEncDec64 #0) = #0));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((roundtripEncDec64 #1) = #1));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((roundtripEncDec64 #1231234) = #1231234));;
+    "ok" <-[boolT] ((![boolT] "ok") && (roundtripEncDec64 #0 = #0));;
+    "ok" <-[boolT] ((![boolT] "ok") && (roundtripEncDec64 #1 = #1));;
+    "ok" <-[boolT] ((![boolT] "ok") && (roundtripEncDec64 #1231234 = #1231234));;
     ![boolT] "ok".
 
 Definition testEncDec64: val :=
   rec: "testEncDec64" <> :=
     let: "ok" := ref_to boolT #true in
-    "ok" <-[boolT] ((![boolT] "ok") && ((roundtripEncDec64 #62206846038638762) = #62206846038638762));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((roundtripEncDec64 (#1 ≪ #63)) = (#1 ≪ #63)));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((roundtripEncDec64 (#1 ≪ #47)) = (#1 ≪ #47)));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((roundtripEncDec64 (#1 ≪ #20)) = (#1 ≪ #20)));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((roundtripEncDec64 (#1 ≪ #18)) = (#1 ≪ #18)));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((roundtripEncDec64 (#1 ≪ #10)) = (#1 ≪ #10)));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((roundtripEncDec64 (#1 ≪ #0)) = (#1 ≪ #0)));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((roundtripEncDec64 ((#1 ≪ #64) - #1)) = ((#1 ≪ #64) - #1)));;
+    "ok" <-[boolT] ((![boolT] "ok") && (roundtripEncDec64 #62206846038638762 = #62206846038638762));;
+    "ok" <-[boolT] ((![boolT] "ok") && (roundtripEncDec64 (#1 ≪ #63) = (#1 ≪ #63)));;
+    "ok" <-[boolT] ((![boolT] "ok") && (roundtripEncDec64 (#1 ≪ #47) = (#1 ≪ #47)));;
+    "ok" <-[boolT] ((![boolT] "ok") && (roundtripEncDec64 (#1 ≪ #20) = (#1 ≪ #20)));;
+    "ok" <-[boolT] ((![boolT] "ok") && (roundtripEncDec64 (#1 ≪ #18) = (#1 ≪ #18)));;
+    "ok" <-[boolT] ((![boolT] "ok") && (roundtripEncDec64 (#1 ≪ #10) = (#1 ≪ #10)));;
+    "ok" <-[boolT] ((![boolT] "ok") && (roundtripEncDec64 (#1 ≪ #0) = (#1 ≪ #0)));;
+    "ok" <-[boolT] ((![boolT] "ok") && (roundtripEncDec64 ((#1 ≪ #64) - #1) = ((#1 ≪ #64) - #1)));;
+    ![boolT] "ok".
+
+(* errors.go *)
+
+(* helpers *)
+Definition errorIfZero: val :=
+  rec: "errorIfZero" "x" :=
+    (if: "x" = #0
+    then SOME (ref_to stringT #(str"zero"))
+    else NONE).
+
+(* tests *)
+Definition testErrorReturn: val :=
+  rec: "testErrorReturn" <> :=
+    let: "ok" := ref_to boolT #true in
+    "ok" <-[boolT] ((![boolT] "ok") && (errorIfZero #1 = NONE));;
+    "ok" <-[boolT] ((![boolT] "ok") && (errorIfZero #0 ≠ NONE));;
+    "ok" <-[boolT] ((![boolT] "ok") && ((match: errorIfZero #0 with NONE => Panic "nil error" | SOME "$msg" => ![stringT] "$msg" end) = #(str"zero")));;
+    ![boolT] "ok".
+
+Definition testErrorIdentity: val :=
+  rec: "testErrorIdentity" <> :=
+    let: "err1" := SOME (ref_to stringT #(str"same")) in
+    let: "err2" := SOME (ref_to stringT #(str"same")) in
+    let: "ok" := ref_to boolT #true in
+    "ok" <-[boolT] ((![boolT] "ok") && ("err1" = "err1"));;
+    "ok" <-[boolT] ((![boolT] "ok") && ("err1" ≠ "err2"));;
+    "ok" <-[boolT] ((![boolT] "ok") && ((match: "err1" with NONE => Panic "nil error" | SOME "$msg" => ![stringT] "$msg" end) = (match: "err2" with NONE => Panic "nil error" | SOME "$msg" => ![stringT] "$msg" end)));;
+    "ok" <-[boolT] ((![boolT] "ok") && (SOME (ref_to stringT #(str"")) ≠ NONE));;
     ![boolT] "ok".
 
+Definition testErrorZeroValue: val :=
+  rec: "testErrorZeroValue" <> :=
+    let: "err" := ref (zero_val errorT) in
+    (![errorT] "err") = NONE.
+
 (* first_class_function.go *)
 
 Definition FirstClassFunction: val :=
@@ -288,7 +450,27 @@ Definition ApplyF: val :=
 
 Definition testFirstClassFunction: val :=
   rec: "testFirstClassFunction" <> :=
-    (ApplyF #1 FirstClassFunction) = #11.
+    ApplyF #1 FirstClassFunction = #11.
+
+Definition makeMultiplier: val :=
+  rec: "makeMultiplier" "n" :=
+    (λ: "x", "x" * "n").
+
+Definition testFunctionParameter: val :=
+  rec: "testFunctionParameter" <> :=
+    ApplyF #3 (makeMultiplier #4) = #12.
+
+Definition testCallReturnedFunction: val :=
+  rec: "testCallReturnedFunction" <> :=
+    (makeMultiplier #2) #5 = #10.
+
+Definition testCallFunctionSlice: val :=
+  rec: "testCallFunctionSlice" <> :=
+    let: "fs" := (let: "$s" := NewSlice (uint64T -> uint64T)%ht #2 in
+     SliceSet (uint64T -> uint64T)%ht "$s" #0 FirstClassFunction;;
+     SliceSet (uint64T -> uint64T)%ht "$s" #1 (makeMultiplier #3);;
+     "$s") in
+    ((SliceGet (uint64T -> uint64T)%ht "fs" #0) #1 = #11) && ((SliceGet (uint64T -> uint64T)%ht "fs" #1) #2 = #6).
 
 (* function_ordering.go *)
 
@@ -323,43 +505,39 @@ Definition Pair := struct.decl [
 Definition failing_testFunctionOrdering: val :=
   rec: "failing_testFunctionOrdering" <> :=
     let: "arr" := ref_to (slice.T uint64T) (NewSlice uint64T #5) in
-    let: "e1" := struct.mk Editor [
+    let: "e1" := ref_to (struct.t Editor) (struct.mk Editor [
       "s" ::= SliceSkip uint64T (![slice.T uint64T] "arr") #0;
       "next_val" ::= #1
-    ] in
-    let: "e2" := struct.mk Editor [
+    ]) in
+    let: "e2" := ref_to (struct.t Editor) (struct.mk Editor [
       "s" ::= SliceSkip uint64T (![slice.T uint64T] "arr") #0;
       "next_val" ::= #101
-    ] in
-    (if: ((Editor__AdvanceReturn "e1" #2) + (Editor__AdvanceReturn "e2" #102)) ≠ #102
+    ]) in
+    (if: (Editor__AdvanceReturn "e1" #2 + Editor__AdvanceReturn "e2" #102) ≠ #102
+    then #false
+    else (if: SliceGet uint64T (![slice.T uint64T] "arr") #0 ≠ #101
+    then #false
+    else (if: addFour64 (Editor__AdvanceReturn "e1" #3) (Editor__AdvanceReturn "e2" #103) (Editor__AdvanceReturn "e2" #104) (Editor__AdvanceReturn "e1" #4) ≠ #210
+    then #false
+    else (if: SliceGet uint64T (![slice.T uint64T] "arr") #1 ≠ #102
+    then #false
+    else (if: SliceGet uint64T (![slice.T uint64T] "arr") #2 ≠ #3
     then #false
     else
-      (if: (SliceGet uint64T (![slice.T uint64T] "arr") #0) ≠ #101
+      let: "p" := struct.mk Pair [
+        "x" ::= Editor__AdvanceReturn "e1" #5;
+        "y" ::= Editor__AdvanceReturn "e2" #105
+      ] in
+      (if: SliceGet uint64T (![slice.T uint64T] "arr") #3 ≠ #104
       then #false
       else
-        (if: (addFour64 (Editor__AdvanceReturn "e1" #3) (Editor__AdvanceReturn "e2" #103) (Editor__AdvanceReturn "e2" #104) (Editor__AdvanceReturn "e1" #4)) ≠ #210
+        let: "q" := struct.mk Pair [
+          "y" ::= Editor__AdvanceReturn "e1" #6;
+          "x" ::= Editor__AdvanceReturn "e2" #106
+        ] in
+        (if: SliceGet uint64T (![slice.T uint64T] "arr") #4 ≠ #105
         then #false
-        else
-          (if: (SliceGet uint64T (![slice.T uint64T] "arr") #1) ≠ #102
-          then #false
-          else
-            (if: (SliceGet uint64T (![slice.T uint64T] "arr") #2) ≠ #3
-            then #false
-            else
-              let: "p" := struct.mk Pair [
-                "x" ::= Editor__AdvanceReturn "e1" #5;
-                "y" ::= Editor__AdvanceReturn "e2" #105
-              ] in
-              (if: (SliceGet uint64T (![slice.T uint64T] "arr") #3) ≠ #104
-              then #false
-              else
-                let: "q" := struct.mk Pair [
-                  "y" ::= Editor__AdvanceReturn "e1" #6;
-                  "x" ::= Editor__AdvanceReturn "e2" #106
-                ] in
-                (if: (SliceGet uint64T (![slice.T uint64T] "arr") #4) ≠ #105
-                then #false
-                else ((struct.get Pair "x" "p") + (struct.get Pair "x" "q")) = #109))))))).
+        else (struct.get Pair "x" "p" + struct.get Pair "x" "q") = #109))))))).
 
 Definition storeAndReturn: val :=
   rec: "storeAndReturn" "x" "v" :=
@@ -398,7 +576,7 @@ Definition testGenerics: val :=
       "a" ::= #10;
       "b" ::= #37
     ]) in
-    ((struct.get pair "a" "res") = #10) && ((struct.get pair "b" "res") = #37).
+    (struct.get pair "a" "res" = #10) && (struct.get pair "b" "res" = #37).
 
 (* int_conversions.go *)
 
@@ -407,41 +585,68 @@ Definition testU64ToU32: val :=
     let: "ok" := ref_to boolT #true in
     let: "x" := #1230 in
     let: "y" := #(U32 1230) in
-    "ok" <-[boolT] ((![boolT] "ok") && ((to_u32 "x") = "y"));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((to_u64 "y") = "x"));;
+    "ok" <-[boolT] ((![boolT] "ok") && (to_u32 "x" = "y"));;
+    "ok" <-[boolT] ((![boolT] "ok") && (to_u64 "y" = "x"));;
     ![boolT] "ok".
 
 Definition testU32Len: val :=
   rec: "testU32Len" <> :=
     let: "s" := NewSlice byteT #100 in
-    (to_u32 (slice.len "s")) = #(U32 100).
+    to_u32 (slice.len "s") = #(U32 100).
 
 Definition Uint32: ty := uint32T.
 
-(* https://github.com/tchajed/goose/issues/14 *)
-Definition failing_testU32NewtypeLen: val :=
-  rec: "failing_testU32NewtypeLen" <> :=
+Definition testU32NewtypeLen: val :=
+  rec: "testU32NewtypeLen" <> :=
     let: "s" := NewSlice byteT #20 in
-    (slice.len "s") = #(U32 20).
+    to_u32 (slice.len "s") = #(U32 20).
+
+Definition testByteConversions: val :=
+  rec: "testByteConversions" <> :=
+    let: "ok" := ref_to boolT #true in
+    let: "x" := #511 in
+    let: "b" := ref_to byteT (to_u8 "x") in
+    "ok" <-[boolT] ((![boolT] "ok") && ((![byteT] "b") = #(U8 255)));;
+    "ok" <-[boolT] ((![boolT] "ok") && (to_u64 (![byteT] "b") = #255));;
+    let: "r" := ref_to uint32T #(U32 97) in
+    "ok" <-[boolT] ((![boolT] "ok") && ((![uint32T] "r") = #(U32 97)));;
+    ![boolT] "ok".
+
+Definition testU64ToU8: val :=
+  rec: "testU64ToU8" <> :=
+    let: "ok" := ref_to boolT #true in
+    let: "x" := #4660 in
+    "ok" <-[boolT] ((![boolT] "ok") && (to_u8 "x" = #(U8 52)));;
+    "ok" <-[boolT] ((![boolT] "ok") && (to_u32 (to_u8 "x") = #(U32 52)));;
+    ![boolT] "ok".
+
+Definition testU32ToU8Newtype: val :=
+  rec: "testU32ToU8Newtype" <> :=
+    let: "ok" := ref_to boolT #true in
+    let: "x" := #(U32 511) in
+    "ok" <-[boolT] ((![boolT] "ok") && (to_u8 "x" = #(U8 255)));;
+    "ok" <-[boolT] ((![boolT] "ok") && (to_u32 (to_u8 "x") = #(U32 255)));;
+    ![boolT] "ok".
 
 (* interfaces.go *)
 
 Definition geometryInterface := struct.decl [
+  "$type" :: stringT;
   "Square" :: (unitT -> uint64T)%ht;
   "Volume" :: (unitT -> uint64T)%ht
 ].
 
 Definition measureArea: val :=
   rec: "measureArea" "t" :=
-    (struct.get geometryInterface "Square") "t".
+    (struct.get geometryInterface "Square" "t") #().
 
 Definition measureVolumePlusNM: val :=
   rec: "measureVolumePlusNM" "t" "n" "m" :=
-    (((struct.get geometryInterface "Volume") "t") + "n") + "m".
+    ((struct.get geometryInterface "Volume" "t") #() + "n") + "m".
 
 Definition measureVolume: val :=
   rec: "measureVolume" "t" :=
-    (struct.get geometryInterface "Volume") "t".
+    (struct.get geometryInterface "Volume" "t") #().
 
 Definition SquareStruct := struct.decl [
   "Side" :: uint64T
@@ -449,17 +654,45 @@ Definition SquareStruct := struct.decl [
 
 Definition SquareStruct__Square: val :=
   rec: "SquareStruct__Square" "t" :=
-    (struct.get SquareStruct "Side" "t") * (struct.get SquareStruct "Side" "t").
+    struct.get SquareStruct "Side" "t" * struct.get SquareStruct "Side" "t".
 
 Definition SquareStruct__Volume: val :=
   rec: "SquareStruct__Volume" "t" :=
-    ((struct.get SquareStruct "Side" "t") * (struct.get SquareStruct "Side" "t")) * (struct.get SquareStruct "Side" "t").
+    (struct.get SquareStruct "Side" "t" * struct.get SquareStruct "Side" "t") * struct.get SquareStruct "Side" "t".
+
+Definition shape := struct.decl [
+  "$type" :: stringT;
+  "area" :: (unitT -> uint64T)%ht
+].
+
+Definition rectangle := struct.decl [
+  "width" :: uint64T;
+  "height" :: uint64T
+].
+
+Definition rectangle__area: val :=
+  rec: "rectangle__area" "r" :=
+    struct.get rectangle "width" "r" * struct.get rectangle "height" "r".
+
+Definition triangle := struct.decl [
+  "base" :: uint64T;
+  "height" :: uint64T
+].
+
+Definition triangle__area: val :=
+  rec: "triangle__area" "t" :=
+    (struct.get triangle "base" "t" * struct.get triangle "height" "t") `quot` #2.
+
+Definition totalArea: val :=
+  rec: "totalArea" "s1" "s2" :=
+    (struct.get shape "area" "s1") #() + (struct.get shape "area" "s2") #().
 
 Definition SquareStruct__to__geometryInterface: val :=
-  rec: "SquareStruct_to_geometryInterface" "t" :=
+  rec: "SquareStruct__to__geometryInterface" "t" :=
     struct.mk geometryInterface [
-      "Square" ::= SquareStruct__Square "t";
-      "Volume" ::= SquareStruct__Volume "t"
+      "$type" ::= #(str"SquareStruct");
+      "Square" ::= (λ: <>, SquareStruct__Square "t");
+      "Volume" ::= (λ: <>, SquareStruct__Volume "t")
     ].
 
 Definition testBasicInterface: val :=
@@ -467,14 +700,14 @@ Definition testBasicInterface: val :=
     let: "s" := struct.mk SquareStruct [
       "Side" ::= #2
     ] in
-    ((measureArea (SquareStruct__to__geometryInterface "s"))) = #4.
+    measureArea (SquareStruct__to__geometryInterface "s") = #4.
 
 Definition testAssignInterface: val :=
   rec: "testAssignInterface" <> :=
     let: "s" := struct.mk SquareStruct [
       "Side" ::= #3
     ] in
-    let: "area" := (measureArea (SquareStruct__to__geometryInterface "s")) in
+    let: "area" := measureArea (SquareStruct__to__geometryInterface "s") in
     "area" = #9.
 
 Definition testMultipleInterface: val :=
@@ -482,8 +715,8 @@ Definition testMultipleInterface: val :=
     let: "s" := struct.mk SquareStruct [
       "Side" ::= #3
     ] in
-    let: "square1" := (measureArea (SquareStruct__to__geometryInterface "s")) in
-    let: "square2" := (measureArea (SquareStruct__to__geometryInterface "s")) in
+    let: "square1" := measureArea (SquareStruct__to__geometryInterface "s") in
+    let: "square2" := measureArea (SquareStruct__to__geometryInterface "s") in
     "square1" = "square2".
 
 Definition testBinaryExprInterface: val :=
@@ -491,19 +724,69 @@ Definition testBinaryExprInterface: val :=
     let: "s" := struct.mk SquareStruct [
       "Side" ::= #3
     ] in
-    let: "square1" := (measureArea (SquareStruct__to__geometryInterface "s")) in
-    let: "square2" := (measureVolume (SquareStruct__to__geometryInterface "s")) in
-    ("square1" = ((measureArea (SquareStruct__to__geometryInterface "s")))) && ("square2" = ((measureVolume (SquareStruct__to__geometryInterface "s")))).
+    let: "square1" := measureArea (SquareStruct__to__geometryInterface "s") in
+    let: "square2" := measureVolume (SquareStruct__to__geometryInterface "s") in
+    ("square1" = measureArea (SquareStruct__to__geometryInterface "s")) && ("square2" = measureVolume (SquareStruct__to__geometryInterface "s")).
 
 Definition testIfStmtInterface: val :=
   rec: "testIfStmtInterface" <> :=
     let: "s" := struct.mk SquareStruct [
       "Side" ::= #3
     ] in
-    (if: ((measureArea (SquareStruct__to__geometryInterface "s"))) = #9
+    (if: measureArea (SquareStruct__to__geometryInterface "s") = #9
     then #true
     else #false).
 
+Definition testParamsInterface: val :=
+  rec: "testParamsInterface" <> :=
+    let: "s" := struct.mk SquareStruct [
+      "Side" ::= #3
+    ] in
+    let: "volume" := measureVolumePlusNM (SquareStruct__to__geometryInterface "s") #1 #2 in
+    "volume" = #30.
+
+Definition rectangle__to__shape: val :=
+  rec: "rectangle__to__shape" "t" :=
+    struct.mk shape [
+      "$type" ::= #(str"rectangle");
+      "area" ::= (λ: <>, rectangle__area "t")
+    ].
+
+Definition triangle__to__shape: val :=
+  rec: "triangle__to__shape" "t" :=
+    struct.mk shape [
+      "$type" ::= #(str"triangle");
+      "area" ::= (λ: <>, triangle__area "t")
+    ].
+
+Definition testTwoImplementors: val :=
+  rec: "testTwoImplementors" <> :=
+    let: "r" := struct.mk rectangle [
+      "width" ::= #2;
+      "height" ::= #3
+    ] in
+    let: "t" := struct.mk triangle [
+      "base" ::= #4;
+      "height" ::= #5
+    ] in
+    (totalArea (rectangle__to__shape "r") (triangle__to__shape "t") = #16) && (totalArea (triangle__to__shape "t") (triangle__to__shape "t") = #20).
+
+Definition areaOrZero: val :=
+  rec: "areaOrZero" "s" :=
+    (if: struct.get shape "$type" "s" = #(str"")
+    then #0
+    else (struct.get shape "area" "s") #()).
+
+Definition testInterfaceNil: val :=
+  rec: "testInterfaceNil" <> :=
+    let: "s" := ref (zero_val (struct.t shape)) in
+    let: "ok" := (areaOrZero (![struct.t shape] "s") = #0) && (areaOrZero (zero_val (struct.t shape)) = #0) in
+    "s" <-[struct.t shape] (rectangle__to__shape (struct.mk rectangle [
+      "width" ::= #2;
+      "height" ::= #2
+    ]));;
+    ("ok" && (struct.get shape "$type" (![struct.t shape] "s") ≠ #(str""))) && (areaOrZero (![struct.t shape] "s") = #4).
+
 (* interfaces_failing.go *)
 
 (* lock.go *)
@@ -525,7 +808,7 @@ Definition standardForLoop: val :=
     let: "sumPtr" := ref (zero_val uint64T) in
     let: "i" := ref_to uint64T #0 in
     (for: (λ: <>, #true); (λ: <>, Skip) := λ: <>,
-      (if: (![uint64T] "i") < (slice.len "s")
+      (if: (![uint64T] "i") < slice.len "s"
       then
         let: "sum" := ![uint64T] "sumPtr" in
         let: "x" := SliceGet uint64T "s" (![uint64T] "i") in
@@ -543,7 +826,6 @@ Definition LoopStruct := struct.decl [
 
 Definition LoopStruct__forLoopWait: val :=
   rec: "LoopStruct__forLoopWait" "ls" "i" :=
-    Skip;;
     (for: (λ: <>, #true); (λ: <>, Skip) := λ: <>,
       let: "nxt" := struct.get LoopStruct "loopNext" "ls" in
       (if: "i" < (![uint64T] "nxt")
@@ -557,11 +839,15 @@ Definition LoopStruct__forLoopWait: val :=
 Definition testStandardForLoop: val :=
   rec: "testStandardForLoop" <> :=
     let: "arr" := ref_to (slice.T uint64T) (NewSlice uint64T #4) in
-    SliceSet uint64T (![slice.T uint64T] "arr") #0 ((SliceGet uint64T (![slice.T uint64T] "arr") #0) + #1);;
-    SliceSet uint64T (![slice.T uint64T] "arr") #1 ((SliceGet uint64T (![slice.T uint64T] "arr") #1) + #3);;
-    SliceSet uint64T (![slice.T uint64T] "arr") #2 ((SliceGet uint64T (![slice.T uint64T] "arr") #2) + #5);;
-    SliceSet uint64T (![slice.T uint64T] "arr") #3 ((SliceGet uint64T (![slice.T uint64T] "arr") #3) + #7);;
-    (standardForLoop (![slice.T uint64T] "arr")) = #16.
+    let: "$lhs0" := ![slice.T uint64T] "arr" in
+    SliceSet uint64T "$lhs0" #0 (SliceGet uint64T "$lhs0" #0 + #1);;
+    let: "$lhs0" := ![slice.T uint64T] "arr" in
+    SliceSet uint64T "$lhs0" #1 (SliceGet uint64T "$lhs0" #1 + #3);;
+    let: "$lhs0" := ![slice.T uint64T] "arr" in
+    SliceSet uint64T "$lhs0" #2 (SliceGet uint64T "$lhs0" #2 + #5);;
+    let: "$lhs0" := ![slice.T uint64T] "arr" in
+    SliceSet uint64T "$lhs0" #3 (SliceGet uint64T "$lhs0" #3 + #7);;
+    standardForLoop (![slice.T uint64T] "arr") = #16.
 
 Definition testForLoopWait: val :=
   rec: "testForLoopWait" <> :=
@@ -574,7 +860,6 @@ Definition testForLoopWait: val :=
 Definition testBreakFromLoopWithContinue: val :=
   rec: "testBreakFromLoopWithContinue" <> :=
     let: "i" := ref_to uint64T #0 in
-    Skip;;
     (for: (λ: <>, #true); (λ: <>, Skip) := λ: <>,
       (if: #true
       then
@@ -586,7 +871,6 @@ Definition testBreakFromLoopWithContinue: val :=
 Definition testBreakFromLoopNoContinue: val :=
   rec: "testBreakFromLoopNoContinue" <> :=
     let: "i" := ref_to uint64T #0 in
-    Skip;;
     (for: (λ: <>, (![uint64T] "i") < #3); (λ: <>, Skip) := λ: <>,
       (if: #true
       then
@@ -600,7 +884,6 @@ Definition testBreakFromLoopNoContinue: val :=
 Definition testBreakFromLoopNoContinueDouble: val :=
   rec: "testBreakFromLoopNoContinueDouble" <> :=
     let: "i" := ref_to uint64T #0 in
-    Skip;;
     (for: (λ: <>, (![uint64T] "i") < #3); (λ: <>, Skip) := λ: <>,
       (if: (![uint64T] "i") = #1
       then
@@ -615,7 +898,6 @@ Definition testBreakFromLoopNoContinueDouble: val :=
 Definition testBreakFromLoopForOnly: val :=
   rec: "testBreakFromLoopForOnly" <> :=
     let: "i" := ref_to uint64T #0 in
-    Skip;;
     (for: (λ: <>, (![uint64T] "i") < #3); (λ: <>, Skip) := λ: <>,
       "i" <-[uint64T] ((![uint64T] "i") + #2);;
       Continue);;
@@ -624,7 +906,6 @@ Definition testBreakFromLoopForOnly: val :=
 Definition testBreakFromLoopAssignAndContinue: val :=
   rec: "testBreakFromLoopAssignAndContinue" <> :=
     let: "i" := ref_to uint64T #0 in
-    Skip;;
     (for: (λ: <>, (![uint64T] "i") < #3); (λ: <>, Skip) := λ: <>,
       (if: #true
       then
@@ -682,6 +963,32 @@ Definition testNestedGoStyleLoopsNoComparison: val :=
       Continue);;
     ![boolT] "ok".
 
+Definition testImplicitContinueUntilBreak: val :=
+  rec: "testImplicitContinueUntilBreak" <> :=
+    let: "i" := ref_to uint64T #0 in
+    let: "steps" := ref_to uint64T #0 in
+    (for: (λ: <>, #true); (λ: <>, Skip) := λ: <>,
+      "steps" <-[uint64T] ((![uint64T] "steps") + #1);;
+      (if: (![uint64T] "i") ≥ #3
+      then Break
+      else
+        "i" <-[uint64T] ((![uint64T] "i") + #1);;
+        Continue));;
+    ((![uint64T] "i") = #3) && ((![uint64T] "steps") = #4).
+
+Definition testInfiniteLoopBreak: val :=
+  rec: "testInfiniteLoopBreak" <> :=
+    let: "c" := ref_to boolT #false in
+    let: "n" := ref_to uint64T #0 in
+    (for: (λ: <>, #true); (λ: <>, Skip) := λ: <>,
+      (if: ![boolT] "c"
+      then Break
+      else
+        "n" <-[uint64T] ((![uint64T] "n") + #1);;
+        "c" <-[boolT] ((![uint64T] "n") = #5);;
+        Continue));;
+    (![uint64T] "n") = #5.
+
 (* maps.go *)
 
 Definition IterateMapKeys: val :=
@@ -705,19 +1012,78 @@ Definition testIterateMap: val :=
     MapInsert "m" #0 #1;;
     MapInsert "m" #1 #2;;
     MapInsert "m" #3 #4;;
-    "ok" <-[boolT] ((![boolT] "ok") && ((IterateMapKeys "m") = #4));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((IterateMapValues "m") = #7));;
+    "ok" <-[boolT] ((![boolT] "ok") && (IterateMapKeys "m" = #4));;
+    "ok" <-[boolT] ((![boolT] "ok") && (IterateMapValues "m" = #7));;
     ![boolT] "ok".
 
 Definition testMapSize: val :=
   rec: "testMapSize" <> :=
     let: "ok" := ref_to boolT #true in
     let: "m" := NewMap uint64T uint64T #() in
-    "ok" <-[boolT] ((![boolT] "ok") && ((MapLen "m") = #0));;
+    "ok" <-[boolT] ((![boolT] "ok") && (MapLen "m" = #0));;
     MapInsert "m" #0 #1;;
     MapInsert "m" #1 #2;;
     MapInsert "m" #3 #4;;
-    "ok" <-[boolT] ((![boolT] "ok") && ((MapLen "m") = #3));;
+    "ok" <-[boolT] ((![boolT] "ok") && (MapLen "m" = #3));;
+    ![boolT] "ok".
+
+Definition testMapAsSet: val :=
+  rec: "testMapAsSet" <> :=
+    let: "ok" := ref_to boolT #true in
+    let: "s" := NewMap uint64T boolT #() in
+    MapInsert "s" #2 #true;;
+    MapInsert "s" #5 #true;;
+    "ok" <-[boolT] ((![boolT] "ok") && Fst (MapGet "s" #2));;
+    "ok" <-[boolT] ((![boolT] "ok") && Fst (MapGet "s" #5));;
+    "ok" <-[boolT] ((![boolT] "ok") && (~ (Fst (MapGet "s" #3))));;
+    (if: Fst (MapGet "s" #0)
+    then #false
+    else ![boolT] "ok").
+
+Definition testMapDelete: val :=
+  rec: "testMapDelete" <> :=
+    let: "ok" := ref_to boolT #true in
+    let: "m" := NewMap uint64T uint64T #() in
+    MapInsert "m" #1 #2;;
+    MapInsert "m" #3 #4;;
+    MapDelete "m" #1;;
+    "ok" <-[boolT] ((![boolT] "ok") && (MapLen "m" = #1));;
+    let: (<>, "present") := MapGet "m" #1 in
+    "ok" <-[boolT] (((![boolT] "ok") && (~ "present")) && (Fst (MapGet "m" #1) = #0));;
+    "ok" <-[boolT] ((![boolT] "ok") && (Fst (MapGet "m" #3) = #4));;
+    MapDelete "m" #5;;
+    "ok" <-[boolT] ((![boolT] "ok") && (MapLen "m" = #1));;
+    ![boolT] "ok".
+
+Definition testMapCommaOk: val :=
+  rec: "testMapCommaOk" <> :=
+    let: "ok" := ref_to boolT #true in
+    let: "m" := NewMap uint64T uint64T #() in
+    MapInsert "m" #1 #0;;
+    let: ("v", "present") := MapGet "m" #1 in
+    "ok" <-[boolT] (((![boolT] "ok") && "present") && ("v" = #0));;
+    let: ("v2", "present2") := MapGet "m" #2 in
+    "ok" <-[boolT] (((![boolT] "ok") && (~ "present2")) && ("v2" = #0));;
+    let: "v3" := ref (zero_val uint64T) in
+    let: "present3" := ref (zero_val boolT) in
+    MapInsert "m" #3 #7;;
+    let: ("0_ret", "1_ret") := MapGet "m" #3 in
+    "v3" <-[uint64T] "0_ret";;
+    "present3" <-[boolT] "1_ret";;
+    "ok" <-[boolT] (((![boolT] "ok") && (![boolT] "present3")) && ((![uint64T] "v3") = #7));;
+    (![boolT] "ok") && (Fst (MapGet "m" #3) = #7).
+
+Definition testMapLiteral: val :=
+  rec: "testMapLiteral" <> :=
+    let: "ok" := ref_to boolT #true in
+    let: "empty" := NewMap uint64T uint64T #() in
+    "ok" <-[boolT] ((![boolT] "ok") && (MapLen "empty" = #0));;
+    let: "m" := (let: "$m" := NewMap uint64T uint64T #() in
+     MapInsert "$m" #1 #2;;
+     MapInsert "$m" #3 #4;;
+     "$m") in
+    "ok" <-[boolT] ((![boolT] "ok") && (MapLen "m" = #2));;
+    "ok" <-[boolT] (((![boolT] "ok") && (Fst (MapGet "m" #1) = #2)) && (Fst (MapGet "m" #3) = #4));;
     ![boolT] "ok".
 
 (* multiple_assign.go *)
@@ -754,10 +1120,57 @@ Definition testMultipleAssignToMap: val :=
   rec: "testMultipleAssignToMap" <> :=
     let: "x" := ref_to uint64T #10 in
     let: "m" := ref_to (mapT uint64T) (NewMap uint64T uint64T #()) in
+    let: "$lhs0" := ![mapT uint64T] "m" in
     let: ("0_ret", "1_ret") := multReturnTwo #() in
     "x" <-[uint64T] "0_ret";;
-    MapInsert (![mapT uint64T] "m") #0 "1_ret";;
-    ((![uint64T] "x") = #2) && ((Fst (MapGet (![mapT uint64T] "m") #0)) = #3).
+    MapInsert "$lhs0" #0 "1_ret";;
+    ((![uint64T] "x") = #2) && (Fst (MapGet (![mapT uint64T] "m") #0) = #3).
+
+Definition testSwap: val :=
+  rec: "testSwap" <> :=
+    let: "x" := ref_to uint64T #1 in
+    let: "y" := ref_to uint64T #2 in
+    let: "0_ret" := ![uint64T] "y" in
+    let: "1_ret" := ![uint64T] "x" in
+    "x" <-[uint64T] "0_ret";;
+    "y" <-[uint64T] "1_ret";;
+    ((![uint64T] "x") = #2) && ((![uint64T] "y") = #1).
+
+Definition testFibStep: val :=
+  rec: "testFibStep" <> :=
+    let: "x" := ref_to uint64T #0 in
+    let: "y" := ref_to uint64T #1 in
+    let: "i" := ref_to uint64T #0 in
+    (for: (λ: <>, (![uint64T] "i") < #5); (λ: <>, "i" <-[uint64T] ((![uint64T] "i") + #1)) := λ: <>,
+      let: "0_ret" := ![uint64T] "y" in
+      let: "1_ret" := (![uint64T] "x") + (![uint64T] "y") in
+      "x" <-[uint64T] "0_ret";;
+      "y" <-[uint64T] "1_ret";;
+      Continue);;
+    ((![uint64T] "x") = #5) && ((![uint64T] "y") = #8).
+
+(* the index on the left is evaluated before any assignment *)
+Definition testAssignIndexBeforeStores: val :=
+  rec: "testAssignIndexBeforeStores" <> :=
+    let: "a" := NewSlice uint64T #3 in
+    let: "i" := ref_to uint64T #0 in
+    let: "$lhs0" := ![uint64T] "i" in
+    let: "0_ret" := #1 in
+    let: "1_ret" := #2 in
+    SliceSet uint64T "a" "$lhs0" "0_ret";;
+    "i" <-[uint64T] "1_ret";;
+    ((SliceGet uint64T "a" #0 = #1) && (SliceGet uint64T "a" #2 = #0)) && ((![uint64T] "i") = #2).
+
+Definition testAssignSliceBeforeStores: val :=
+  rec: "testAssignSliceBeforeStores" <> :=
+    let: "a" := ref_to (slice.T uint64T) (NewSlice uint64T #1) in
+    let: "old" := ![slice.T uint64T] "a" in
+    let: "$lhs0" := ![slice.T uint64T] "a" in
+    let: "0_ret" := NewSlice uint64T #1 in
+    let: "1_ret" := #5 in
+    "a" <-[slice.T uint64T] "0_ret";;
+    SliceSet uint64T "$lhs0" #0 "1_ret";;
+    (SliceGet uint64T "old" #0 = #5) && (SliceGet uint64T (![slice.T uint64T] "a") #0 = #0).
 
 (* multiple_return.go *)
 
@@ -793,6 +1206,31 @@ Definition testReturnFour: val :=
     let: ((("x", "y"), "z"), "w") := returnFour #() in
     ((("x" = #2) && ("y" = #true)) && ("z" = #(U32 1))) && ("w" = #7).
 
+(* mutex_field.go *)
+
+Definition lockedCounter := struct.decl [
+  "mu" :: ptrT;
+  "n" :: uint64T
+].
+
+Definition lockedCounter__add: val :=
+  rec: "lockedCounter__add" "c" "x" :=
+    lock.acquire (struct.loadF lockedCounter "mu" "c");;
+    struct.storeF lockedCounter "n" "c" (struct.loadF lockedCounter "n" "c" + "x");;
+    lock.release (struct.loadF lockedCounter "mu" "c");;
+    #().
+
+(* tests *)
+Definition testMutexField: val :=
+  rec: "testMutexField" <> :=
+    let: "c" := struct.new lockedCounter [
+      "n" ::= #1;
+      "mu" ::= lock.new #()
+    ] in
+    lockedCounter__add "c" #2;;
+    lockedCounter__add "c" #3;;
+    struct.loadF lockedCounter "n" "c" = #6.
+
 (* nil.go *)
 
 Definition failing_testCompareSliceToNil: val :=
@@ -821,6 +1259,12 @@ Definition testComparePointerWrappedDefaultToNil: val :=
     let: "s" := ref (zero_val (slice.T byteT)) in
     (![slice.T byteT] "s") = slice.nil.
 
+Definition testCompareNilOnLeft: val :=
+  rec: "testCompareNilOnLeft" <> :=
+    let: "p" := ref (zero_val ptrT) in
+    let: "q" := ref (zero_val uint64T) in
+    ((![ptrT] "p") = #null) && ("q" ≠ #null).
+
 (* operations.go *)
 
 (* helpers *)
@@ -838,8 +1282,8 @@ Definition reverseAssignOps32: val :=
     let: "y" := ref (zero_val uint32T) in
     "y" <-[uint32T] ((![uint32T] "y") + "x");;
     "y" <-[uint32T] ((![uint32T] "y") - "x");;
-    "y" <-[uint32T] ((![uint32T] "y") + #1);;
-    "y" <-[uint32T] ((![uint32T] "y") - #1);;
+    "y" <-[uint32T] ((![uint32T] "y") + #(U32 1));;
+    "y" <-[uint32T] ((![uint32T] "y") - #(U32 1));;
     ![uint32T] "y".
 
 Definition add64Equals: val :=
@@ -854,46 +1298,46 @@ Definition sub64Equals: val :=
 Definition testReverseAssignOps64: val :=
   rec: "testReverseAssignOps64" <> :=
     let: "ok" := ref_to boolT #true in
-    "ok" <-[boolT] ((![boolT] "ok") && ((reverseAssignOps64 #0) = #0));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((reverseAssignOps64 #1) = #0));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((reverseAssignOps64 #1231234) = #0));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((reverseAssignOps64 #62206846038638762) = #0));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((reverseAssignOps64 (#1 ≪ #63)) = #0));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((reverseAssignOps64 (#1 ≪ #47)) = #0));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((reverseAssignOps64 (#1 ≪ #20)) = #0));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((reverseAssignOps64 (#1 ≪ #18)) = #0));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((reverseAssignOps64 (#1 ≪ #10)) = #0));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((reverseAssignOps64 (#1 ≪ #0)) = #0));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((reverseAssignOps64 ((#1 ≪ #64) - #1)) = #0));;
+    "ok" <-[boolT] ((![boolT] "ok") && (reverseAssignOps64 #0 = #0));;
+    "ok" <-[boolT] ((![boolT] "ok") && (reverseAssignOps64 #1 = #0));;
+    "ok" <-[boolT] ((![boolT] "ok") && (reverseAssignOps64 #1231234 = #0));;
+    "ok" <-[boolT] ((![boolT] "ok") && (reverseAssignOps64 #62206846038638762 = #0));;
+    "ok" <-[boolT] ((![boolT] "ok") && (reverseAssignOps64 (#1 ≪ #63) = #0));;
+    "ok" <-[boolT] ((![boolT] "ok") && (reverseAssignOps64 (#1 ≪ #47) = #0));;
+    "ok" <-[boolT] ((![boolT] "ok") && (reverseAssignOps64 (#1 ≪ #20) = #0));;
+    "ok" <-[boolT] ((![boolT] "ok") && (reverseAssignOps64 (#1 ≪ #18) = #0));;
+    "ok" <-[boolT] ((![boolT] "ok") && (reverseAssignOps64 (#1 ≪ #10) = #0));;
+    "ok" <-[boolT] ((![boolT] "ok") && (reverseAssignOps64 (#1 ≪ #0) = #0));;
+    "ok" <-[boolT] ((![boolT] "ok") && (reverseAssignOps64 ((#1 ≪ #64) - #1) = #0));;
     ![boolT] "ok".
 
 Definition failing_testReverseAssignOps32: val :=
   rec: "failing_testReverseAssignOps32" <> :=
     let: "ok" := ref_to boolT #true in
-    "ok" <-[boolT] ((![boolT] "ok") && ((reverseAssignOps32 #(U32 0)) = #(U32 0)));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((reverseAssignOps32 #(U32 1)) = #(U32 0)));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((reverseAssignOps32 #(U32 1231234)) = #(U32 0)));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((reverseAssignOps32 #(U32 3434807466)) = #(U32 0)));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((reverseAssignOps32 (#1 ≪ #20)) = #(U32 0)));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((reverseAssignOps32 (#1 ≪ #18)) = #(U32 0)));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((reverseAssignOps32 (#1 ≪ #10)) = #(U32 0)));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((reverseAssignOps32 (#1 ≪ #0)) = #(U32 0)));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((reverseAssignOps32 ((#1 ≪ #32) - #1)) = #(U32 0)));;
+    "ok" <-[boolT] ((![boolT] "ok") && (reverseAssignOps32 #(U32 0) = #(U32 0)));;
+    "ok" <-[boolT] ((![boolT] "ok") && (reverseAssignOps32 #(U32 1) = #(U32 0)));;
+    "ok" <-[boolT] ((![boolT] "ok") && (reverseAssignOps32 #(U32 1231234) = #(U32 0)));;
+    "ok" <-[boolT] ((![boolT] "ok") && (reverseAssignOps32 #(U32 3434807466) = #(U32 0)));;
+    "ok" <-[boolT] ((![boolT] "ok") && (reverseAssignOps32 (#1 ≪ #20) = #(U32 0)));;
+    "ok" <-[boolT] ((![boolT] "ok") && (reverseAssignOps32 (#1 ≪ #18) = #(U32 0)));;
+    "ok" <-[boolT] ((![boolT] "ok") && (reverseAssignOps32 (#1 ≪ #10) = #(U32 0)));;
+    "ok" <-[boolT] ((![boolT] "ok") && (reverseAssignOps32 (#1 ≪ #0) = #(U32 0)));;
+    "ok" <-[boolT] ((![boolT] "ok") && (reverseAssignOps32 ((#1 ≪ #32) - #1) = #(U32 0)));;
     ![boolT] "ok".
 
 Definition testAdd64Equals: val :=
   rec: "testAdd64Equals" <> :=
     let: "ok" := ref_to boolT #true in
-    "ok" <-[boolT] ((![boolT] "ok") && (add64Equals #2 #3 #5));;
-    "ok" <-[boolT] ((![boolT] "ok") && (add64Equals ((#1 ≪ #64) - #1) #1 #0));;
+    "ok" <-[boolT] ((![boolT] "ok") && add64Equals #2 #3 #5);;
+    "ok" <-[boolT] ((![boolT] "ok") && add64Equals ((#1 ≪ #64) - #1) #1 #0);;
     ![boolT] "ok".
 
 Definition testSub64Equals: val :=
   rec: "testSub64Equals" <> :=
     let: "ok" := ref_to boolT #true in
-    "ok" <-[boolT] ((![boolT] "ok") && (sub64Equals #2 #1 #1));;
-    "ok" <-[boolT] ((![boolT] "ok") && (sub64Equals ((#1 ≪ #64) - #1) (#1 ≪ #63) ((#1 ≪ #63) - #1)));;
-    "ok" <-[boolT] ((![boolT] "ok") && (sub64Equals #2 #8 ((#1 ≪ #64) - #6)));;
+    "ok" <-[boolT] ((![boolT] "ok") && sub64Equals #2 #1 #1);;
+    "ok" <-[boolT] ((![boolT] "ok") && sub64Equals ((#1 ≪ #64) - #1) (#1 ≪ #63) ((#1 ≪ #63) - #1));;
+    "ok" <-[boolT] ((![boolT] "ok") && sub64Equals #2 #8 ((#1 ≪ #64) - #6));;
     ![boolT] "ok".
 
 Definition testDivisionPrecedence: val :=
@@ -944,6 +1388,165 @@ Definition testPlusTimes: val :=
   rec: "testPlusTimes" <> :=
     ((#2 + #5) * #2) = #14.
 
+Definition incCounter := struct.decl [
+  "n" :: uint64T
+].
+
+Definition testIncDec: val :=
+  rec: "testIncDec" <> :=
+    let: "ok" := ref_to boolT #true in
+    let: "x" := ref_to uint32T #(U32 3) in
+    "x" <-[uint32T] ((![uint32T] "x") + #(U32 1));;
+    "ok" <-[boolT] ((![boolT] "ok") && ((![uint32T] "x") = #(U32 4)));;
+    "x" <-[uint32T] ((![uint32T] "x") - #(U32 1));;
+    "x" <-[uint32T] ((![uint32T] "x") - #(U32 1));;
+    "ok" <-[boolT] ((![boolT] "ok") && ((![uint32T] "x") = #(U32 2)));;
+    let: "c" := struct.new incCounter [
+      "n" ::= #10
+    ] in
+    struct.storeF incCounter "n" "c" (struct.loadF incCounter "n" "c" + #1);;
+    struct.storeF incCounter "n" "c" (struct.loadF incCounter "n" "c" + #1);;
+    "ok" <-[boolT] ((![boolT] "ok") && (struct.loadF incCounter "n" "c" = #12));;
+    struct.storeF incCounter "n" "c" (struct.loadF incCounter "n" "c" - #1);;
+    (![boolT] "ok") && (struct.loadF incCounter "n" "c" = #11).
+
+Definition flagSet := struct.decl [
+  "bits" :: uint32T
+].
+
+Definition testCompoundAssign: val :=
+  rec: "testCompoundAssign" <> :=
+    let: "ok" := ref_to boolT #true in
+    let: "x" := ref_to uint64T #3 in
+    "x" <-[uint64T] ((![uint64T] "x") + #4);;
+    "ok" <-[boolT] ((![boolT] "ok") && ((![uint64T] "x") = #7));;
+    "x" <-[uint64T] ((![uint64T] "x") * #3);;
+    "ok" <-[boolT] ((![boolT] "ok") && ((![uint64T] "x") = #21));;
+    "x" <-[uint64T] ((![uint64T] "x") `rem` #8);;
+    "ok" <-[boolT] ((![boolT] "ok") && ((![uint64T] "x") = #5));;
+    "x" <-[uint64T] ((![uint64T] "x") ≪ #2);;
+    "ok" <-[boolT] ((![boolT] "ok") && ((![uint64T] "x") = #20));;
+    let: "f" := struct.new flagSet [
+    ] in
+    struct.storeF flagSet "bits" "f" (struct.loadF flagSet "bits" "f" `or` #(U32 1));;
+    struct.storeF flagSet "bits" "f" (struct.loadF flagSet "bits" "f" `or` #(U32 4));;
+    "ok" <-[boolT] ((![boolT] "ok") && (struct.loadF flagSet "bits" "f" = #(U32 5)));;
+    struct.storeF flagSet "bits" "f" (struct.loadF flagSet "bits" "f" `and` #(U32 4));;
+    (![boolT] "ok") && (struct.loadF flagSet "bits" "f" = #(U32 4)).
+
+Definition testBoolEquality: val :=
+  rec: "testBoolEquality" <> :=
+    let: "ok" := ref_to boolT #true in
+    let: "t" := #true in
+    let: "f" := #false in
+    "ok" <-[boolT] (((![boolT] "ok") && ("t" = "t")) && ("f" = "f"));;
+    "ok" <-[boolT] (((![boolT] "ok") && ("t" ≠ "f")) && ("f" ≠ "t"));;
+    "ok" <-[boolT] ((![boolT] "ok") && (~ ("t" = "f")));;
+    "ok" <-[boolT] ((![boolT] "ok") && ("t" = (~ "f")));;
+    ![boolT] "ok".
+
+Definition testConditionalDef: val :=
+  rec: "testConditionalDef" <> :=
+    let: "ok" := ref_to boolT #true in
+    let: "i" := ref_to uint64T #0 in
+    (for: (λ: <>, (![uint64T] "i") < #2); (λ: <>, "i" <-[uint64T] ((![uint64T] "i") + #1)) := λ: <>,
+      let: "x" := (if: (![uint64T] "i") = #0
+        then #10
+        else #20) in
+      "ok" <-[boolT] ((![boolT] "ok") && ("x" = (#10 * ((![uint64T] "i") + #1))));;
+      Continue);;
+    ![boolT] "ok".
+
+Definition earlyReturnBranches: val :=
+  rec: "earlyReturnBranches" "x" :=
+    let: "y" := ref_to uint64T "x" in
+    (if: "x" = #1
+    then #1
+    else
+      (if: "x" = #0
+      then "y" <-[uint64T] #10
+      else "y" <-[uint64T] ((![uint64T] "y") + #2));;
+      (if: (![uint64T] "y") > #5
+      then ![uint64T] "y"
+      else
+        "y" <-[uint64T] #0;;
+        ![uint64T] "y")).
+
+Definition testEarlyReturnBranches: val :=
+  rec: "testEarlyReturnBranches" <> :=
+    let: "ok" := ref_to boolT #true in
+    "ok" <-[boolT] ((![boolT] "ok") && (earlyReturnBranches #0 = #10));;
+    "ok" <-[boolT] ((![boolT] "ok") && (earlyReturnBranches #1 = #1));;
+    "ok" <-[boolT] ((![boolT] "ok") && (earlyReturnBranches #2 = #0));;
+    "ok" <-[boolT] ((![boolT] "ok") && (earlyReturnBranches #4 = #6));;
+    ![boolT] "ok".
+
+Definition condCounter := struct.decl [
+  "f" :: uint64T
+].
+
+Definition condCounter__inc: val :=
+  rec: "condCounter__inc" "c" :=
+    struct.storeF condCounter "f" "c" (struct.loadF condCounter "f" "c" + #1);;
+    #().
+
+Definition testConditionalDefModified: val :=
+  rec: "testConditionalDefModified" <> :=
+    let: "n" := #2 in
+    let: "s" := ref (zero_val (struct.t condCounter)) in
+    (if: "n" = #2
+    then
+      "s" <-[struct.t condCounter] (struct.mk condCounter [
+        "f" ::= #1
+      ])
+    else
+      "s" <-[struct.t condCounter] (struct.mk condCounter [
+        "f" ::= #5
+      ]));;
+    condCounter__inc "s";;
+    let: "t" := ref (zero_val (struct.t condCounter)) in
+    (if: struct.get condCounter "f" (![struct.t condCounter] "s") = #2
+    then "t" <-[struct.t condCounter] (![struct.t condCounter] "s")
+    else
+      "t" <-[struct.t condCounter] (struct.mk condCounter [
+        "f" ::= #0
+      ]));;
+    struct.storeF condCounter "f" "t" #7;;
+    (struct.get condCounter "f" (![struct.t condCounter] "s") = #2) && (struct.get condCounter "f" (![struct.t condCounter] "t") = #7).
+
+Definition indexCounter := struct.decl [
+  "calls" :: uint64T
+].
+
+Definition indexCounter__next: val :=
+  rec: "indexCounter__next" "c" :=
+    struct.storeF indexCounter "calls" "c" (struct.loadF indexCounter "calls" "c" + #1);;
+    struct.loadF indexCounter "calls" "c" - #1.
+
+(* the index of a compound assignment is evaluated once *)
+Definition testCompoundAssignIndexOnce: val :=
+  rec: "testCompoundAssignIndexOnce" <> :=
+    let: "s" := NewSlice uint64T #3 in
+    let: "c" := struct.new indexCounter [
+    ] in
+    let: "$lhs0" := indexCounter__next "c" in
+    SliceSet uint64T "s" "$lhs0" (SliceGet uint64T "s" "$lhs0" + #5);;
+    let: "m" := NewMap uint64T uint64T #() in
+    let: "$lhs0" := indexCounter__next "c" in
+    MapInsert "m" "$lhs0" (Fst (MapGet "m" "$lhs0") + #2);;
+    ((SliceGet uint64T "s" #0 = #5) && (Fst (MapGet "m" #1) = #2)) && (struct.loadF indexCounter "calls" "c" = #2).
+
+Definition testIncIndexOnce: val :=
+  rec: "testIncIndexOnce" <> :=
+    let: "s" := NewSlice uint64T #2 in
+    let: "c" := struct.new indexCounter [
+    ] in
+    let: "$lhs0" := indexCounter__next "c" in
+    SliceSet uint64T "s" "$lhs0" (SliceGet uint64T "s" "$lhs0" + #1);;
+    let: "$lhs0" := indexCounter__next "c" in
+    SliceSet uint64T "s" "$lhs0" (SliceGet uint64T "s" "$lhs0" + #1);;
+    ((SliceGet uint64T "s" #0 = #1) && (SliceGet uint64T "s" #1 = #1)) && (struct.loadF indexCounter "calls" "c" = #2).
+
 (* precedence.go *)
 
 Definition testOrCompareSimple: val :=
@@ -955,7 +1558,7 @@ Definition testOrCompareSimple: val :=
 Definition testOrCompare: val :=
   rec: "testOrCompare" <> :=
     let: "ok" := ref_to boolT #true in
-    (if: (~ ((#3 > #4) || (#4 > #3)))
+    (if: ~ ((#3 > #4) || (#4 > #3))
     then "ok" <-[boolT] #false
     else #());;
     (if: (#4 < #3) || (#2 > #3)
@@ -1000,12 +1603,12 @@ Definition BoolTest := struct.decl [
 
 Definition CheckTrue: val :=
   rec: "CheckTrue" "b" :=
-    struct.storeF BoolTest "tc" "b" ((struct.loadF BoolTest "tc" "b") + #1);;
+    struct.storeF BoolTest "tc" "b" (struct.loadF BoolTest "tc" "b" + #1);;
     struct.loadF BoolTest "t" "b".
 
 Definition CheckFalse: val :=
   rec: "CheckFalse" "b" :=
-    struct.storeF BoolTest "fc" "b" ((struct.loadF BoolTest "fc" "b") + #1);;
+    struct.storeF BoolTest "fc" "b" (struct.loadF BoolTest "fc" "b" + #1);;
     struct.loadF BoolTest "f" "b".
 
 (* tests *)
@@ -1017,9 +1620,9 @@ Definition testShortcircuitAndTF: val :=
       "tc" ::= #0;
       "fc" ::= #0
     ] in
-    (if: (CheckTrue "b") && (CheckFalse "b")
+    (if: CheckTrue "b" && CheckFalse "b"
     then #false
-    else ((struct.loadF BoolTest "tc" "b") = #1) && ((struct.loadF BoolTest "fc" "b") = #1)).
+    else (struct.loadF BoolTest "tc" "b" = #1) && (struct.loadF BoolTest "fc" "b" = #1)).
 
 Definition testShortcircuitAndFT: val :=
   rec: "testShortcircuitAndFT" <> :=
@@ -1029,9 +1632,9 @@ Definition testShortcircuitAndFT: val :=
       "tc" ::= #0;
       "fc" ::= #0
     ] in
-    (if: (CheckFalse "b") && (CheckTrue "b")
+    (if: CheckFalse "b" && CheckTrue "b"
     then #false
-    else ((struct.loadF BoolTest "tc" "b") = #0) && ((struct.loadF BoolTest "fc" "b") = #1)).
+    else (struct.loadF BoolTest "tc" "b" = #0) && (struct.loadF BoolTest "fc" "b" = #1)).
 
 Definition testShortcircuitOrTF: val :=
   rec: "testShortcircuitOrTF" <> :=
@@ -1041,8 +1644,8 @@ Definition testShortcircuitOrTF: val :=
       "tc" ::= #0;
       "fc" ::= #0
     ] in
-    (if: (CheckTrue "b") || (CheckFalse "b")
-    then ((struct.loadF BoolTest "tc" "b") = #1) && ((struct.loadF BoolTest "fc" "b") = #0)
+    (if: CheckTrue "b" || CheckFalse "b"
+    then (struct.loadF BoolTest "tc" "b" = #1) && (struct.loadF BoolTest "fc" "b" = #0)
     else #false).
 
 Definition testShortcircuitOrFT: val :=
@@ -1053,8 +1656,8 @@ Definition testShortcircuitOrFT: val :=
       "tc" ::= #0;
       "fc" ::= #0
     ] in
-    (if: (CheckFalse "b") || (CheckTrue "b")
-    then ((struct.loadF BoolTest "tc" "b") = #1) && ((struct.loadF BoolTest "fc" "b") = #1)
+    (if: CheckFalse "b" || CheckTrue "b"
+    then (struct.loadF BoolTest "tc" "b" = #1) && (struct.loadF BoolTest "fc" "b" = #1)
     else #false).
 
 (* slices.go *)
@@ -1067,7 +1670,7 @@ Definition ArrayEditor := struct.decl [
 
 Definition ArrayEditor__Advance: val :=
   rec: "ArrayEditor__Advance" "ae" "arr" "next" :=
-    SliceSet uint64T "arr" #0 ((SliceGet uint64T "arr" #0) + #1);;
+    SliceSet uint64T "arr" #0 (SliceGet uint64T "arr" #0 + #1);;
     SliceSet uint64T (struct.loadF ArrayEditor "s" "ae") #0 (struct.loadF ArrayEditor "next_val" "ae");;
     struct.storeF ArrayEditor "next_val" "ae" "next";;
     struct.storeF ArrayEditor "s" "ae" (SliceSkip uint64T (struct.loadF ArrayEditor "s" "ae") #1);;
@@ -1087,11 +1690,11 @@ Definition testSliceOps: val :=
     let: "v4" := SliceRef uint64T "x" #2 in
     let: "ok" := ref_to boolT #true in
     "ok" <-[boolT] ((![boolT] "ok") && ("v1" = #10));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((SliceGet uint64T "v2" #0) = #10));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((slice.len "v2") = #1));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((SliceGet uint64T "v3" #1) = #5));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((SliceGet uint64T "v3" #2) = #10));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((slice.len "v3") = #3));;
+    "ok" <-[boolT] ((![boolT] "ok") && (SliceGet uint64T "v2" #0 = #10));;
+    "ok" <-[boolT] ((![boolT] "ok") && (slice.len "v2" = #1));;
+    "ok" <-[boolT] ((![boolT] "ok") && (SliceGet uint64T "v3" #1 = #5));;
+    "ok" <-[boolT] ((![boolT] "ok") && (SliceGet uint64T "v3" #2 = #10));;
+    "ok" <-[boolT] ((![boolT] "ok") && (slice.len "v3" = #3));;
     "ok" <-[boolT] ((![boolT] "ok") && ((![uint64T] "v4") = #10));;
     ![boolT] "ok".
 
@@ -1103,12 +1706,12 @@ Definition testSliceCapacityOps: val :=
     let: "sub2" := SliceSubslice uint64T "x" #2 #4 in
     SliceSet uint64T "sub2" #0 #2;;
     let: "ok" := ref_to boolT #true in
-    "ok" <-[boolT] ((![boolT] "ok") && ((slice.len "sub1") = #6));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((slice.cap "sub1") = #10));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((SliceGet uint64T (SliceTake "x" #10) #0) = #1));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((slice.len "sub2") = #2));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((slice.cap "sub2") = #8));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((SliceGet uint64T (SliceTake "x" #10) #2) = #2));;
+    "ok" <-[boolT] ((![boolT] "ok") && (slice.len "sub1" = #6));;
+    "ok" <-[boolT] ((![boolT] "ok") && (slice.cap "sub1" = #10));;
+    "ok" <-[boolT] ((![boolT] "ok") && (SliceGet uint64T (SliceTake "x" #10) #0 = #1));;
+    "ok" <-[boolT] ((![boolT] "ok") && (slice.len "sub2" = #2));;
+    "ok" <-[boolT] ((![boolT] "ok") && (slice.cap "sub2" = #8));;
+    "ok" <-[boolT] ((![boolT] "ok") && (SliceGet uint64T (SliceTake "x" #10) #2 = #2));;
     ![boolT] "ok".
 
 Definition testOverwriteArray: val :=
@@ -1129,16 +1732,154 @@ Definition testOverwriteArray: val :=
     ArrayEditor__Advance "ae1" (![slice.T uint64T] "arr") #3;;
     ArrayEditor__Advance "ae1" (![slice.T uint64T] "arr") #4;;
     ArrayEditor__Advance "ae1" (![slice.T uint64T] "arr") #5;;
-    (if: ((((SliceGet uint64T (![slice.T uint64T] "arr") #0) + (SliceGet uint64T (![slice.T uint64T] "arr") #1)) + (SliceGet uint64T (![slice.T uint64T] "arr") #2)) + (SliceGet uint64T (![slice.T uint64T] "arr") #3)) ≥ #100
+    (if: (((SliceGet uint64T (![slice.T uint64T] "arr") #0 + SliceGet uint64T (![slice.T uint64T] "arr") #1) + SliceGet uint64T (![slice.T uint64T] "arr") #2) + SliceGet uint64T (![slice.T uint64T] "arr") #3) ≥ #100
     then #false
-    else ((SliceGet uint64T (![slice.T uint64T] "arr") #3) = #4) && ((SliceGet uint64T (![slice.T uint64T] "arr") #0) = #4)).
+    else (SliceGet uint64T (![slice.T uint64T] "arr") #3 = #4) && (SliceGet uint64T (![slice.T uint64T] "arr") #0 = #4)).
+
+Definition doubleSlice: val :=
+  rec: "doubleSlice" "s" :=
+    ForSlice uint64T "i" <> "s"
+      (SliceSet uint64T "s" "i" (#2 * SliceGet uint64T "s" "i"));;
+    #().
+
+Definition testDoubleSliceInPlace: val :=
+  rec: "testDoubleSliceInPlace" <> :=
+    let: "s" := NewSlice uint64T #3 in
+    SliceSet uint64T "s" #0 #1;;
+    SliceSet uint64T "s" #1 #2;;
+    SliceSet uint64T "s" #2 #3;;
+    doubleSlice "s";;
+    ((SliceGet uint64T "s" #0 = #2) && (SliceGet uint64T "s" #1 = #4)) && (SliceGet uint64T "s" #2 = #6).
+
+Definition twoElements: val :=
+  rec: "twoElements" <> :=
+    let: "s" := NewSlice uint64T #2 in
+    SliceSet uint64T "s" #0 #5;;
+    SliceSet uint64T "s" #1 #6;;
+    "s".
+
+Definition testAppendCallResult: val :=
+  rec: "testAppendCallResult" <> :=
+    let: "s" := ref_to (slice.T uint64T) (NewSlice uint64T #1) in
+    "s" <-[slice.T uint64T] (SliceAppendSlice uint64T (![slice.T uint64T] "s") (twoElements #()));;
+    ((slice.len (![slice.T uint64T] "s") = #3) && (SliceGet uint64T (![slice.T uint64T] "s") #0 = #0)) && (SliceGet uint64T (![slice.T uint64T] "s") #2 = #6).
+
+Definition testSliceLiteral: val :=
+  rec: "testSliceLiteral" <> :=
+    let: "ok" := ref_to boolT #true in
+    let: "empty" := NewSlice uint64T #0 in
+    "ok" <-[boolT] ((![boolT] "ok") && (slice.len "empty" = #0));;
+    let: "s" := (let: "$s" := NewSlice uint64T #3 in
+     SliceSet uint64T "$s" #0 #1;;
+     SliceSet uint64T "$s" #1 #2;;
+     SliceSet uint64T "$s" #2 #3;;
+     "$s") in
+    "ok" <-[boolT] ((![boolT] "ok") && (slice.len "s" = #3));;
+    "ok" <-[boolT] ((((![boolT] "ok") && (SliceGet uint64T "s" #0 = #1)) && (SliceGet uint64T "s" #1 = #2)) && (SliceGet uint64T "s" #2 = #3));;
+    ![boolT] "ok".
+
+Definition testSparseSliceLiteral: val :=
+  rec: "testSparseSliceLiteral" <> :=
+    let: "s" := (let: "$s" := NewSlice uint64T #4 in
+     SliceSet uint64T "$s" #3 #7;;
+     SliceSet uint64T "$s" #1 #2;;
+     "$s") in
+    ((((slice.len "s" = #4) && (SliceGet uint64T "s" #0 = #0)) && (SliceGet uint64T "s" #1 = #2)) && (SliceGet uint64T "s" #2 = #0)) && (SliceGet uint64T "s" #3 = #7).
+
+Definition uint64s: ty := slice.T uint64T.
+
+Definition testSliceElementPointer: val :=
+  rec: "testSliceElementPointer" <> :=
+    let: "s" := NewSlice uint64T #3 in
+    let: "p" := SliceRef uint64T "s" #1 in
+    "p" <-[uint64T] #5;;
+    let: "t" := SliceSkip uint64T "s" #1 in
+    let: "q" := SliceRef uint64T "t" #1 in
+    "q" <-[uint64T] #7;;
+    (((SliceGet uint64T "s" #1 = #5) && (SliceGet uint64T "t" #0 = #5)) && (SliceGet uint64T "s" #2 = #7)) && ((![uint64T] "p") = #5).
+
+Definition testArraySliceSharesStorage: val :=
+  rec: "testArraySliceSharesStorage" <> :=
+    let: "a" := ref_to (arrayT uint64T) (array.mk uint64T [#1; #2; #3]) in
+    let: "s" := SliceSkip uint64T (array.toSlice uint64T "a" #3) #1 in
+    SliceSet uint64T "s" #0 #5;;
+    let: "t" := array.toSlice uint64T "a" #3 in
+    SliceGet uint64T "t" #1 = #5.
+
+(* spawn.go *)
+
+(* helpers *)
+Definition storeArg: val :=
+  rec: "storeArg" "wg" "dst" "x" :=
+    "dst" <-[uint64T] "x";;
+    waitgroup.Done "wg";;
+    #().
+
+Definition argStorer := struct.decl [
+  "wg" :: ptrT;
+  "dst" :: ptrT
+].
+
+Definition argStorer__store: val :=
+  rec: "argStorer__store" "s" "x" :=
+    storeArg (struct.get argStorer "wg" "s") (struct.get argStorer "dst" "s") "x";;
+    #().
+
+(* failing: the interpreter does not run forked threads *)
+Definition failing_testGoArgCapture: val :=
+  rec: "failing_testGoArgCapture" <> :=
+    let: "x" := ref_to uint64T #1 in
+    let: "result" := ref (zero_val uint64T) in
+    let: "wg" := waitgroup.New #() in
+    waitgroup.Add "wg" #1;;
+    let: "$a0" := "wg" in
+    let: "$a1" := "result" in
+    let: "$a2" := ![uint64T] "x" in
+    Fork (storeArg "$a0" "$a1" "$a2");;
+    "x" <-[uint64T] #2;;
+    waitgroup.Wait "wg";;
+    ((![uint64T] "result") = #1) && ((![uint64T] "x") = #2).
+
+(* failing: the interpreter does not run forked threads *)
+Definition failing_testGoClosureCapture: val :=
+  rec: "failing_testGoClosureCapture" <> :=
+    let: "total" := ref (zero_val uint64T) in
+    let: "x" := #3 in
+    let: "wg" := waitgroup.New #() in
+    waitgroup.Add "wg" #1;;
+    Fork ("total" <-[uint64T] ((![uint64T] "total") + "x");;
+          waitgroup.Done "wg");;
+    waitgroup.Wait "wg";;
+    (![uint64T] "total") = #3.
+
+(* failing: the interpreter does not run forked threads *)
+Definition failing_testGoMethodArgCapture: val :=
+  rec: "failing_testGoMethodArgCapture" <> :=
+    let: "x" := ref_to uint64T #1 in
+    let: "wg" := waitgroup.New #() in
+    let: "s" := ref_to (struct.t argStorer) (struct.mk argStorer [
+      "wg" ::= "wg";
+      "dst" ::= ref (zero_val uint64T)
+    ]) in
+    let: "result" := struct.get argStorer "dst" (![struct.t argStorer] "s") in
+    waitgroup.Add "wg" #1;;
+    let: "$recv" := ![struct.t argStorer] "s" in
+    let: "$a0" := ![uint64T] "x" in
+    Fork (argStorer__store "$recv" "$a0");;
+    "x" <-[uint64T] #2;;
+    "s" <-[struct.t argStorer] (struct.mk argStorer [
+      "wg" ::= "wg";
+      "dst" ::= ref (zero_val uint64T)
+    ]);;
+    waitgroup.Wait "wg";;
+    ((![uint64T] "result") = #1) && ((![uint64T] (struct.get argStorer "dst" (![struct.t argStorer] "s"))) = #0).
 
 (* strings.go *)
 
 (* helpers *)
 Definition stringAppend: val :=
   rec: "stringAppend" "s" "x" :=
-    "s" + (uint64_to_string "x").
+    "s" + uint64_to_string "x".
 
 Definition stringLength: val :=
   rec: "stringLength" "s" :=
@@ -1156,11 +1897,61 @@ Definition failing_testStringLength: val :=
   rec: "failing_testStringLength" <> :=
     let: "ok" := ref_to boolT #true in
     let: "s" := ref_to stringT #(str"") in
-    "ok" <-[boolT] ((![boolT] "ok") && ((StringLength (![stringT] "s")) = #0));;
+    "ok" <-[boolT] ((![boolT] "ok") && (StringLength (![stringT] "s") = #0));;
     "s" <-[stringT] (stringAppend (![stringT] "s") #1);;
-    "ok" <-[boolT] ((![boolT] "ok") && ((StringLength (![stringT] "s")) = #1));;
+    "ok" <-[boolT] ((![boolT] "ok") && (StringLength (![stringT] "s") = #1));;
     "s" <-[stringT] (stringAppend (![stringT] "s") #23);;
-    (![boolT] "ok") && ((StringLength (![stringT] "s")) = #3).
+    (![boolT] "ok") && (StringLength (![stringT] "s") = #3).
+
+Definition repeatString: val :=
+  rec: "repeatString" "s" "n" :=
+    let: "out" := ref_to stringT #(str"") in
+    let: "i" := ref_to uint64T #0 in
+    (for: (λ: <>, (![uint64T] "i") < "n"); (λ: <>, "i" <-[uint64T] ((![uint64T] "i") + #1)) := λ: <>,
+      "out" <-[stringT] ((![stringT] "out") + "s");;
+      Continue);;
+    ![stringT] "out".
+
+Definition testStringConcatLoop: val :=
+  rec: "testStringConcatLoop" <> :=
+    (repeatString #(str"ab") #3 = #(str"ababab")) && (repeatString #(str"ab") #0 = #(str"")).
+
+Definition testStringEquality: val :=
+  rec: "testStringEquality" <> :=
+    let: "ok" := ref_to boolT #true in
+    let: "s" := #(str"abc") in
+    let: "t" := #(str"ab") + #(str"c") in
+    "ok" <-[boolT] ((![boolT] "ok") && ("s" = "t"));;
+    "ok" <-[boolT] ((![boolT] "ok") && ("s" = #(str"abc")));;
+    "ok" <-[boolT] ((![boolT] "ok") && (~ ("s" ≠ "t")));;
+    "ok" <-[boolT] ((![boolT] "ok") && ("s" ≠ #(str"abd")));;
+    "ok" <-[boolT] ((![boolT] "ok") && (#(str"") ≠ "s"));;
+    ![boolT] "ok".
+
+Definition greet: val :=
+  rec: "greet" "name" :=
+    #(str"hello, ") + "name".
+
+Definition testStringConcat: val :=
+  rec: "testStringConcat" <> :=
+    let: "ok" := ref_to boolT #true in
+    let: "s" := greet #(str"world") in
+    "ok" <-[boolT] ((![boolT] "ok") && ("s" = #(str"hello, world")));;
+    let: "t" := "s" + "s" in
+    "ok" <-[boolT] ((![boolT] "ok") && ("t" = #(str"hello, worldhello, world")));;
+    "ok" <-[boolT] ((![boolT] "ok") && (StringLength "t" = #24));;
+    "ok" <-[boolT] ((![boolT] "ok") && ((greet #(str"") + #(str"")) = #(str"hello, ")));;
+    ![boolT] "ok".
+
+Definition testStringIndex: val :=
+  rec: "testStringIndex" <> :=
+    let: "ok" := ref_to boolT #true in
+    let: "s" := #(str"abc") in
+    "ok" <-[boolT] ((![boolT] "ok") && (StringGet "s" #0 = #(U8 97)));;
+    "ok" <-[boolT] ((![boolT] "ok") && (StringGet "s" (StringLength "s" - #1) = #(U8 99)));;
+    let: "i" := ref_to uint64T #1 in
+    "ok" <-[boolT] ((![boolT] "ok") && (StringGet (greet "s") (#7 + (![uint64T] "i")) = #(U8 98)));;
+    ![boolT] "ok".
 
 (* struct_pointers.go *)
 
@@ -1183,19 +1974,33 @@ Definition Bar__mutate: val :=
 
 Definition Foo__mutateBar: val :=
   rec: "Foo__mutateBar" "foo" :=
-    Bar__mutate (struct.loadF Foo "bar" "foo");;
+    Bar__mutate (struct.fieldRef Foo "bar" "foo");;
     #().
 
-Definition failing_testFooBarMutation: val :=
-  rec: "failing_testFooBarMutation" <> :=
-    let: "x" := struct.mk Foo [
+Definition testFooBarMutation: val :=
+  rec: "testFooBarMutation" <> :=
+    let: "x" := ref_to (struct.t Foo) (struct.mk Foo [
       "bar" ::= struct.mk Bar [
         "a" ::= #0;
         "b" ::= #0
       ]
-    ] in
+    ]) in
     Foo__mutateBar "x";;
-    (struct.get Bar "a" (struct.get Foo "bar" "x")) = #2.
+    struct.get Bar "a" (struct.get Foo "bar" (![struct.t Foo] "x")) = #2.
+
+Definition testNewUint64: val :=
+  rec: "testNewUint64" <> :=
+    let: "p" := ref (zero_val uint64T) in
+    let: "ok" := (![uint64T] "p") = #0 in
+    "p" <-[uint64T] #3;;
+    "ok" && ((![uint64T] "p") = #3).
+
+Definition testNewStruct: val :=
+  rec: "testNewStruct" <> :=
+    let: "p" := struct.alloc Bar (zero_val (struct.t Bar)) in
+    let: "ok" := (struct.loadF Bar "a" "p" = #0) && (struct.loadF Bar "b" "p" = #0) in
+    Bar__mutate "p";;
+    ("ok" && (struct.loadF Bar "a" "p" = #2)) && (struct.loadF Bar "b" "p" = #3).
 
 (* structs.go *)
 
@@ -1247,18 +2052,18 @@ Definition failing_testStructUpdates: val :=
   rec: "failing_testStructUpdates" <> :=
     let: "ok" := ref_to boolT #true in
     let: "ns" := NewS #() in
-    "ok" <-[boolT] ((![boolT] "ok") && ((S__readA "ns") = #2));;
+    "ok" <-[boolT] ((![boolT] "ok") && (S__readA "ns" = #2));;
     let: "b1" := ref_to (struct.t TwoInts) (S__readB "ns") in
-    "ok" <-[boolT] ((![boolT] "ok") && ((struct.get TwoInts "x" (![struct.t TwoInts] "b1")) = #1));;
+    "ok" <-[boolT] ((![boolT] "ok") && (struct.get TwoInts "x" (![struct.t TwoInts] "b1") = #1));;
     S__negateC "ns";;
-    "ok" <-[boolT] ((![boolT] "ok") && ((struct.loadF S "c" "ns") = #false));;
+    "ok" <-[boolT] ((![boolT] "ok") && (struct.loadF S "c" "ns" = #false));;
     struct.storeF TwoInts "x" "b1" #3;;
     let: "b2" := ref_to (struct.t TwoInts) (S__readB "ns") in
-    "ok" <-[boolT] ((![boolT] "ok") && ((struct.get TwoInts "x" (![struct.t TwoInts] "b2")) = #1));;
+    "ok" <-[boolT] ((![boolT] "ok") && (struct.get TwoInts "x" (![struct.t TwoInts] "b2") = #1));;
     let: "b3" := ref_to ptrT (struct.fieldRef S "b" "ns") in
-    "ok" <-[boolT] ((![boolT] "ok") && ((struct.loadF TwoInts "x" (![ptrT] "b3")) = #1));;
+    "ok" <-[boolT] ((![boolT] "ok") && (struct.loadF TwoInts "x" (![ptrT] "b3") = #1));;
     S__updateBValX "ns" #4;;
-    "ok" <-[boolT] ((![boolT] "ok") && ((struct.get TwoInts "x" (S__readBVal "ns")) = #4));;
+    "ok" <-[boolT] ((![boolT] "ok") && (struct.get TwoInts "x" (S__readBVal (struct.load S "ns")) = #4));;
     ![boolT] "ok".
 
 Definition testNestedStructUpdates: val :=
@@ -1266,19 +2071,19 @@ Definition testNestedStructUpdates: val :=
     let: "ok" := ref_to boolT #true in
     let: "ns" := ref_to ptrT (NewS #()) in
     struct.storeF TwoInts "x" (struct.fieldRef S "b" (![ptrT] "ns")) #5;;
-    "ok" <-[boolT] ((![boolT] "ok") && ((struct.get TwoInts "x" (struct.loadF S "b" (![ptrT] "ns"))) = #5));;
+    "ok" <-[boolT] ((![boolT] "ok") && (struct.get TwoInts "x" (struct.loadF S "b" (![ptrT] "ns")) = #5));;
     "ns" <-[ptrT] (NewS #());;
     let: "p" := ref_to ptrT (struct.fieldRef S "b" (![ptrT] "ns")) in
     struct.storeF TwoInts "x" (![ptrT] "p") #5;;
-    "ok" <-[boolT] ((![boolT] "ok") && ((struct.get TwoInts "x" (struct.loadF S "b" (![ptrT] "ns"))) = #5));;
+    "ok" <-[boolT] ((![boolT] "ok") && (struct.get TwoInts "x" (struct.loadF S "b" (![ptrT] "ns")) = #5));;
     "ns" <-[ptrT] (NewS #());;
     "p" <-[ptrT] (struct.fieldRef S "b" (![ptrT] "ns"));;
     struct.storeF TwoInts "x" (struct.fieldRef S "b" (![ptrT] "ns")) #5;;
-    "ok" <-[boolT] ((![boolT] "ok") && ((struct.get TwoInts "x" (struct.load TwoInts (![ptrT] "p"))) = #5));;
+    "ok" <-[boolT] ((![boolT] "ok") && (struct.get TwoInts "x" (struct.load TwoInts (![ptrT] "p")) = #5));;
     "ns" <-[ptrT] (NewS #());;
     "p" <-[ptrT] (struct.fieldRef S "b" (![ptrT] "ns"));;
     struct.storeF TwoInts "x" (struct.fieldRef S "b" (![ptrT] "ns")) #5;;
-    "ok" <-[boolT] ((![boolT] "ok") && ((struct.loadF TwoInts "x" (![ptrT] "p")) = #5));;
+    "ok" <-[boolT] ((![boolT] "ok") && (struct.loadF TwoInts "x" (![ptrT] "p") = #5));;
     ![boolT] "ok".
 
 Definition testStructConstructions: val :=
@@ -1290,15 +2095,19 @@ Definition testStructConstructions: val :=
       "y" ::= #0;
       "x" ::= #0
     ] in
-    let: "p4" := struct.mk TwoInts [
+    let: "p4" := ref_to (struct.t TwoInts) (struct.mk TwoInts [
       "x" ::= #0;
       "y" ::= #0
-    ] in
+    ]) in
     "ok" <-[boolT] ((![boolT] "ok") && ((![ptrT] "p1") = #null));;
     "p1" <-[ptrT] (struct.alloc TwoInts (zero_val (struct.t TwoInts)));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((![struct.t TwoInts] "p2") = "p3"));;
-    "ok" <-[boolT] ((![boolT] "ok") && ("p3" = "p4"));;
-    "ok" <-[boolT] ((![boolT] "ok") && ("p4" = (struct.load TwoInts (![ptrT] "p1"))));;
+    "ok" <-[boolT] ((![boolT] "ok") && (let: "$x" := ![struct.t TwoInts] "p2" in
+    (struct.get TwoInts "x" "$x" = struct.get TwoInts "x" "p3") && (struct.get TwoInts "y" "$x" = struct.get TwoInts "y" "p3")));;
+    "ok" <-[boolT] ((![boolT] "ok") && (let: "$y" := ![struct.t TwoInts] "p4" in
+    (struct.get TwoInts "x" "p3" = struct.get TwoInts "x" "$y") && (struct.get TwoInts "y" "p3" = struct.get TwoInts "y" "$y")));;
+    "ok" <-[boolT] ((![boolT] "ok") && (let: "$x" := ![struct.t TwoInts] "p4" in
+    let: "$y" := struct.load TwoInts (![ptrT] "p1") in
+    (struct.get TwoInts "x" "$x" = struct.get TwoInts "x" "$y") && (struct.get TwoInts "y" "$x" = struct.get TwoInts "y" "$y")));;
     "ok" <-[boolT] ((![boolT] "ok") && ("p4" ≠ (![ptrT] "p1")));;
     ![boolT] "ok".
 
@@ -1308,12 +2117,12 @@ Definition testIncompleteStruct: val :=
     let: "p1" := struct.mk TwoInts [
       "x" ::= #0
     ] in
-    "ok" <-[boolT] ((![boolT] "ok") && ((struct.get TwoInts "y" "p1") = #0));;
+    "ok" <-[boolT] ((![boolT] "ok") && (struct.get TwoInts "y" "p1" = #0));;
     let: "p2" := struct.mk S [
       "a" ::= #2
     ] in
-    "ok" <-[boolT] ((![boolT] "ok") && ((struct.get TwoInts "x" (struct.get S "b" "p2")) = #0));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((struct.get S "c" "p2") = #false));;
+    "ok" <-[boolT] ((![boolT] "ok") && (struct.get TwoInts "x" (struct.get S "b" "p2") = #0));;
+    "ok" <-[boolT] ((![boolT] "ok") && (struct.get S "c" "p2" = #false));;
     ![boolT] "ok".
 
 Definition StructWrap := struct.decl [
@@ -1326,13 +2135,13 @@ Definition testStoreInStructVar: val :=
       "i" ::= #0
     ]) in
     struct.storeF StructWrap "i" "p" #5;;
-    (struct.get StructWrap "i" (![struct.t StructWrap] "p")) = #5.
+    struct.get StructWrap "i" (![struct.t StructWrap] "p") = #5.
 
 Definition testStoreInStructPointerVar: val :=
   rec: "testStoreInStructPointerVar" <> :=
     let: "p" := ref_to ptrT (struct.alloc StructWrap (zero_val (struct.t StructWrap))) in
     struct.storeF StructWrap "i" (![ptrT] "p") #5;;
-    (struct.loadF StructWrap "i" (![ptrT] "p")) = #5.
+    struct.loadF StructWrap "i" (![ptrT] "p") = #5.
 
 Definition testStoreComposite: val :=
   rec: "testStoreComposite" <> :=
@@ -1341,14 +2150,14 @@ Definition testStoreComposite: val :=
       "x" ::= #3;
       "y" ::= #4
     ]);;
-    (struct.get TwoInts "y" (struct.load TwoInts "p")) = #4.
+    struct.get TwoInts "y" (struct.load TwoInts "p") = #4.
 
 Definition testStoreSlice: val :=
   rec: "testStoreSlice" <> :=
     let: "p" := ref (zero_val (slice.T uint64T)) in
     let: "s" := NewSlice uint64T #3 in
     "p" <-[slice.T uint64T] "s";;
-    (slice.len (![slice.T uint64T] "p")) = #3.
+    slice.len (![slice.T uint64T] "p") = #3.
 
 Definition StructWithFunc := struct.decl [
   "fn" :: (uint64T -> uint64T)%ht
@@ -1357,10 +2166,224 @@ Definition StructWithFunc := struct.decl [
 Definition testStructFieldFunc: val :=
   rec: "testStructFieldFunc" <> :=
     let: "a" := struct.alloc StructWithFunc (zero_val (struct.t StructWithFunc)) in
-    struct.storeF StructWithFunc "fn" "a" (λ: "arg",
-      "arg" * #2
-      );;
-    ((struct.loadF StructWithFunc "fn" "a") #10) = #20.
+    struct.storeF StructWithFunc "fn" "a" (λ: "arg", "arg" * #2);;
+    (struct.loadF StructWithFunc "fn" "a") #10 = #20.
+
+Definition zeroInner := struct.decl [
+  "x" :: uint64T
+].
+
+Definition mixedFields := struct.decl [
+  "n" :: uint64T;
+  "s" :: slice.T uint64T;
+  "m" :: mapT uint64T;
+  "p" :: ptrT;
+  "inner" :: struct.t zeroInner
+].
+
+Definition testZeroStructFields: val :=
+  rec: "testZeroStructFields" <> :=
+    let: "z" := ref (zero_val (struct.t mixedFields)) in
+    ((((struct.get mixedFields "n" (![struct.t mixedFields] "z") = #0) && (slice.len (struct.get mixedFields "s" (![struct.t mixedFields] "z")) = #0)) && (MapLen (struct.get mixedFields "m" (![struct.t mixedFields] "z")) = #0)) && (struct.get mixedFields "p" (![struct.t mixedFields] "z") = #null)) && (struct.get zeroInner "x" (struct.get mixedFields "inner" (![struct.t mixedFields] "z")) = #0).
+
+Definition testPartialStructLiteral: val :=
+  rec: "testPartialStructLiteral" <> :=
+    let: "s" := struct.mk S [
+      "a" ::= #3
+    ] in
+    (((struct.get S "a" "s" = #3) && (struct.get TwoInts "x" (struct.get S "b" "s") = #0)) && (struct.get TwoInts "y" (struct.get S "b" "s") = #0)) && (~ (struct.get S "c" "s")).
+
+(* nextB counts its calls in s.a, to check that a field access on its result
+   runs it once *)
+Definition S__nextB: val :=
+  rec: "S__nextB" "s" :=
+    struct.storeF S "a" "s" (struct.loadF S "a" "s" + #1);;
+    struct.loadF S "b" "s".
+
+Definition testFieldOfCallResult: val :=
+  rec: "testFieldOfCallResult" <> :=
+    let: "s" := NewS #() in
+    let: "x" := struct.get TwoInts "x" (S__nextB "s") in
+    let: "y" := struct.get TwoInts "y" (S__nextB "s") in
+    let: "ok" := (("x" = #1) && ("y" = #2)) && (struct.loadF S "a" "s" = #4) in
+    "ok" && (struct.get TwoInts "y" (struct.loadF S "b" (NewS #())) = #2).
+
+Definition testFieldPointer: val :=
+  rec: "testFieldPointer" <> :=
+    let: "s" := ref (zero_val (struct.t TwoInts)) in
+    let: "p" := struct.fieldRef TwoInts "x" "s" in
+    "p" <-[uint64T] #3;;
+    let: "sp" := NewS #() in
+    let: "q" := struct.fieldRef TwoInts "y" (struct.fieldRef S "b" "sp") in
+    "q" <-[uint64T] #7;;
+    ((struct.get TwoInts "x" (![struct.t TwoInts] "s") = #3) && (struct.get TwoInts "y" (![struct.t TwoInts] "s") = #0)) && (struct.get TwoInts "y" (struct.loadF S "b" "sp") = #7).
+
+Definition Outer := struct.decl [
+  "val" :: struct.t TwoInts;
+  "ptr" :: ptrT
+].
+
+Definition testNestedFieldChains: val :=
+  rec: "testNestedFieldChains" <> :=
+    let: "o" := struct.new Outer [
+      "val" ::= struct.mk TwoInts [
+        "x" ::= #1;
+        "y" ::= #2
+      ];
+      "ptr" ::= struct.new TwoInts [
+        "x" ::= #3;
+        "y" ::= #4
+      ]
+    ] in
+    let: "v" := struct.mk Outer [
+      "val" ::= struct.mk TwoInts [
+        "x" ::= #5;
+        "y" ::= #6
+      ];
+      "ptr" ::= struct.loadF Outer "ptr" "o"
+    ] in
+    struct.storeF TwoInts "y" (struct.loadF Outer "ptr" "o") #7;;
+    let: "ok" := ref_to boolT ((struct.get TwoInts "x" (struct.loadF Outer "val" "o") = #1) && (struct.loadF TwoInts "x" (struct.loadF Outer "ptr" "o") = #3)) in
+    "ok" <-[boolT] (((![boolT] "ok") && (struct.get TwoInts "y" (struct.get Outer "val" "v") = #6)) && (struct.loadF TwoInts "y" (struct.get Outer "ptr" "v") = #7));;
+    ![boolT] "ok".
+
+Definition testReceiverRules: val :=
+  rec: "testReceiverRules" <> :=
+    let: "s" := ref (zero_val (struct.t S)) in
+    S__updateBValX "s" #5;;
+    let: "p" := "s" in
+    (struct.get TwoInts "x" (struct.get S "b" (![struct.t S] "s")) = #5) && (struct.get TwoInts "x" (S__readBVal (struct.load S "p")) = #5).
+
+Definition counterBase := struct.decl [
+  "count" :: uint64T
+].
+
+Definition counterBase__incr: val :=
+  rec: "counterBase__incr" "c" :=
+    struct.storeF counterBase "count" "c" (struct.loadF counterBase "count" "c" + #1);;
+    #().
+
+Definition counterBase__get: val :=
+  rec: "counterBase__get" "c" :=
+    struct.get counterBase "count" "c".
+
+Definition namedCounter := struct.decl [
+  "counterBase" :: struct.t counterBase;
+  "name" :: stringT
+].
+
+Definition testPromotedMethods: val :=
+  rec: "testPromotedMethods" <> :=
+    let: "c" := ref (zero_val (struct.t namedCounter)) in
+    counterBase__incr (struct.fieldRef namedCounter "counterBase" "c");;
+    counterBase__incr (struct.fieldRef namedCounter "counterBase" "c");;
+    let: "p" := struct.new namedCounter [
+      "name" ::= #(str"p")
+    ] in
+    counterBase__incr (struct.fieldRef namedCounter "counterBase" "p");;
+    (counterBase__get (struct.get namedCounter "counterBase" (![struct.t namedCounter] "c")) = #2) && (counterBase__get (struct.loadF namedCounter "counterBase" "p") = #1).
+
+Definition testStructEquality: val :=
+  rec: "testStructEquality" <> :=
+    let: "a" := struct.mk Outer [
+      "val" ::= struct.mk TwoInts [
+        "x" ::= #1;
+        "y" ::= #2
+      ]
+    ] in
+    let: "b" := ref_to (struct.t Outer) (struct.mk Outer [
+      "val" ::= struct.mk TwoInts [
+        "x" ::= #1;
+        "y" ::= #2
+      ]
+    ]) in
+    let: "ok" := ref_to boolT (let: "$y" := ![struct.t Outer] "b" in
+    ((struct.get TwoInts "x" (struct.get Outer "val" "a") = struct.get TwoInts "x" (struct.get Outer "val" "$y")) && (struct.get TwoInts "y" (struct.get Outer "val" "a") = struct.get TwoInts "y" (struct.get Outer "val" "$y"))) && (struct.get Outer "ptr" "a" = struct.get Outer "ptr" "$y")) in
+    struct.storeF TwoInts "y" (struct.fieldRef Outer "val" "b") #3;;
+    "ok" <-[boolT] ((![boolT] "ok") && (~ (let: "$y" := ![struct.t Outer] "b" in
+    ((struct.get TwoInts "x" (struct.get Outer "val" "a") = struct.get TwoInts "x" (struct.get Outer "val" "$y")) && (struct.get TwoInts "y" (struct.get Outer "val" "a") = struct.get TwoInts "y" (struct.get Outer "val" "$y"))) && (struct.get Outer "ptr" "a" = struct.get Outer "ptr" "$y"))));;
+    struct.storeF TwoInts "y" (struct.fieldRef Outer "val" "b") #2;;
+    struct.storeF Outer "ptr" "b" (struct.new TwoInts [
+      "x" ::= #1;
+      "y" ::= #2
+    ]);;
+    (![boolT] "ok") && (~ (let: "$y" := ![struct.t Outer] "b" in
+    ((struct.get TwoInts "x" (struct.get Outer "val" "a") = struct.get TwoInts "x" (struct.get Outer "val" "$y")) && (struct.get TwoInts "y" (struct.get Outer "val" "a") = struct.get TwoInts "y" (struct.get Outer "val" "$y"))) && (struct.get Outer "ptr" "a" = struct.get Outer "ptr" "$y"))).
+
+Definition S__nextPair: val :=
+  rec: "S__nextPair" "s" :=
+    struct.storeF S "a" "s" (struct.loadF S "a" "s" + #1);;
+    struct.mk TwoInts [
+      "x" ::= struct.loadF S "a" "s";
+      "y" ::= #0
+    ].
+
+Definition testStructEqualityEvaluatesOnce: val :=
+  rec: "testStructEqualityEvaluatesOnce" <> :=
+    let: "s" := NewS #() in
+    let: "ok" := ref_to boolT (let: "$x" := S__nextPair "s" in
+    let: "$y" := struct.mk TwoInts [
+      "x" ::= #3;
+      "y" ::= #0
+    ] in
+    (struct.get TwoInts "x" "$x" = struct.get TwoInts "x" "$y") && (struct.get TwoInts "y" "$x" = struct.get TwoInts "y" "$y")) in
+    "ok" <-[boolT] ((![boolT] "ok") && (struct.loadF S "a" "s" = #3));;
+    let: "p" := struct.new TwoInts [
+      "x" ::= #1;
+      "y" ::= #2
+    ] in
+    "ok" <-[boolT] ((![boolT] "ok") && (let: "$x" := struct.load TwoInts "p" in
+    let: "$y" := struct.mk TwoInts [
+      "x" ::= #1;
+      "y" ::= #2
+    ] in
+    (struct.get TwoInts "x" "$x" = struct.get TwoInts "x" "$y") && (struct.get TwoInts "y" "$x" = struct.get TwoInts "y" "$y")));;
+    let: "$tag" := struct.load TwoInts "p" in
+    (if: (let: "$y" := struct.mk TwoInts [
+      "x" ::= #2;
+      "y" ::= #1
+    ] in
+    (struct.get TwoInts "x" "$tag" = struct.get TwoInts "x" "$y") && (struct.get TwoInts "y" "$tag" = struct.get TwoInts "y" "$y"))
+    then "ok" <-[boolT] #false
+    else (if: (let: "$y" := struct.mk TwoInts [
+      "x" ::= #1;
+      "y" ::= #2
+    ] in
+    (struct.get TwoInts "x" "$tag" = struct.get TwoInts "x" "$y") && (struct.get TwoInts "y" "$tag" = struct.get TwoInts "y" "$y"))
+    then "ok" <-[boolT] ((![boolT] "ok") && #true)
+    else "ok" <-[boolT] #false));;
+    ![boolT] "ok".
+
+(* switch.go *)
+
+Definition classify: val :=
+  rec: "classify" "x" :=
+    let: "y" := "x" `rem` #4 in
+    let: "$tag" := "y" in
+    (if: "$tag" = #0
+    then #0
+    else (if: ("$tag" = #1) || ("$tag" = #3)
+    then #1
+    else #2)).
+
+Definition testSwitchWithInit: val :=
+  rec: "testSwitchWithInit" <> :=
+    (((classify #4 = #0) && (classify #5 = #1)) && (classify #7 = #1)) && (classify #6 = #2).
+
+Definition testSwitchInLoop: val :=
+  rec: "testSwitchInLoop" <> :=
+    let: "evens" := ref_to uint64T #0 in
+    let: "odds" := ref_to uint64T #0 in
+    let: "i" := ref_to uint64T #0 in
+    (for: (λ: <>, (![uint64T] "i") < #5); (λ: <>, "i" <-[uint64T] ((![uint64T] "i") + #1)) := λ: <>,
+      (if: ((![uint64T] "i") `rem` #2) = #0
+      then
+        "evens" <-[uint64T] ((![uint64T] "evens") + #1);;
+        Continue
+      else
+        "odds" <-[uint64T] ((![uint64T] "odds") + #1);;
+        Continue));;
+    ((![uint64T] "evens") = #3) && ((![uint64T] "odds") = #2).
 
 (* vars.go *)
 
@@ -1382,12 +2405,53 @@ Definition testAnonymousAssign: val :=
     #1 + #2;;
     #true.
 
+Definition ifInitValue: val :=
+  rec: "ifInitValue" "x" :=
+    let: "y" := "x" * #2 in
+    (if: "y" > #10
+    then "y"
+    else "x").
+
+Definition testIfWithInit: val :=
+  rec: "testIfWithInit" <> :=
+    (ifInitValue #3 = #3) && (ifInitValue #6 = #12).
+
+Definition countCalls: val :=
+  rec: "countCalls" "n" :=
+    "n" <-[uint64T] ((![uint64T] "n") + #1);;
+    ![uint64T] "n".
+
+Definition testDiscardCall: val :=
+  rec: "testDiscardCall" <> :=
+    let: "n" := ref (zero_val uint64T) in
+    countCalls "n";;
+    Skip;;
+    (![uint64T] "n") = #1.
+
+(* globalCounter is modified, so it is translated as a location *)
+Definition globalCounter__init: val :=
+  rec: "globalCounter__init" <> :=
+    globals.put #(str"globalCounter") (ref_to uint64T (zero_val uint64T)).
+
+Definition bumpGlobalCounter: val :=
+  rec: "bumpGlobalCounter" <> :=
+    (globals.get #(str"globalCounter")) <-[uint64T] ((![uint64T] (globals.get #(str"globalCounter"))) + #1);;
+    ![uint64T] (globals.get #(str"globalCounter")).
+
+Definition testGlobalVariableMutation: val :=
+  rec: "testGlobalVariableMutation" <> :=
+    let: "start" := ![uint64T] (globals.get #(str"globalCounter")) in
+    bumpGlobalCounter #();;
+    (bumpGlobalCounter #() = ("start" + #2)) && ((![uint64T] (globals.get #(str"globalCounter"))) = ("start" + #2)).
+
 (* wal.go *)
 
-(* 10 is completely arbitrary *)
+(* MaxTxnWrites is a guaranteed reservation for each transaction.
+
+   10 is completely arbitrary *)
 Definition MaxTxnWrites : expr := #10.
 
-Definition logLength : expr := #1 + (#2 * MaxTxnWrites).
+Definition logLength : expr := #21.
 
 Definition Log := struct.decl [
   "d" :: disk.Disk;
@@ -1569,10 +2633,15 @@ Definition disabled_testWal: val :=
     (if: Log__BeginTxn "lg"
     then Log__Write "lg" #2 (intToBlock #11)
     else #());;
-    "ok" <-[boolT] ((![boolT] "ok") && ((blockToInt (Log__Read "lg" #2)) = #11));;
-    "ok" <-[boolT] ((![boolT] "ok") && ((blockToInt (disk.Read #0)) = #0));;
+    "ok" <-[boolT] ((![boolT] "ok") && (blockToInt (Log__Read "lg" #2) = #11));;
+    "ok" <-[boolT] ((![boolT] "ok") && (blockToInt (disk.Read #0) = #0));;
     Log__Commit "lg";;
-    "ok" <-[boolT] ((![boolT] "ok") && ((blockToInt (disk.Read #0)) = #1));;
+    "ok" <-[boolT] ((![boolT] "ok") && (blockToInt (disk.Read #0) = #1));;
     Log__Apply "lg";;
     "ok" <-[boolT] ((![boolT] "ok") && ((![uint64T] (struct.get Log "length" "lg")) = #0));;
     ![boolT] "ok".
+
+Definition globals__init: val :=
+  rec: "globals__init" <> :=
+    globalCounter__init #();;
+    #().
@@ -76,3 +76,68 @@ func testOverwriteArray() bool {
 	}
 	return arr[3] == 4 && arr[0] == 4
 }
+
+func doubleSlice(s []uint64) {
+	for i := range s {
+		s[i] = 2 * s[i]
+	}
+}
+
+func testDoubleSliceInPlace() bool {
+	s := make([]uint64, 3)
+	s[0] = 1
+	s[1] = 2
+	s[2] = 3
+	doubleSlice(s)
+	return s[0] == 2 && s[1] == 4 && s[2] == 6
+}
+
+func twoElements() []uint64 {
+	s := make([]uint64, 2)
+	s[0] = 5
+	s[1] = 6
+	return s
+}
+
+func testAppendCallResult() bool {
+	var s = make([]uint64, 1)
+	s = append(s, twoElements()...)
+	return uint64(len(s)) == 3 && s[0] == 0 && s[2] == 6
+}
+
+func testSliceLiteral() bool {
+	var ok = true
+
+	empty := []uint64{}
+	ok = ok && len(empty) == 0
+
+	s := []uint64{1, 2, 3}
+	ok = ok && len(s) == 3
+	ok = ok && s[0] == 1 && s[1] == 2 && s[2] == 3
+	return ok
+}
+
+func testSparseSliceLiteral() bool {
+	s := []uint64{3: 7, 1: 2}
+	return len(s) == 4 && s[0] == 0 && s[1] == 2 && s[2] == 0 && s[3] == 7
+}
+
+type uint64s []uint64
+
+func testSliceElementPointer() bool {
+	s := make(uint64s, 3)
+	p := &s[1]
+	*p = 5
+	t := s[1:]
+	q := &t[1]
+	*q = 7
+	return s[1] == 5 && t[0] == 5 && s[2] == 7 && *p == 5
+}
+
+func testArraySliceSharesStorage() bool {
+	var a = [3]uint64{1, 2, 3}
+	s := a[1:]
+	s[0] = 5
+	t := a[:]
+	return t[1] == 5
+}
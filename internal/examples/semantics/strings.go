@@ -33,3 +33,52 @@ func failing_testStringLength() bool {
 
 	return ok && (uint64(len(s)) == 3)
 }
+
+func repeatString(s string, n uint64) string {
+	var out = ""
+	for i := uint64(0); i < n; i++ {
+		out = out + s
+	}
+	return out
+}
+
+func testStringConcatLoop() bool {
+	return repeatString("ab", 3) == "ababab" && repeatString("ab", 0) == ""
+}
+
+func testStringEquality() bool {
+	var ok = true
+	s := "abc"
+	t := "ab" + "c"
+	ok = ok && s == t
+	ok = ok && s == "abc"
+	ok = ok && !(s != t)
+	ok = ok && s != "abd"
+	ok = ok && "" != s
+	return ok
+}
+
+func greet(name string) string {
+	return "hello, " + name
+}
+
+func testStringConcat() bool {
+	var ok = true
+	s := greet("world")
+	ok = ok && s == "hello, world"
+	t := s + s
+	ok = ok && t == "hello, worldhello, world"
+	ok = ok && uint64(len(t)) == 24
+	ok = ok && greet("")+"" == "hello, "
+	return ok
+}
+
+func testStringIndex() bool {
+	var ok = true
+	s := "abc"
+	ok = ok && s[0] == 'a'
+	ok = ok && s[uint64(len(s))-1] == 'c'
+	var i = uint64(1)
+	ok = ok && greet(s)[7+i] == byte(98)
+	return ok
+}
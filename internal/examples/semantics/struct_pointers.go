@@ -20,8 +20,22 @@ func (foo *Foo) mutateBar() {
 	foo.bar.mutate()
 }
 
-func failing_testFooBarMutation() bool {
-	x := Foo{bar: Bar{a: 0, b: 0}}
+func testFooBarMutation() bool {
+	var x = Foo{bar: Bar{a: 0, b: 0}}
 	x.mutateBar()
 	return x.bar.a == 2
 }
+
+func testNewUint64() bool {
+	p := new(uint64)
+	ok := *p == 0
+	*p = 3
+	return ok && *p == 3
+}
+
+func testNewStruct() bool {
+	p := new(Bar)
+	ok := p.a == 0 && p.b == 0
+	p.mutate()
+	return ok && p.a == 2 && p.b == 3
+}
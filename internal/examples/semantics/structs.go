@@ -89,10 +89,10 @@ func testNestedStructUpdates() bool {
 func testStructConstructions() bool {
 	var ok = true
 
-	var p1 *TwoInts           // p1 == nil
-	var p2 TwoInts            // p2 == TwoInts{0, 0}
-	p3 := TwoInts{y: 0, x: 0} // p3 == TwoInts{0, 0}
-	p4 := TwoInts{x: 0, y: 0} // p4 == TwoInts{0, 0}
+	var p1 *TwoInts              // p1 == nil
+	var p2 TwoInts               // p2 == TwoInts{0, 0}
+	p3 := TwoInts{y: 0, x: 0}    // p3 == TwoInts{0, 0}
+	var p4 = TwoInts{x: 0, y: 0} // p4 == TwoInts{0, 0}
 
 	ok = ok && (p1 == nil)
 	p1 = new(TwoInts) // p1 == &TwoInts{0, 0}
@@ -158,3 +158,133 @@ func testStructFieldFunc() bool {
 	}
 	return (a.fn(10) == 20)
 }
+
+type zeroInner struct {
+	x uint64
+}
+
+type mixedFields struct {
+	n     uint64
+	s     []uint64
+	m     map[uint64]uint64
+	p     *uint64
+	inner zeroInner
+}
+
+func testZeroStructFields() bool {
+	var z mixedFields
+	return z.n == 0 && uint64(len(z.s)) == 0 && uint64(len(z.m)) == 0 &&
+		z.p == nil && z.inner.x == 0
+}
+
+func testPartialStructLiteral() bool {
+	s := S{a: 3}
+	return s.a == 3 && s.b.x == 0 && s.b.y == 0 && !s.c
+}
+
+// nextB counts its calls in s.a, to check that a field access on its result
+// runs it once
+func (s *S) nextB() TwoInts {
+	s.a += 1
+	return s.b
+}
+
+func testFieldOfCallResult() bool {
+	s := NewS()
+	x := s.nextB().x
+	y := s.nextB().y
+	ok := x == 1 && y == 2 && s.a == 4
+	return ok && NewS().b.y == 2
+}
+
+func testFieldPointer() bool {
+	var s TwoInts
+	p := &s.x
+	*p = 3
+	sp := NewS()
+	q := &sp.b.y
+	*q = 7
+	return s.x == 3 && s.y == 0 && sp.b.y == 7
+}
+
+type Outer struct {
+	val TwoInts
+	ptr *TwoInts
+}
+
+func testNestedFieldChains() bool {
+	o := &Outer{val: TwoInts{x: 1, y: 2}, ptr: &TwoInts{x: 3, y: 4}}
+	v := Outer{val: TwoInts{x: 5, y: 6}, ptr: o.ptr}
+	o.ptr.y = 7
+	var ok = o.val.x == 1 && o.ptr.x == 3
+	ok = ok && v.val.y == 6 && v.ptr.y == 7
+	return ok
+}
+
+func testReceiverRules() bool {
+	var s S
+	// pointer receiver on an addressable value
+	s.updateBValX(5)
+	p := &s
+	// value receiver through a pointer
+	return s.b.x == 5 && p.readBVal().x == 5
+}
+
+type counterBase struct {
+	count uint64
+}
+
+func (c *counterBase) incr() {
+	c.count += 1
+}
+
+func (c counterBase) get() uint64 {
+	return c.count
+}
+
+type namedCounter struct {
+	counterBase
+	name string
+}
+
+func testPromotedMethods() bool {
+	var c namedCounter
+	c.incr()
+	c.incr()
+	p := &namedCounter{name: "p"}
+	p.incr()
+	return c.get() == 2 && p.get() == 1
+}
+
+func testStructEquality() bool {
+	a := Outer{val: TwoInts{x: 1, y: 2}}
+	var b = Outer{val: TwoInts{x: 1, y: 2}}
+	var ok = a == b
+	b.val.y = 3
+	ok = ok && a != b
+	b.val.y = 2
+	b.ptr = &TwoInts{x: 1, y: 2}
+	return ok && a != b
+}
+
+func (s *S) nextPair() TwoInts {
+	s.a += 1
+	return TwoInts{x: s.a, y: 0}
+}
+
+func testStructEqualityEvaluatesOnce() bool {
+	s := NewS()
+	var ok = s.nextPair() == TwoInts{x: 3, y: 0}
+	ok = ok && s.a == 3
+	p := &TwoInts{x: 1, y: 2}
+	ok = ok && *p == TwoInts{x: 1, y: 2}
+	switch *p {
+	case TwoInts{x: 2, y: 1}:
+		ok = false
+	case TwoInts{x: 1, y: 2}:
+		ok = ok && true
+	default:
+		ok = false
+	}
+	return ok
+}
@@ -0,0 +1,29 @@
+package semantics
+
+func classify(x uint64) uint64 {
+	switch y := x % 4; y {
+	case 0:
+		return 0
+	case 1, 3:
+		return 1
+	}
+	return 2
+}
+
+func testSwitchWithInit() bool {
+	return classify(4) == 0 && classify(5) == 1 && classify(7) == 1 && classify(6) == 2
+}
+
+func testSwitchInLoop() bool {
+	var evens = uint64(0)
+	var odds = uint64(0)
+	for i := uint64(0); i < 5; i++ {
+		switch {
+		case i%2 == 0:
+			evens += 1
+		default:
+			odds += 1
+		}
+	}
+	return evens == 3 && odds == 2
+}
@@ -17,3 +17,40 @@ func testAnonymousAssign() bool {
 	_ = uint64(1) + uint64(2)
 	return true
 }
+
+func ifInitValue(x uint64) uint64 {
+	if y := x * 2; y > 10 {
+		return y
+	}
+	return x
+}
+
+func testIfWithInit() bool {
+	return ifInitValue(3) == 3 && ifInitValue(6) == 12
+}
+
+func countCalls(n *uint64) uint64 {
+	*n += 1
+	return *n
+}
+
+func testDiscardCall() bool {
+	var n uint64
+	_ = countCalls(&n)
+	_ = n
+	return n == 1
+}
+
+// globalCounter is modified, so it is translated as a location
+var globalCounter uint64
+
+func bumpGlobalCounter() uint64 {
+	globalCounter += 1
+	return globalCounter
+}
+
+func testGlobalVariableMutation() bool {
+	start := globalCounter
+	bumpGlobalCounter()
+	return bumpGlobalCounter() == start+2 && globalCounter == start+2
+}
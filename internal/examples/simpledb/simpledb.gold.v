@@ -51,7 +51,7 @@ Definition Entry := struct.decl [
    then decoding failed, and the value of type T should be ignored. *)
 Definition DecodeUInt64: val :=
   rec: "DecodeUInt64" "p" :=
-    (if: (slice.len "p") < #8
+    (if: slice.len "p" < #8
     then (#0, #0)
     else
       let: "n" := UInt64Get "p" in
@@ -70,24 +70,23 @@ Definition DecodeEntry: val :=
     else
       let: ("valueLen", "l2") := DecodeUInt64 (SliceSkip byteT "data" "l1") in
       (if: "l2" = #0
+      then
+        (struct.mk Entry [
+           "Key" ::= #0;
+           "Value" ::= slice.nil
+         ], #0)
+      else (if: slice.len "data" < (("l1" + "l2") + "valueLen")
       then
         (struct.mk Entry [
            "Key" ::= #0;
            "Value" ::= slice.nil
          ], #0)
       else
-        (if: (slice.len "data") < (("l1" + "l2") + "valueLen")
-        then
-          (struct.mk Entry [
-             "Key" ::= #0;
-             "Value" ::= slice.nil
-           ], #0)
-        else
-          let: "value" := SliceSubslice byteT "data" ("l1" + "l2") (("l1" + "l2") + "valueLen") in
-          (struct.mk Entry [
-             "Key" ::= "key";
-             "Value" ::= "value"
-           ], ("l1" + "l2") + "valueLen")))).
+        let: "value" := SliceSubslice byteT "data" ("l1" + "l2") (("l1" + "l2") + "valueLen") in
+        (struct.mk Entry [
+           "Key" ::= "key";
+           "Value" ::= "value"
+         ], ("l1" + "l2") + "valueLen")))).
 
 Definition lazyFileBuf := struct.decl [
   "offset" :: uint64T;
@@ -105,15 +104,15 @@ Definition readTableIndex: val :=
       let: ("e", "l") := DecodeEntry (struct.get lazyFileBuf "next" (![struct.t lazyFileBuf] "buf")) in
       (if: "l" > #0
       then
-        MapInsert "index" (struct.get Entry "Key" "e") (#8 + (struct.get lazyFileBuf "offset" (![struct.t lazyFileBuf] "buf")));;
+        MapInsert "index" (struct.get Entry "Key" "e") (#8 + struct.get lazyFileBuf "offset" (![struct.t lazyFileBuf] "buf"));;
         "buf" <-[struct.t lazyFileBuf] (struct.mk lazyFileBuf [
-          "offset" ::= (struct.get lazyFileBuf "offset" (![struct.t lazyFileBuf] "buf")) + "l";
+          "offset" ::= struct.get lazyFileBuf "offset" (![struct.t lazyFileBuf] "buf") + "l";
           "next" ::= SliceSkip byteT (struct.get lazyFileBuf "next" (![struct.t lazyFileBuf] "buf")) "l"
         ]);;
         Continue
       else
-        let: "p" := FS.readAt "f" ((struct.get lazyFileBuf "offset" (![struct.t lazyFileBuf] "buf")) + (slice.len (struct.get lazyFileBuf "next" (![struct.t lazyFileBuf] "buf")))) #4096 in
-        (if: (slice.len "p") = #0
+        let: "p" := FS.readAt "f" (struct.get lazyFileBuf "offset" (![struct.t lazyFileBuf] "buf") + slice.len (struct.get lazyFileBuf "next" (![struct.t lazyFileBuf] "buf"))) #4096 in
+        (if: slice.len "p" = #0
         then Break
         else
           let: "newBuf" := SliceAppendSlice byteT (struct.get lazyFileBuf "next" (![struct.t lazyFileBuf] "buf")) "p" in
@@ -157,7 +156,7 @@ Definition readValue: val :=
 Definition tableRead: val :=
   rec: "tableRead" "t" "k" :=
     let: ("off", "ok") := MapGet (struct.get Table "Index" "t") "k" in
-    (if: (~ "ok")
+    (if: ~ "ok"
     then (slice.nil, #false)
     else
       let: "p" := readValue (struct.get Table "File" "t") "off" in
@@ -179,7 +178,7 @@ Definition newBuf: val :=
 Definition bufFlush: val :=
   rec: "bufFlush" "f" :=
     let: "buf" := ![slice.T byteT] (struct.get bufFile "buf" "f") in
-    (if: (slice.len "buf") = #0
+    (if: slice.len "buf" = #0
     then #()
     else
       FS.append (struct.get bufFile "file" "f") "buf";;
@@ -223,7 +222,7 @@ Definition tableWriterAppend: val :=
   rec: "tableWriterAppend" "w" "p" :=
     bufAppend (struct.get tableWriter "file" "w") "p";;
     let: "off" := ![uint64T] (struct.get tableWriter "offset" "w") in
-    (struct.get tableWriter "offset" "w") <-[uint64T] ("off" + (slice.len "p"));;
+    (struct.get tableWriter "offset" "w") <-[uint64T] ("off" + slice.len "p");;
     #().
 
 Definition tableWriterClose: val :=
@@ -256,7 +255,7 @@ Definition tablePut: val :=
     let: "tmp2" := EncodeUInt64 "k" "tmp" in
     let: "tmp3" := EncodeSlice "v" "tmp2" in
     let: "off" := ![uint64T] (struct.get tableWriter "offset" "w") in
-    MapInsert (struct.get tableWriter "index" "w") "k" ("off" + (slice.len "tmp2"));;
+    MapInsert (struct.get tableWriter "index" "w") "k" ("off" + slice.len "tmp2");;
     tableWriterAppend "w" "tmp3";;
     #().
 
@@ -350,10 +349,9 @@ Definition freshTable: val :=
   rec: "freshTable" "p" :=
     (if: "p" = #(str"table.0")
     then #(str"table.1")
-    else
-      (if: "p" = #(str"table.1")
-      then #(str"table.0")
-      else "p")).
+    else (if: "p" = #(str"table.1")
+    then #(str"table.0")
+    else "p")).
 
 Definition tablePutBuffer: val :=
   rec: "tablePutBuffer" "w" "buf" :=
@@ -374,17 +372,17 @@ Definition tablePutOldTable: val :=
       (if: "l" > #0
       then
         let: (<>, "ok") := MapGet "b" (struct.get Entry "Key" "e") in
-        (if: (~ "ok")
+        (if: ~ "ok"
         then tablePut "w" (struct.get Entry "Key" "e") (struct.get Entry "Value" "e")
         else #());;
         "buf" <-[struct.t lazyFileBuf] (struct.mk lazyFileBuf [
-          "offset" ::= (struct.get lazyFileBuf "offset" (![struct.t lazyFileBuf] "buf")) + "l";
+          "offset" ::= struct.get lazyFileBuf "offset" (![struct.t lazyFileBuf] "buf") + "l";
           "next" ::= SliceSkip byteT (struct.get lazyFileBuf "next" (![struct.t lazyFileBuf] "buf")) "l"
         ]);;
         Continue
       else
-        let: "p" := FS.readAt (struct.get Table "File" "t") ((struct.get lazyFileBuf "offset" (![struct.t lazyFileBuf] "buf")) + (slice.len (struct.get lazyFileBuf "next" (![struct.t lazyFileBuf] "buf")))) #4096 in
-        (if: (slice.len "p") = #0
+        let: "p" := FS.readAt (struct.get Table "File" "t") (struct.get lazyFileBuf "offset" (![struct.t lazyFileBuf] "buf") + slice.len (struct.get lazyFileBuf "next" (![struct.t lazyFileBuf] "buf"))) #4096 in
+        (if: slice.len "p" = #0
         then Break
         else
           let: "newBuf" := SliceAppendSlice byteT (struct.get lazyFileBuf "next" (![struct.t lazyFileBuf] "buf")) "p" in
@@ -452,12 +450,11 @@ Definition deleteOtherFile: val :=
   rec: "deleteOtherFile" "name" "tableName" :=
     (if: "name" = "tableName"
     then #()
+    else (if: "name" = #(str"manifest")
+    then #()
     else
-      (if: "name" = #(str"manifest")
-      then #()
-      else
-        FS.delete #(str"db") "name";;
-        #())).
+      FS.delete #(str"db") "name";;
+      #())).
 
 Definition deleteOtherFiles: val :=
   rec: "deleteOtherFiles" "tableName" :=
@@ -0,0 +1,31 @@
+// typecheck is translated with type-checking theorems enabled
+package typecheck
+
+const Size uint64 = 4096
+
+const Enabled = true
+
+const Name = "typecheck"
+
+func double(x uint64) uint64 {
+	return 2 * x
+}
+
+// untyped is excluded from type checking.
+//
+//goose:no-typecheck
+func untyped(x uint64) uint64 {
+	return x + Size
+}
+
+type Color uint64
+
+const (
+	Red   Color = 0
+	Green Color = 1
+	Blue  Color = 2
+)
+
+func isRed(c Color) bool {
+	return c == Red
+}
@@ -0,0 +1,55 @@
+(* autogenerated from github.com/tchajed/goose/internal/examples/typecheck *)
+From Perennial.goose_lang Require Import prelude.
+
+Section code.
+Context `{ext_ty: ext_types}.
+Local Coercion Var' s: expr := Var s.
+
+(* typecheck is translated with type-checking theorems enabled *)
+
+Definition Size : expr := #4096.
+Theorem Size_t Γ : Γ ⊢ Size : uint64T.
+Proof. typecheck. Qed.
+
+Definition Enabled : expr := #true.
+Theorem Enabled_t Γ : Γ ⊢ Enabled : boolT.
+Proof. typecheck. Qed.
+
+Definition Name : expr := #(str"typecheck").
+Theorem Name_t Γ : Γ ⊢ Name : stringT.
+Proof. typecheck. Qed.
+
+Definition double: val :=
+  rec: "double" "x" :=
+    #2 * "x".
+Theorem double_t: ⊢ double : (uint64T -> uint64T).
+Proof. typecheck. Qed.
+Hint Resolve double_t : types.
+
+(* untyped is excluded from type checking. *)
+Definition untyped: val :=
+  rec: "untyped" "x" :=
+    "x" + Size.
+
+Definition Color: ty := uint64T.
+
+Definition Red : expr := #0.
+Theorem Red_t Γ : Γ ⊢ Red : Color.
+Proof. typecheck. Qed.
+
+Definition Green : expr := #1.
+Theorem Green_t Γ : Γ ⊢ Green : Color.
+Proof. typecheck. Qed.
+
+Definition Blue : expr := #2.
+Theorem Blue_t Γ : Γ ⊢ Blue : Color.
+Proof. typecheck. Qed.
+
+Definition isRed: val :=
+  rec: "isRed" "c" :=
+    "c" = Red.
+Theorem isRed_t: ⊢ isRed : (Color -> boolT).
+Proof. typecheck. Qed.
+Hint Resolve isRed_t : types.
+
+End code.
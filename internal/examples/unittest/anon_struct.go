@@ -0,0 +1,33 @@
+package unittest
+
+type hasAnonField struct {
+	pos struct {
+		x uint64
+		y uint64
+	}
+}
+
+func anonStructVar() uint64 {
+	var p struct {
+		x uint64
+		y uint64
+	}
+	p.x = 1
+	return p.x + p.y
+}
+
+func anonStructLiteral() uint64 {
+	p := struct {
+		x uint64
+		y uint64
+	}{x: 2, y: 3}
+	return p.y
+}
+
+func otherAnonStruct() bool {
+	p := struct {
+		x  uint64
+		ok bool
+	}{x: 1, ok: true}
+	return p.ok
+}
@@ -0,0 +1,56 @@
+package unittest
+
+type hasArrayField struct {
+	data [4]uint64
+}
+
+func arrayLiteral() [3]uint64 {
+	return [3]uint64{1, 2, 3}
+}
+
+func partialArrayLiteral() [4]uint64 {
+	return [4]uint64{1, 2}
+}
+
+func arrayFieldLiteral() hasArrayField {
+	return hasArrayField{data: [4]uint64{1, 2, 3, 4}}
+}
+
+func sparseArrayLiteral(x uint64) [4]uint64 {
+	return [4]uint64{1: x, 3}
+}
+
+func arrayToSlice() []uint64 {
+	var a = [3]uint64{1, 2, 3}
+	return a[:]
+}
+
+func arrayPartialSlices(arr [4]uint64) uint64 {
+	var a = arr
+	x := a[1:3]
+	y := a[2:]
+	z := a[:1]
+	return uint64(len(x) + len(y) + len(z))
+}
+
+func arrayPointerSlice(p *[4]uint64) []uint64 {
+	return p[1:]
+}
+
+func (f *hasArrayField) dataSlice() []uint64 {
+	return f.data[:]
+}
+
+const arrayLen = 2
+
+func constArrayLength() [arrayLen * 2]uint64 {
+	return [arrayLen * 2]uint64{1}
+}
+
+func makeConstSize() []uint64 {
+	return make([]uint64, 2*arrayLen, arrayLen*4+1)
+}
+
+func makeRuntimeSize(n uint64) []uint64 {
+	return make([]uint64, n*arrayLen)
+}
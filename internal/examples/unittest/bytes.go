@@ -0,0 +1,33 @@
+package unittest
+
+type byteBuf struct {
+	data []byte
+	last byte
+}
+
+func (b *byteBuf) putByte(x byte) {
+	b.data = append(b.data, x)
+	b.last = x
+}
+
+func uint8Slice(x uint8) []uint8 {
+	return []uint8{x}
+}
+
+func byteFromUint64(x uint64) byte {
+	return byte(x)
+}
+
+func runeToUint32(r rune) uint32 {
+	return uint32(r)
+}
+
+func runeLiteral() rune {
+	var r rune = 'a'
+	return r + 1
+}
+
+func byteLiteral() byte {
+	var b byte = 'z'
+	return b
+}
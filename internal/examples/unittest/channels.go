@@ -0,0 +1,27 @@
+package unittest
+
+func sendAndReceive() uint64 {
+	ch := make(chan uint64, 1)
+	ch <- 3
+	x := <-ch
+	return x
+}
+
+func produce(ch chan<- uint64, n uint64) {
+	for i := uint64(0); i < n; i++ {
+		ch <- i
+	}
+	close(ch)
+}
+
+func consumeOne(ch <-chan uint64) (uint64, bool) {
+	x, ok := <-ch
+	return x, ok
+}
+
+func unbufferedSpawn() uint64 {
+	ch := make(chan uint64)
+	go produce(ch, 2)
+	<-ch
+	return <-ch
+}
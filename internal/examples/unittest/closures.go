@@ -0,0 +1,50 @@
+package unittest
+
+func applyTwice(f func(uint64) uint64, x uint64) uint64 {
+	return f(f(x))
+}
+
+func storedClosure() uint64 {
+	add := func(x uint64) uint64 {
+		return x + 1
+	}
+	return add(2)
+}
+
+func passClosure(n uint64) uint64 {
+	return applyTwice(func(x uint64) uint64 {
+		return x + n
+	}, 3)
+}
+
+func closureCapture() uint64 {
+	var count = uint64(0)
+	incr := func() {
+		count += 1
+	}
+	incr()
+	incr()
+	return count
+}
+
+func closureMultipleReturn() (uint64, bool) {
+	divmod := func(x uint64, y uint64) (uint64, uint64) {
+		return x / y, x % y
+	}
+	q, r := divmod(7, 2)
+	return q, r == 1
+}
+
+func closureEarlyReturn(x uint64) uint64 {
+	lookup := func(k uint64) (uint64, bool) {
+		if k == 0 {
+			return 0, false
+		}
+		return k + 1, true
+	}
+	v, ok := lookup(x)
+	if !ok {
+		return 0
+	}
+	return v
+}
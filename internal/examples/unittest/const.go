@@ -15,3 +15,37 @@ const DivisionInConst uint64 = (4096 - 8) / 8
 const ModInConst uint64 = 513 + 12%8 // 517
 
 const ModInConstParens uint64 = (513 + 12) % 8 // 5
+
+const blockSize = 4096
+
+const wordsPerBlock = blockSize / 8
+
+const DivisionOfConst uint64 = 4096 / 8
+
+// MaxEntries bounds the number of entries.
+const MaxEntries uint64 = 16
+
+// Limits on request sizes
+const (
+	// MinRequest is the smallest request.
+	MinRequest uint64 = 1
+	MaxRequest uint64 = 1024 // the largest request
+)
+
+const Flag = true
+
+const TypedFlag bool = false
+
+const Greeting = "hello, " + "world"
+
+const FlagsAgree = Flag == TypedFlag
+
+const FoldedUntyped = 3 + 4*5
+
+const FoldedUint32 uint32 = 1<<31 + 5
+
+const FoldedByte byte = 'a' + 2
+
+const FoldedConversion = uint32(2 * TypedInt32)
+
+const FoldedGreeting = Greeting + "!"
@@ -46,6 +46,80 @@ func conditionalAssign(x bool) uint64 {
 	return y
 }
 
+func conditionalDef(x bool, a uint64) uint64 {
+	var y uint64
+	if x {
+		y = a
+	} else {
+		y = a + 1
+	}
+	return y * 2
+}
+
+type condCounter struct {
+	f uint64
+}
+
+func (c *condCounter) inc() {
+	c.f += 1
+}
+
+// the remaining functions modify the conditionally-defined variable, so it
+// still needs to be declared with var
+
+func conditionalDefFieldStore(x bool, a condCounter, b condCounter) condCounter {
+	var s condCounter
+	if x {
+		s = a
+	} else {
+		s = b
+	}
+	s.f = 3
+	return s
+}
+
+func conditionalDefPointerMethod(x bool, a condCounter, b condCounter) uint64 {
+	var s condCounter
+	if x {
+		s = a
+	} else {
+		s = b
+	}
+	s.inc()
+	return s.f
+}
+
+func conditionalDefIndexStore(x bool, a []uint64, b []uint64) []uint64 {
+	var arr []uint64
+	if x {
+		arr = a
+	} else {
+		arr = b
+	}
+	arr[0] = 9
+	return arr
+}
+
+func earlyReturnWithElse(x bool, a uint64) uint64 {
+	var y = a
+	if x {
+		return 0
+	} else {
+		y += 1
+	}
+	return y * 2
+}
+
+func earlyReturnInElse(x bool, a uint64) uint64 {
+	var y = a
+	if x {
+		y += 1
+	} else {
+		return 0
+	}
+	return y * 2
+}
+
 func elseIf(x, y bool) uint64 {
 	if x {
 		return 0
@@ -55,3 +129,55 @@ func elseIf(x, y bool) uint64 {
 		return 2
 	}
 }
+
+func leadingGuards(s []uint64, x uint64) uint64 {
+	if len(s) == 0 {
+		return 0
+	}
+	if x == 0 {
+		return 1
+	}
+	y := s[0] + x
+	return y
+}
+
+func leadingVoidGuards(s []uint64, p *uint64) {
+	if len(s) == 0 {
+		return
+	}
+	if p == nil {
+		return
+	}
+	*p = s[0]
+}
+
+func minCond(a uint64, b uint64) uint64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func betweenCond(a uint64, b uint64, c uint64) bool {
+	if a < b && b <= c {
+		return true
+	}
+	return false
+}
+
+func ifWithInit(s []uint64) uint64 {
+	if n := uint64(len(s)); n > 2 {
+		return n
+	} else if n == 0 {
+		return 1
+	}
+	return 2
+}
+
+func ifWithInitNoReturn(x uint64) uint64 {
+	var y = uint64(0)
+	if z := x + 1; z > 3 {
+		y = z
+	}
+	return y
+}
@@ -35,3 +35,27 @@ func stringToStringWrapper(s string) stringWrapper {
 func stringWrapperToString(s stringWrapper) string {
 	return string(s)
 }
+
+type byteSliceWrapper []byte
+
+func byteSliceWrapperToString(p byteSliceWrapper) stringWrapper {
+	return stringWrapper(p)
+}
+
+func stringWrapperToByteSlice(s stringWrapper) byteSliceWrapper {
+	return byteSliceWrapper(s)
+}
+
+type uint32Wrapper uint32
+
+func widenUint32(x uint32) uint64 {
+	return uint64(x)
+}
+
+func narrowUint64(x uint64) (uint32, byte) {
+	return uint32(x), byte(x)
+}
+
+func lenToUint32Wrapper(p []byte) uint32Wrapper {
+	return uint32Wrapper(len(p))
+}
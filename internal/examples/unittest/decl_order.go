@@ -0,0 +1,22 @@
+package unittest
+
+// Go allows declarations in any order; these are emitted after the
+// declarations they depend on.
+
+func useLater() uint64 {
+	return laterFunc(later{a: 2})
+}
+
+func (l later) double() uint64 {
+	return l.a * 2
+}
+
+func laterFunc(l later) uint64 {
+	return l.double() + laterConst
+}
+
+type later struct {
+	a uint64
+}
+
+const laterConst uint64 = 3
@@ -0,0 +1,41 @@
+package unittest
+
+import "sync"
+
+func namedResults() (x uint64, ok bool) {
+	x = 2
+	ok = true
+	return
+}
+
+func deferIncrement() (n uint64) {
+	defer func() {
+		n++
+	}()
+	return 5
+}
+
+func deferUnlock(m *sync.Mutex) uint64 {
+	m.Lock()
+	defer m.Unlock()
+	return 1
+}
+
+func deferWithArgs(s []uint64) {
+	defer atomicCreateStub("dir", "file", nil)
+	s[0] = 1
+}
+
+type deferLog struct {
+	entries []uint64
+}
+
+func (l *deferLog) append(x uint64) {
+	l.entries = append(l.entries, x)
+}
+
+func deferMethodWithArgs(logs []*deferLog, i uint64) {
+	var j = i
+	defer logs[j].append(j + 1)
+	j = 0
+}
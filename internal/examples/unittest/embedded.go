@@ -0,0 +1,54 @@
+package unittest
+
+type embedA struct {
+	a uint64
+}
+
+type embedB struct {
+	embedA
+	b uint64
+}
+
+type embedC struct {
+	*embedB
+	c uint64
+}
+
+func readEmbedded(b embedB) uint64 {
+	return b.embedA.a
+}
+
+func readPromoted(b embedB) uint64 {
+	return b.a
+}
+
+func readPromotedPtr(c embedC) uint64 {
+	return c.a + c.b
+}
+
+func embeddedLiteral() embedB {
+	return embedB{embedA: embedA{a: 1}, b: 2}
+}
+
+func (a embedA) getA() uint64 {
+	return a.a
+}
+
+func (a *embedA) setA(x uint64) {
+	a.a = x
+}
+
+func callPromoted(b embedB) uint64 {
+	return b.getA()
+}
+
+func callPromotedPtr(c embedC) uint64 {
+	c.setA(3)
+	return c.getA()
+}
+
+func callPromotedAddr() uint64 {
+	var b embedB
+	b.setA(2)
+	return b.getA()
+}
@@ -5,3 +5,15 @@ func empty() {}
 func emptyReturn() {
 	return
 }
+
+func ifNoElse(b bool) {
+	if b {
+		empty()
+	}
+}
+
+func emptyBranches(b bool) {
+	if b {
+	} else {
+	}
+}
@@ -0,0 +1,45 @@
+package unittest
+
+// Color is an enum defined with a named type and constants
+type Color uint64
+
+const (
+	Red   Color = 0
+	Green Color = 1
+	Blue  Color = 2
+)
+
+// Level is an enum with a narrower underlying type
+type Level uint32
+
+const (
+	Low  Level = 1
+	Mid  Level = 5
+	High Level = 10
+)
+
+func isWarm(c Color) bool {
+	return c == Red
+}
+
+func nextColor(c Color) Color {
+	if c == Blue {
+		return Red
+	}
+	return c + 1
+}
+
+func raise(l Level) Level {
+	if l < High {
+		return l + Low
+	}
+	return l
+}
+
+func colorIndex(c Color) uint64 {
+	return uint64(c)
+}
+
+const Purple = Color(3)
+
+const Top = Level(20)
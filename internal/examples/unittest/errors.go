@@ -0,0 +1,35 @@
+package unittest
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrNotFound = errors.New("not found")
+
+func lookupEntry(m map[uint64]string, k uint64) (string, error) {
+	v, ok := m[k]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return v, nil
+}
+
+func checkedDivErr(x uint64, y uint64) (uint64, error) {
+	if y == 0 {
+		return 0, fmt.Errorf("division of %d by zero", x)
+	}
+	return x / y, nil
+}
+
+func useLookup(m map[uint64]string) string {
+	v, err := lookupEntry(m, 1)
+	if err != nil {
+		return err.Error()
+	}
+	return v
+}
+
+func isNotFound(err error) bool {
+	return err == ErrNotFound
+}
@@ -0,0 +1,28 @@
+package unittest
+
+// globals that are only read are translated as definitions
+var defaultTimeout uint64 = 100
+
+var verbose bool
+
+func timeout() uint64 {
+	if verbose {
+		return 2 * defaultTimeout
+	}
+	return defaultTimeout
+}
+
+// globals that are modified are translated as locations
+var requests uint64
+
+var lastRequest = "none"
+
+func recordRequest(name string) uint64 {
+	requests += 1
+	lastRequest = name
+	return requests
+}
+
+func requestCounter() *uint64 {
+	return &requests
+}
@@ -3,3 +3,40 @@ package unittest
 func TakesFunctionType(f func()) {
 	f()
 }
+
+type hasFunctionFields struct {
+	thunk  func() uint64
+	nested func(func() uint64) uint64
+}
+
+type nestedFunctionType func(func() uint64) uint64
+
+func callFunctionParam(f func(uint64, bool) uint64, x uint64) uint64 {
+	return f(x, true) + 1
+}
+
+func makeAdder(n uint64) func(uint64) uint64 {
+	return func(x uint64) uint64 {
+		return x + n
+	}
+}
+
+func callReturnedFunction() uint64 {
+	return makeAdder(2)(3)
+}
+
+func callFunctionSlice(fs []func() uint64, i uint64) uint64 {
+	return fs[i]()
+}
+
+func callFunctionLiteral() uint64 {
+	return func(x uint64) uint64 {
+		return x * 2
+	}(4)
+}
+
+// the type of f comes from the type checker rather than a Go type expression
+func callThroughPointer(p *func(uint64) bool, x uint64) bool {
+	f := *p
+	return f(x)
+}
@@ -0,0 +1,70 @@
+package unittest
+
+type counter interface {
+	get() uint64
+	add(n uint64) uint64
+}
+
+type fixedCounter struct {
+	n uint64
+}
+
+func (c fixedCounter) get() uint64 {
+	return c.n
+}
+
+func (c fixedCounter) add(n uint64) uint64 {
+	return c.n + n
+}
+
+type doubleCounter struct {
+	n uint64
+}
+
+func (c doubleCounter) get() uint64 {
+	return 2 * c.n
+}
+
+func (c doubleCounter) add(n uint64) uint64 {
+	return 2 * (c.n + n)
+}
+
+type counterHolder struct {
+	c counter
+}
+
+func useCounter(c counter) uint64 {
+	return c.add(c.get())
+}
+
+func newCounter() counter {
+	return fixedCounter{n: 1}
+}
+
+func counterConversions() uint64 {
+	var c counter = fixedCounter{n: 2}
+	c = doubleCounter{n: 3}
+	h := counterHolder{c: fixedCounter{n: 4}}
+	return useCounter(c) + useCounter(h.c) + useCounter(newCounter())
+}
+
+func counterOrZero(c counter) uint64 {
+	if c == nil {
+		return 0
+	}
+	return c.get()
+}
+
+func noCounter() counter {
+	return nil
+}
+
+func conditionalDefInterface(x bool, n uint64) counter {
+	var c counter
+	if x {
+		c = fixedCounter{n: n}
+	} else {
+		c = doubleCounter{n: n}
+	}
+	return c
+}
@@ -14,3 +14,7 @@ type my_u32 uint32
 type also_u32 my_u32
 
 const ConstWithAbbrevType also_u32 = 3
+
+const MaxUint32 uint32 = 1<<32 - 1
+
+const MaxByte byte = 255
@@ -29,3 +29,19 @@ func oddLiterals() allTheLiterals {
 		b:   false,
 	}
 }
+
+func literalValue() allTheLiterals {
+	return allTheLiterals{int: 1, s: "value", b: true}
+}
+
+func literalAllocation() *allTheLiterals {
+	return &allTheLiterals{int: 2, s: "allocated", b: false}
+}
+
+func sliceLiteralRef(x uint64) *[]uint64 {
+	return &[]uint64{x}
+}
+
+func arrayLiteralRef(x uint64) *[2]uint64 {
+	return &[2]uint64{x, x}
+}
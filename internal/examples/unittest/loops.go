@@ -104,8 +104,6 @@ func nestedGoStyleLoops() {
 			if true {
 				break
 			}
-			// TODO: this is necessary to make the break actually return
-			continue
 		}
 	}
 }
@@ -123,7 +121,44 @@ func breakFromLoop() {
 		if true {
 			break
 		}
-		// TODO: this is necessary for the break to work
+	}
+}
+
+func breakSkipsRest(n uint64) uint64 {
+	var x = uint64(0)
+	for {
+		if x >= n {
+			break
+		}
+		x = x + 1
+	}
+	return x
+}
+
+func explicitTrailingContinue(n uint64) uint64 {
+	var x = uint64(0)
+	for {
+		if x >= n {
+			break
+		}
+		x = x + 1
 		continue
 	}
+	return x
+}
+
+func infiniteLoopBreak(c *bool) {
+	for {
+		if *c {
+			break
+		}
+	}
+}
+
+func whileLoop(n uint64) uint64 {
+	var x = uint64(0)
+	for x < n {
+		x = x + 1
+	}
+	return x
 }
@@ -28,3 +28,54 @@ func MapTypeAliases(m1 map[IntWrapper]bool, m2 MapWrapper) {
 func StringMap(m map[string]uint64) uint64 {
 	return m["foo"]
 }
+
+func mapAsSet(keys []uint64, k uint64) bool {
+	s := make(map[uint64]bool)
+	for _, key := range keys {
+		s[key] = true
+	}
+	if s[k] {
+		return true
+	}
+	return false
+}
+
+func deleteKey(m map[uint64]uint64, k uint64) uint64 {
+	delete(m, k)
+	return m[k]
+}
+
+func deleteFromWrapper(m MapWrapper) {
+	delete(m, 1)
+}
+
+func mapLookupOk(m map[uint64]uint64, k uint64) (uint64, bool) {
+	v, ok := m[k]
+	return v, ok
+}
+
+func mapLookupValue(m map[uint64]uint64, k uint64) uint64 {
+	v := m[k]
+	return v
+}
+
+func mapLookupAssign(m map[uint64]uint64, k uint64) bool {
+	var v uint64
+	var ok bool
+	v, ok = m[k]
+	return ok && v > 0
+}
+
+func emptyMapLiteral() map[uint64]uint64 {
+	return map[uint64]uint64{}
+}
+
+func mapLiteral() map[uint64]bool {
+	return map[uint64]bool{1: true, 3: false}
+}
+
+func nestedMapLiteral() map[uint64]map[string]uint64 {
+	return map[uint64]map[string]uint64{
+		0: {"a": 1},
+	}
+}
@@ -10,3 +10,7 @@ func returnTwoWrapper(data []byte) (uint64, uint64) {
 }
 
 func multipleVar(x, y uint64) {}
+
+func swapValues(p *uint64, q *uint64) {
+	*p, *q = *q, *p
+}
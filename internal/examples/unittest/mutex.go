@@ -0,0 +1,28 @@
+package unittest
+
+import "sync"
+
+type counterWithLock struct {
+	mu    sync.Mutex
+	count uint64
+}
+
+func newCounterWithLock() *counterWithLock {
+	return &counterWithLock{}
+}
+
+func (c *counterWithLock) increment() {
+	c.mu.Lock()
+	c.count += 1
+	c.mu.Unlock()
+}
+
+func (c *counterWithLock) lockRef() *sync.Mutex {
+	return &c.mu
+}
+
+func allocMutex() {
+	mu := new(sync.Mutex)
+	mu.Lock()
+	mu.Unlock()
+}
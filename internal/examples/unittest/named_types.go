@@ -0,0 +1,45 @@
+package unittest
+
+// Bytes is a named slice type.
+type Bytes []byte
+
+// Counts maps keys to counts.
+type Counts map[uint64]uint64
+
+func newBytes(n uint64) Bytes {
+	return make(Bytes, n)
+}
+
+func firstByte(b Bytes) byte {
+	return b[0]
+}
+
+func countOf(c Counts, k uint64) uint64 {
+	return c[k]
+}
+
+type Name string
+
+func toColor(x uint64) Color {
+	return Color(x)
+}
+
+func fromColor(c Color) uint64 {
+	return uint64(c)
+}
+
+func levelToColor(l Level) Color {
+	return Color(l)
+}
+
+func toBytes(b []byte) Bytes {
+	return Bytes(b)
+}
+
+func fromBytes(b Bytes) []byte {
+	return []byte(b)
+}
+
+func nameLength(n Name) uint64 {
+	return uint64(len(string(n)))
+}
@@ -19,3 +19,12 @@ func ComparePointerToNil() bool {
 	s := new(uint64)
 	return s != nil
 }
+
+func CompareNilToPointer() bool {
+	s := new(uint64)
+	return nil == s
+}
+
+func CompareNilToError(err error) bool {
+	return nil != err
+}
@@ -42,3 +42,62 @@ func AssignOps() {
 	x++
 	x--
 }
+
+func NotEqualsInts(x uint64, y uint32) bool {
+	return x != 0 && y != 1
+}
+
+func NotEqualsPointers(p *uint64, q *uint64) bool {
+	return p != nil && p != q
+}
+
+func NotEqualsStrings(s string) bool {
+	return s != "" && s != "x"
+}
+
+type counterFields struct {
+	hits  uint64
+	small uint32
+}
+
+func IncDecFields(c *counterFields) {
+	c.hits++
+	c.small--
+}
+
+func IncDecLocals() uint32 {
+	var x uint32
+	x++
+	x++
+	x--
+	return x
+}
+
+func CompoundAssignOps(x uint64) uint64 {
+	var y uint64 = 6
+	y *= x
+	y /= 2
+	y %= 100
+	y ^= 1
+	y &= 0xff
+	y <<= 2
+	y >>= 1
+	return y
+}
+
+type flags struct {
+	bits uint32
+}
+
+func (f *flags) set(b uint32) {
+	f.bits |= b
+}
+
+func BoolEquality(b1 bool, b2 bool) bool {
+	return b1 == b2 || b1 != !b2
+}
+
+func CompoundAssignIndexCall(s []uint64, m map[uint64]uint64, ch chan uint64) {
+	s[len(s)-1] += 2
+	m[<-ch] *= 3
+}
@@ -1,5 +1,38 @@
 package unittest
 
+import "fmt"
+
 func PanicAtTheDisco() {
 	panic("disco")
 }
+
+const unreachable = "unreachable"
+
+func checkedDiv(x, y uint64) uint64 {
+	if y != 0 {
+		return x / y
+	} else {
+		panic(unreachable)
+	}
+}
+
+const maxValue uint64 = 10
+
+func panicFormatted(x uint64) {
+	if x > maxValue {
+		panic(fmt.Sprintf("value above %d", maxValue))
+	}
+}
+
+func panicFormattedArg(x uint64) {
+	if x > maxValue {
+		panic(fmt.Sprintf("value %d above maximum", x))
+	}
+}
+
+func assertPositive(x uint64) uint64 {
+	if !(x > 0) {
+		panic("not positive")
+	}
+	return x - 1
+}
@@ -0,0 +1,10 @@
+package unittest
+
+import "github.com/tchajed/goose/machine"
+
+// Linearize is a value in the model, while RandomUint64 is a function that is
+// applied to #()
+func linearizeRandom() uint64 {
+	machine.Linearize()
+	return machine.RandomUint64()
+}
@@ -13,3 +13,13 @@ func ReassignVars() {
 	z = composite{a: y, b: x}
 	x = z.a
 }
+
+func discardPureVar(x uint64) uint64 {
+	_ = x
+	return 1
+}
+
+func discardCall() {
+	_ = composite{a: 1, b: 2}
+	_ = discardPureVar(2)
+}
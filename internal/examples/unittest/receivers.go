@@ -0,0 +1,23 @@
+package unittest
+
+type recv struct {
+	n uint64
+}
+
+func (r recv) get() uint64 {
+	return r.n
+}
+
+func (r *recv) set(n uint64) {
+	r.n = n
+}
+
+func valueOnPointer(p *recv) uint64 {
+	return p.get()
+}
+
+func pointerOnValue() uint64 {
+	var r recv
+	r.set(3)
+	return r.get()
+}
@@ -0,0 +1,22 @@
+package unittest
+
+// Go names that are reserved in Coq
+
+const match uint64 = 2
+
+type interval struct {
+	in  uint64
+	end uint64
+}
+
+func end(i interval) uint64 {
+	return i.end
+}
+
+func contains(i interval, x uint64) bool {
+	return i.in <= x && x < end(i)
+}
+
+func doubleMatch() uint64 {
+	return match * 2
+}
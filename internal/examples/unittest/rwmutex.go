@@ -0,0 +1,37 @@
+package unittest
+
+import "sync"
+
+type cache struct {
+	mu      sync.RWMutex
+	entries map[uint64]string
+}
+
+func newCache() *cache {
+	return &cache{entries: make(map[uint64]string)}
+}
+
+func (c *cache) get(k uint64) string {
+	c.mu.RLock()
+	v := c.entries[k]
+	c.mu.RUnlock()
+	return v
+}
+
+func (c *cache) put(k uint64, v string) {
+	c.mu.Lock()
+	c.entries[k] = v
+	c.mu.Unlock()
+}
+
+func readThenWrite(l *sync.RWMutex) {
+	l.RLock()
+	l.RUnlock()
+	l.Lock()
+	l.Unlock()
+}
+
+func allocRWMutex() {
+	l := new(sync.RWMutex)
+	readThenWrite(l)
+}
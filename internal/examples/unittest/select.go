@@ -0,0 +1,34 @@
+package unittest
+
+func tryReceive(ch chan uint64) uint64 {
+	var x uint64
+	select {
+	case v := <-ch:
+		x = v
+	default:
+		x = 0
+	}
+	return x
+}
+
+func sendOrReceive(in chan uint64, out chan uint64) bool {
+	var closed = false
+	select {
+	case out <- 1:
+	case _, ok := <-in:
+		closed = !ok
+	}
+	return closed
+}
+
+func receiveEither(a chan uint64, b chan uint64) uint64 {
+	var which uint64
+	select {
+	case <-a:
+		which = 1
+	case <-b:
+		which = 2
+	default:
+	}
+	return which
+}
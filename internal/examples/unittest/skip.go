@@ -0,0 +1,23 @@
+package unittest
+
+//goose:skip
+type skippedChan struct {
+	c chan uint64
+}
+
+// skippedHelper uses channels, which goose doesn't support, but it isn't
+// translated.
+//
+//goose:skip
+func skippedHelper(c chan uint64) {
+	c <- 1
+}
+
+//goose:skip
+func (s skippedChan) send() {
+	s.c <- 2
+}
+
+func notSkipped() uint64 {
+	return 1
+}
@@ -30,3 +30,38 @@ func (ts sliceOfThings) getThingRef(i uint64) *thing {
 func makeAlias() SliceAlias {
 	return make(SliceAlias, 10)
 }
+
+func doubleInPlace(s []uint64) {
+	for i := range s {
+		s[i] = 2 * s[i]
+	}
+}
+
+func moreElements(n uint64) []uint64 {
+	return make([]uint64, n)
+}
+
+func appendCallResult(s []uint64) []uint64 {
+	return append(s, moreElements(2)...)
+}
+
+func emptySliceLiteral() []uint64 {
+	return []uint64{}
+}
+
+func sliceLiteral(x uint64) []uint64 {
+	return []uint64{1, x, 3}
+}
+
+func sparseSliceLiteral(x uint64) []uint64 {
+	return []uint64{2: x, 0: 1}
+}
+
+func aliasElementRef(s SliceAlias, i uint64) *bool {
+	return &s[i]
+}
+
+func setThroughElementRef(s []uint64) {
+	p := &s[0]
+	*p = 1
+}
@@ -2,6 +2,8 @@ package unittest
 
 import (
 	"sync"
+
+	"github.com/tchajed/goose/internal/examples/unittest/generic"
 )
 
 // Skip is a placeholder for some impure code
@@ -38,3 +40,54 @@ func loopSpawn() {
 		continue
 	}
 }
+
+func rangeSpawn(s []uint64) {
+	for _, x := range s {
+		x := x
+		go func() {
+			threadCode(x)
+		}()
+	}
+}
+
+func spawnWithArgs() {
+	var x uint64 = 1
+	go threadCode(x)
+	x = 2
+	go func(a uint64, b uint64) {
+		threadCode(a + b)
+	}(x, 3)
+}
+
+func spawnCapture(l *sync.Mutex) {
+	var count uint64
+	x := uint64(5)
+	go func() {
+		l.Lock()
+		count = count + x
+		l.Unlock()
+	}()
+	go func() {
+		if x == 0 {
+			return
+		}
+		threadCode(x)
+	}()
+}
+
+type spawnWorker struct {
+	id uint64
+}
+
+func (w spawnWorker) run(n uint64) {
+	threadCode(w.id + n)
+}
+
+// the receiver and arguments are evaluated before the thread starts
+func spawnMethod(ws []spawnWorker, i uint64) {
+	go ws[i].run(i + 1)
+}
+
+func spawnPackageFunction(x uint64) {
+	go generic.Id(x)
+}
@@ -0,0 +1,11 @@
+package unittest
+
+import "fmt"
+
+func formatInt(x uint64) string {
+	return fmt.Sprintf("x = %d", x)
+}
+
+func formatMixed(name string, n uint32) string {
+	return fmt.Sprintf("%s has %d items (100%%)", name, n)
+}
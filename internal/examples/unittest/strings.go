@@ -9,3 +9,42 @@ func stringAppend(s string, x uint64) string {
 func stringLength(s string) uint64 {
 	return uint64(len(s))
 }
+
+func joinStrings(pieces []string, sep string) string {
+	var out = ""
+	for i, p := range pieces {
+		if i > 0 {
+			out += sep
+		}
+		out = out + p
+	}
+	return out
+}
+
+func repeatString(s string, n uint64) string {
+	var out = ""
+	for i := uint64(0); i < n; i++ {
+		out = out + s
+	}
+	return out
+}
+
+func stringsEqual(s1, s2 string) bool {
+	return s1 == s2
+}
+
+func stringNotLiteral(s string) bool {
+	return s != "foo"
+}
+
+func concatWrapper(s stringWrapper) stringWrapper {
+	return s + "!"
+}
+
+func stringFirstByte(s string) byte {
+	return s[0]
+}
+
+func stringByteAt(s string, i uint64) uint64 {
+	return uint64(s[i])
+}
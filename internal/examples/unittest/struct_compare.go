@@ -0,0 +1,46 @@
+package unittest
+
+type point struct {
+	x uint64
+	y uint64
+}
+
+type segment struct {
+	start point
+	end   point
+	label string
+}
+
+func pointsEqual(p point, q point) bool {
+	return p == q
+}
+
+func pointsDiffer(p point, q point) bool {
+	return p != q
+}
+
+func segmentsEqual(s1 segment, s2 segment) bool {
+	return s1 == s2
+}
+
+func isOrigin(p *point) bool {
+	return *p == point{x: 0, y: 0}
+}
+
+func makePoint(x uint64) point {
+	return point{x: x, y: x}
+}
+
+func comparesCall(p point) bool {
+	return makePoint(p.x) == p
+}
+
+func switchOnPoint(p point) uint64 {
+	switch p {
+	case point{}:
+		return 0
+	case makePoint(1), makePoint(2):
+		return 1
+	}
+	return 2
+}
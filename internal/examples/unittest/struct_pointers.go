@@ -57,3 +57,24 @@ func setField() S {
 	s.c = true
 	return s
 }
+
+type nestedPtr struct {
+	inner *TwoInts
+	s     S
+}
+
+func pointerValueChain(p *S) uint64 {
+	return p.b.x
+}
+
+func valueValueChain(v S) uint64 {
+	return v.b.y
+}
+
+func valuePointerChain(n nestedPtr) uint64 {
+	return n.inner.x
+}
+
+func pointerPointerChain(n *nestedPtr) uint64 {
+	return n.inner.y + n.s.b.x
+}
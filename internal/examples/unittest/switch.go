@@ -0,0 +1,42 @@
+package unittest
+
+func switchTag(x uint64) uint64 {
+	switch x {
+	case 0:
+		return 10
+	case 1, 2:
+		return 20
+	default:
+		return 30
+	}
+}
+
+func switchNoTag(x uint64) string {
+	switch {
+	case x < 10:
+		return "small"
+	case x < 100:
+		return "medium"
+	}
+	return "large"
+}
+
+func switchDefaultFirst(x uint64) uint64 {
+	var y = uint64(0)
+	switch x {
+	default:
+		y = 3
+	case 1:
+		y = 1
+	}
+	return y
+}
+
+func switchWithInit(s []uint64) uint64 {
+	switch n := uint64(len(s)); n {
+	case 0:
+		return 0
+	default:
+		return n + 1
+	}
+}
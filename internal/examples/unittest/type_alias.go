@@ -12,3 +12,30 @@ func convertToAlias() Timestamp {
 	x := uint64(2)
 	return Timestamp(x)
 }
+
+type timestampPair struct {
+	start Timestamp
+	end   Timestamp
+}
+
+type Interval = timestampPair
+
+type aliasFields struct {
+	ts       my_u64
+	interval Interval
+}
+
+func aliasParam(x my_u64, i Interval) my_u64 {
+	return x + uint64(i.start)
+}
+
+// aliasUser is declared before the type its alias refers to
+type aliasUser struct {
+	r laterAlias
+}
+
+type laterAlias = laterRecord
+
+type laterRecord struct {
+	n uint64
+}
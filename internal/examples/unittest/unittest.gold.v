@@ -5,6 +5,214 @@ From Goose Require github_com.tchajed.marshal.
 
 From Perennial.goose_lang Require Import ffi.disk_prelude.
 
+(* anon_struct.go *)
+
+Definition struct__d1cb477c := struct.decl [
+  "x" :: uint64T;
+  "y" :: uint64T
+].
+
+Definition hasAnonField := struct.decl [
+  "pos" :: struct.t struct__d1cb477c
+].
+
+Definition anonStructVar: val :=
+  rec: "anonStructVar" <> :=
+    let: "p" := ref (zero_val (struct.t struct__d1cb477c)) in
+    struct.storeF struct__d1cb477c "x" "p" #1;;
+    struct.get struct__d1cb477c "x" (![struct.t struct__d1cb477c] "p") + struct.get struct__d1cb477c "y" (![struct.t struct__d1cb477c] "p").
+
+Definition anonStructLiteral: val :=
+  rec: "anonStructLiteral" <> :=
+    let: "p" := struct.mk struct__d1cb477c [
+      "x" ::= #2;
+      "y" ::= #3
+    ] in
+    struct.get struct__d1cb477c "y" "p".
+
+Definition struct__e6dc33db := struct.decl [
+  "x" :: uint64T;
+  "ok" :: boolT
+].
+
+Definition otherAnonStruct: val :=
+  rec: "otherAnonStruct" <> :=
+    let: "p" := struct.mk struct__e6dc33db [
+      "x" ::= #1;
+      "ok" ::= #true
+    ] in
+    struct.get struct__e6dc33db "ok" "p".
+
+(* arrays.go *)
+
+Definition hasArrayField := struct.decl [
+  "data" :: arrayT uint64T
+].
+
+Definition arrayLiteral: val :=
+  rec: "arrayLiteral" <> :=
+    array.mk uint64T [#1; #2; #3].
+
+Definition partialArrayLiteral: val :=
+  rec: "partialArrayLiteral" <> :=
+    array.mk uint64T [#1; #2; zero_val uint64T; zero_val uint64T].
+
+Definition arrayFieldLiteral: val :=
+  rec: "arrayFieldLiteral" <> :=
+    struct.mk hasArrayField [
+      "data" ::= array.mk uint64T [#1; #2; #3; #4]
+    ].
+
+Definition sparseArrayLiteral: val :=
+  rec: "sparseArrayLiteral" "x" :=
+    array.mk uint64T [zero_val uint64T; "x"; #3; zero_val uint64T].
+
+Definition arrayToSlice: val :=
+  rec: "arrayToSlice" <> :=
+    let: "a" := ref_to (arrayT uint64T) (array.mk uint64T [#1; #2; #3]) in
+    array.toSlice uint64T "a" #3.
+
+Definition arrayPartialSlices: val :=
+  rec: "arrayPartialSlices" "arr" :=
+    let: "a" := ref_to (arrayT uint64T) "arr" in
+    let: "x" := SliceSubslice uint64T (array.toSlice uint64T "a" #4) #1 #3 in
+    let: "y" := SliceSkip uint64T (array.toSlice uint64T "a" #4) #2 in
+    let: "z" := SliceTake (array.toSlice uint64T "a" #4) #1 in
+    (slice.len "x" + slice.len "y") + slice.len "z".
+
+Definition arrayPointerSlice: val :=
+  rec: "arrayPointerSlice" "p" :=
+    SliceSkip uint64T (array.toSlice uint64T "p" #4) #1.
+
+Definition hasArrayField__dataSlice: val :=
+  rec: "hasArrayField__dataSlice" "f" :=
+    array.toSlice uint64T (struct.fieldRef hasArrayField "data" "f") #4.
+
+Definition arrayLen : expr := #2.
+
+Definition constArrayLength: val :=
+  rec: "constArrayLength" <> :=
+    array.mk uint64T [#1; zero_val uint64T; zero_val uint64T; zero_val uint64T].
+
+Definition makeConstSize: val :=
+  rec: "makeConstSize" <> :=
+    NewSliceWithCap uint64T #4 #9.
+
+Definition makeRuntimeSize: val :=
+  rec: "makeRuntimeSize" "n" :=
+    NewSlice uint64T ("n" * arrayLen).
+
+(* bytes.go *)
+
+Definition byteBuf := struct.decl [
+  "data" :: slice.T byteT;
+  "last" :: byteT
+].
+
+Definition byteBuf__putByte: val :=
+  rec: "byteBuf__putByte" "b" "x" :=
+    struct.storeF byteBuf "data" "b" (SliceAppend byteT (struct.loadF byteBuf "data" "b") "x");;
+    struct.storeF byteBuf "last" "b" "x";;
+    #().
+
+Definition uint8Slice: val :=
+  rec: "uint8Slice" "x" :=
+    SliceSingleton "x".
+
+Definition byteFromUint64: val :=
+  rec: "byteFromUint64" "x" :=
+    to_u8 "x".
+
+Definition runeToUint32: val :=
+  rec: "runeToUint32" "r" :=
+    "r".
+
+Definition runeLiteral: val :=
+  rec: "runeLiteral" <> :=
+    let: "r" := ref_to uint32T #(U32 97) in
+    (![uint32T] "r") + #(U32 1).
+
+Definition byteLiteral: val :=
+  rec: "byteLiteral" <> :=
+    let: "b" := ref_to byteT #(U8 122) in
+    ![byteT] "b".
+
+(* channels.go *)
+
+Definition sendAndReceive: val :=
+  rec: "sendAndReceive" <> :=
+    let: "ch" := chan.make uint64T #1 in
+    chan.send "ch" #3;;
+    let: "x" := Fst (chan.receive "ch") in
+    "x".
+
+Definition produce: val :=
+  rec: "produce" "ch" "n" :=
+    let: "i" := ref_to uint64T #0 in
+    (for: (λ: <>, (![uint64T] "i") < "n"); (λ: <>, "i" <-[uint64T] ((![uint64T] "i") + #1)) := λ: <>,
+      chan.send "ch" (![uint64T] "i");;
+      Continue);;
+    chan.close "ch";;
+    #().
+
+Definition consumeOne: val :=
+  rec: "consumeOne" "ch" :=
+    let: ("x", "ok") := chan.receive "ch" in
+    ("x", "ok").
+
+Definition unbufferedSpawn: val :=
+  rec: "unbufferedSpawn" <> :=
+    let: "ch" := chan.make uint64T #0 in
+    let: "$a0" := "ch" in
+    let: "$a1" := #2 in
+    Fork (produce "$a0" "$a1");;
+    Fst (chan.receive "ch");;
+    Fst (chan.receive "ch").
+
+(* closures.go *)
+
+Definition applyTwice: val :=
+  rec: "applyTwice" "f" "x" :=
+    "f" ("f" "x").
+
+Definition storedClosure: val :=
+  rec: "storedClosure" <> :=
+    let: "add" := (λ: "x", "x" + #1) in
+    "add" #2.
+
+Definition passClosure: val :=
+  rec: "passClosure" "n" :=
+    applyTwice (λ: "x", "x" + "n") #3.
+
+Definition closureCapture: val :=
+  rec: "closureCapture" <> :=
+    let: "count" := ref_to uint64T #0 in
+    let: "incr" := (λ: <>,
+      "count" <-[uint64T] ((![uint64T] "count") + #1);;
+      #()
+      ) in
+    "incr" #();;
+    "incr" #();;
+    ![uint64T] "count".
+
+Definition closureMultipleReturn: val :=
+  rec: "closureMultipleReturn" <> :=
+    let: "divmod" := (λ: "x" "y", ("x" `quot` "y", "x" `rem` "y")) in
+    let: ("q", "r") := "divmod" #7 #2 in
+    ("q", "r" = #1).
+
+Definition closureEarlyReturn: val :=
+  rec: "closureEarlyReturn" "x" :=
+    let: "lookup" := (λ: "k",
+      (if: "k" = #0
+      then (#0, #false)
+      else ("k" + #1, #true))
+      ) in
+    let: ("v", "ok") := "lookup" "x" in
+    (if: ~ "ok"
+    then #0
+    else "v").
+
 (* comments.go *)
 
 (* unittest is a package full of many independent and small translation examples *)
@@ -52,17 +260,52 @@ Definition UntypedStringConstant : expr := #(str"bar").
 
 Definition TypedInt : expr := #32.
 
-Definition ConstWithArith : expr := #4 + (#3 * TypedInt).
+Definition ConstWithArith : expr := #100.
 
 Definition TypedInt32 : expr := #(U32 3).
 
-Definition DivisionInConst : expr := (#4096 - #8) `quot` #8.
+Definition DivisionInConst : expr := #511.
 
 (* 517 *)
-Definition ModInConst : expr := #513 + (#12 `rem` #8).
+Definition ModInConst : expr := #517.
 
 (* 5 *)
-Definition ModInConstParens : expr := (#513 + #12) `rem` #8.
+Definition ModInConstParens : expr := #5.
+
+Definition blockSize : expr := #4096.
+
+Definition wordsPerBlock : expr := #512.
+
+Definition DivisionOfConst : expr := #512.
+
+(* MaxEntries bounds the number of entries. *)
+Definition MaxEntries : expr := #16.
+
+(* Limits on request sizes *)
+
+(* MinRequest is the smallest request. *)
+Definition MinRequest : expr := #1.
+
+(* the largest request *)
+Definition MaxRequest : expr := #1024.
+
+Definition Flag : expr := #true.
+
+Definition TypedFlag : expr := #false.
+
+Definition Greeting : expr := #(str"hello, world").
+
+Definition FlagsAgree : expr := #false.
+
+Definition FoldedUntyped : expr := #23.
+
+Definition FoldedUint32 : expr := #(U32 2147483653).
+
+Definition FoldedByte : expr := #(U8 99).
+
+Definition FoldedConversion : expr := #(U32 6).
+
+Definition FoldedGreeting : expr := #(str"hello, world!").
 
 (* control_flow.go *)
 
@@ -80,7 +323,7 @@ Definition alwaysReturn: val :=
 
 Definition alwaysReturnInNestedBranches: val :=
   rec: "alwaysReturnInNestedBranches" "x" :=
-    (if: (~ "x")
+    (if: ~ "x"
     then
       (if: "x"
       then #0
@@ -104,14 +347,124 @@ Definition conditionalAssign: val :=
     "y" <-[uint64T] ((![uint64T] "y") + #1);;
     ![uint64T] "y".
 
+Definition conditionalDef: val :=
+  rec: "conditionalDef" "x" "a" :=
+    let: "y" := (if: "x"
+      then "a"
+      else "a" + #1) in
+    "y" * #2.
+
+Definition condCounter := struct.decl [
+  "f" :: uint64T
+].
+
+Definition condCounter__inc: val :=
+  rec: "condCounter__inc" "c" :=
+    struct.storeF condCounter "f" "c" (struct.loadF condCounter "f" "c" + #1);;
+    #().
+
+Definition conditionalDefFieldStore: val :=
+  rec: "conditionalDefFieldStore" "x" "a" "b" :=
+    let: "s" := ref (zero_val (struct.t condCounter)) in
+    (if: "x"
+    then "s" <-[struct.t condCounter] "a"
+    else "s" <-[struct.t condCounter] "b");;
+    struct.storeF condCounter "f" "s" #3;;
+    ![struct.t condCounter] "s".
+
+Definition conditionalDefPointerMethod: val :=
+  rec: "conditionalDefPointerMethod" "x" "a" "b" :=
+    let: "s" := ref (zero_val (struct.t condCounter)) in
+    (if: "x"
+    then "s" <-[struct.t condCounter] "a"
+    else "s" <-[struct.t condCounter] "b");;
+    condCounter__inc "s";;
+    struct.get condCounter "f" (![struct.t condCounter] "s").
+
+Definition conditionalDefIndexStore: val :=
+  rec: "conditionalDefIndexStore" "x" "a" "b" :=
+    let: "arr" := ref (zero_val (slice.T uint64T)) in
+    (if: "x"
+    then "arr" <-[slice.T uint64T] "a"
+    else "arr" <-[slice.T uint64T] "b");;
+    SliceSet uint64T (![slice.T uint64T] "arr") #0 #9;;
+    ![slice.T uint64T] "arr".
+
+Definition earlyReturnWithElse: val :=
+  rec: "earlyReturnWithElse" "x" "a" :=
+    let: "y" := ref_to uint64T "a" in
+    (if: "x"
+    then #0
+    else
+      "y" <-[uint64T] ((![uint64T] "y") + #1);;
+      (![uint64T] "y") * #2).
+
+Definition earlyReturnInElse: val :=
+  rec: "earlyReturnInElse" "x" "a" :=
+    let: "y" := ref_to uint64T "a" in
+    (if: "x"
+    then
+      "y" <-[uint64T] ((![uint64T] "y") + #1);;
+      (![uint64T] "y") * #2
+    else #0).
+
 Definition elseIf: val :=
   rec: "elseIf" "x" "y" :=
     (if: "x"
     then #0
+    else (if: "y"
+    then #1
+    else #2)).
+
+Definition leadingGuards: val :=
+  rec: "leadingGuards" "s" "x" :=
+    (if: slice.len "s" = #0
+    then #0
+    else (if: "x" = #0
+    then #1
+    else
+      let: "y" := SliceGet uint64T "s" #0 + "x" in
+      "y")).
+
+Definition leadingVoidGuards: val :=
+  rec: "leadingVoidGuards" "s" "p" :=
+    (if: slice.len "s" = #0
+    then #()
+    else (if: "p" = #null
+    then #()
     else
-      (if: "y"
-      then #1
-      else #2)).
+      "p" <-[uint64T] (SliceGet uint64T "s" #0);;
+      #())).
+
+Definition minCond: val :=
+  rec: "minCond" "a" "b" :=
+    (if: "a" < "b"
+    then "a"
+    else "b").
+
+Definition betweenCond: val :=
+  rec: "betweenCond" "a" "b" "c" :=
+    (if: ("a" < "b") && ("b" ≤ "c")
+    then #true
+    else #false).
+
+Definition ifWithInit: val :=
+  rec: "ifWithInit" "s" :=
+    let: "n" := slice.len "s" in
+    (if: "n" > #2
+    then "n"
+    else (if: "n" = #0
+    then #1
+    else #2)).
+
+Definition ifWithInitNoReturn: val :=
+  rec: "ifWithInitNoReturn" "x" :=
+    let: "y" := ref_to uint64T #0 in
+    let: "z" := "x" + #1 in
+    (if: "z" > #3
+    then "y" <-[uint64T] "z"
+    else #());;
+    ![uint64T] "y".
 
 (* conversions.go *)
 
@@ -148,6 +501,30 @@ Definition stringWrapperToString: val :=
   rec: "stringWrapperToString" "s" :=
     "s".
 
+Definition byteSliceWrapper: ty := slice.T byteT.
+
+Definition byteSliceWrapperToString: val :=
+  rec: "byteSliceWrapperToString" "p" :=
+    StringFromBytes "p".
+
+Definition stringWrapperToByteSlice: val :=
+  rec: "stringWrapperToByteSlice" "s" :=
+    StringToBytes "s".
+
+Definition uint32Wrapper: ty := uint32T.
+
+Definition widenUint32: val :=
+  rec: "widenUint32" "x" :=
+    to_u64 "x".
+
+Definition narrowUint64: val :=
+  rec: "narrowUint64" "x" :=
+    (to_u32 "x", to_u8 "x").
+
+Definition lenToUint32Wrapper: val :=
+  rec: "lenToUint32Wrapper" "p" :=
+    to_u32 (slice.len "p").
+
 (* copy.go *)
 
 Definition testCopySimple: val :=
@@ -156,7 +533,7 @@ Definition testCopySimple: val :=
     SliceSet byteT "x" #3 #(U8 1);;
     let: "y" := NewSlice byteT #10 in
     SliceCopy byteT "y" "x";;
-    (SliceGet byteT "y" #3) = #(U8 1).
+    SliceGet byteT "y" #3 = #(U8 1).
 
 Definition testCopyDifferentLengths: val :=
   rec: "testCopyDifferentLengths" <> :=
@@ -165,7 +542,7 @@ Definition testCopyDifferentLengths: val :=
     SliceSet byteT "x" #12 #(U8 2);;
     let: "y" := NewSlice byteT #10 in
     let: "n" := SliceCopy byteT "y" "x" in
-    ("n" = #10) && ((SliceGet byteT "y" #3) = #(U8 1)).
+    ("n" = #10) && (SliceGet byteT "y" #3 = #(U8 1)).
 
 (* data_structures.go *)
 
@@ -232,6 +609,107 @@ Definition getRandom: val :=
     let: "r" := rand.RandomUint64 #() in
     "r".
 
+(* decl_order.go *)
+
+Definition later := struct.decl [
+  "a" :: uint64T
+].
+
+Definition later__double: val :=
+  rec: "later__double" "l" :=
+    struct.get later "a" "l" * #2.
+
+Definition laterConst : expr := #3.
+
+Definition laterFunc: val :=
+  rec: "laterFunc" "l" :=
+    later__double "l" + laterConst.
+
+Definition useLater: val :=
+  rec: "useLater" <> :=
+    laterFunc (struct.mk later [
+      "a" ::= #2
+    ]).
+
+(* defer.go *)
+
+Definition namedResults: val :=
+  rec: "namedResults" <> :=
+    let: "x" := ref (zero_val uint64T) in
+    let: "ok" := ref (zero_val boolT) in
+    "x" <-[uint64T] #2;;
+    "ok" <-[boolT] #true;;
+    (![uint64T] "x", ![boolT] "ok").
+
+Definition deferIncrement: val :=
+  rec: "deferIncrement" <> :=
+    let: "n" := ref (zero_val uint64T) in
+    let: "$defer" := ref_to (unitT -> unitT)%ht (λ: <>, #()) in
+    let: "$f" := ![(unitT -> unitT)%ht] "$defer" in
+    "$defer" <-[(unitT -> unitT)%ht] (λ: <>,
+      (λ: <>,
+        "n" <-[uint64T] ((![uint64T] "n") + #1);;
+        #()
+        ) #();;
+      "$f" #()
+      );;
+    let: "$r0" := #5 in
+    "n" <-[uint64T] "$r0";;
+    (![(unitT -> unitT)%ht] "$defer") #();;
+    ![uint64T] "n".
+
+Definition deferUnlock: val :=
+  rec: "deferUnlock" "m" :=
+    let: "$defer" := ref_to (unitT -> unitT)%ht (λ: <>, #()) in
+    lock.acquire "m";;
+    let: "$f" := ![(unitT -> unitT)%ht] "$defer" in
+    "$defer" <-[(unitT -> unitT)%ht] (λ: <>,
+      lock.release "m";;
+      "$f" #()
+      );;
+    let: "$r0" := #1 in
+    (![(unitT -> unitT)%ht] "$defer") #();;
+    "$r0".
+
+Definition deferWithArgs: val :=
+  rec: "deferWithArgs" "s" :=
+    let: "$defer" := ref_to (unitT -> unitT)%ht (λ: <>, #()) in
+    let: "$a0" := #(str"dir") in
+    let: "$a1" := #(str"file") in
+    let: "$a2" := slice.nil in
+    let: "$f" := ![(unitT -> unitT)%ht] "$defer" in
+    "$defer" <-[(unitT -> unitT)%ht] (λ: <>,
+      atomicCreateStub "$a0" "$a1" "$a2";;
+      "$f" #()
+      );;
+    SliceSet uint64T "s" #0 #1;;
+    (![(unitT -> unitT)%ht] "$defer") #();;
+    #().
+
+Definition deferLog := struct.decl [
+  "entries" :: slice.T uint64T
+].
+
+Definition deferLog__append: val :=
+  rec: "deferLog__append" "l" "x" :=
+    struct.storeF deferLog "entries" "l" (SliceAppend uint64T (struct.loadF deferLog "entries" "l") "x");;
+    #().
+
+Definition deferMethodWithArgs: val :=
+  rec: "deferMethodWithArgs" "logs" "i" :=
+    let: "$defer" := ref_to (unitT -> unitT)%ht (λ: <>, #()) in
+    let: "j" := ref_to uint64T "i" in
+    let: "$recv" := SliceGet ptrT "logs" (![uint64T] "j") in
+    let: "$a0" := (![uint64T] "j") + #1 in
+    let: "$f" := ![(unitT -> unitT)%ht] "$defer" in
+    "$defer" <-[(unitT -> unitT)%ht] (λ: <>,
+      deferLog__append "$recv" "$a0";;
+      "$f" #()
+      );;
+    "j" <-[uint64T] #0;;
+    (![(unitT -> unitT)%ht] "$defer") #();;
+    #().
+
 (* disk.go *)
 
 Definition diskWrapper := struct.decl [
@@ -244,6 +722,67 @@ Definition diskArgument: val :=
     disk.Write #1 "b";;
     #().
 
+(* embedded.go *)
+
+Definition embedA := struct.decl [
+  "a" :: uint64T
+].
+
+Definition embedB := struct.decl [
+  "embedA" :: struct.t embedA;
+  "b" :: uint64T
+].
+
+Definition embedC := struct.decl [
+  "embedB" :: ptrT;
+  "c" :: uint64T
+].
+
+Definition readEmbedded: val :=
+  rec: "readEmbedded" "b" :=
+    struct.get embedA "a" (struct.get embedB "embedA" "b").
+
+Definition readPromoted: val :=
+  rec: "readPromoted" "b" :=
+    struct.get embedA "a" (struct.get embedB "embedA" "b").
+
+Definition readPromotedPtr: val :=
+  rec: "readPromotedPtr" "c" :=
+    struct.get embedA "a" (struct.loadF embedB "embedA" (struct.get embedC "embedB" "c")) + struct.loadF embedB "b" (struct.get embedC "embedB" "c").
+
+Definition embeddedLiteral: val :=
+  rec: "embeddedLiteral" <> :=
+    struct.mk embedB [
+      "embedA" ::= struct.mk embedA [
+        "a" ::= #1
+      ];
+      "b" ::= #2
+    ].
+
+Definition embedA__getA: val :=
+  rec: "embedA__getA" "a" :=
+    struct.get embedA "a" "a".
+
+Definition embedA__setA: val :=
+  rec: "embedA__setA" "a" "x" :=
+    struct.storeF embedA "a" "a" "x";;
+    #().
+
+Definition callPromoted: val :=
+  rec: "callPromoted" "b" :=
+    embedA__getA (struct.get embedB "embedA" "b").
+
+Definition callPromotedPtr: val :=
+  rec: "callPromotedPtr" "c" :=
+    embedA__setA (struct.fieldRef embedB "embedA" (struct.get embedC "embedB" "c")) #3;;
+    embedA__getA (struct.loadF embedB "embedA" (struct.get embedC "embedB" "c")).
+
+Definition callPromotedAddr: val :=
+  rec: "callPromotedAddr" <> :=
+    let: "b" := ref (zero_val (struct.t embedB)) in
+    embedA__setA (struct.fieldRef embedB "embedA" "b") #2;;
+    embedA__getA (struct.get embedB "embedA" (![struct.t embedB] "b")).
+
 (* empty_functions.go *)
 
 Definition empty: val :=
@@ -254,6 +793,20 @@ Definition emptyReturn: val :=
   rec: "emptyReturn" <> :=
     #().
 
+Definition ifNoElse: val :=
+  rec: "ifNoElse" "b" :=
+    (if: "b"
+    then
+      empty #();;
+      #()
+    else #()).
+
+Definition emptyBranches: val :=
+  rec: "emptyBranches" "b" :=
+    (if: "b"
+    then #()
+    else #()).
+
 (* encoding.go *)
 
 Definition Enc := struct.decl [
@@ -294,6 +847,80 @@ Definition Dec__UInt32: val :=
   rec: "Dec__UInt32" "d" :=
     UInt32Get (Dec__consume "d" #4).
 
+(* enum.go *)
+
+(* Color is an enum defined with a named type and constants *)
+Definition Color: ty := uint64T.
+
+Definition Red : expr := #0.
+
+Definition Green : expr := #1.
+
+Definition Blue : expr := #2.
+
+(* Level is an enum with a narrower underlying type *)
+Definition Level: ty := uint32T.
+
+Definition Low : expr := #(U32 1).
+
+Definition Mid : expr := #(U32 5).
+
+Definition High : expr := #(U32 10).
+
+Definition isWarm: val :=
+  rec: "isWarm" "c" :=
+    "c" = Red.
+
+Definition nextColor: val :=
+  rec: "nextColor" "c" :=
+    (if: "c" = Blue
+    then Red
+    else "c" + #1).
+
+Definition raise: val :=
+  rec: "raise" "l" :=
+    (if: "l" < High
+    then "l" + Low
+    else "l").
+
+Definition colorIndex: val :=
+  rec: "colorIndex" "c" :=
+    "c".
+
+Definition Purple : expr := #3.
+
+Definition Top : expr := #(U32 20).
+
+(* errors.go *)
+
+Definition ErrNotFound__init: val :=
+  rec: "ErrNotFound__init" <> :=
+    globals.put #(str"ErrNotFound") (ref_to errorT (SOME (ref_to stringT #(str"not found")))).
+
+Definition lookupEntry: val :=
+  rec: "lookupEntry" "m" "k" :=
+    let: ("v", "ok") := MapGet "m" "k" in
+    (if: ~ "ok"
+    then (#(str""), ![errorT] (globals.get #(str"ErrNotFound")))
+    else ("v", NONE)).
+
+Definition checkedDivErr: val :=
+  rec: "checkedDivErr" "x" "y" :=
+    (if: "y" = #0
+    then (#0, SOME (ref_to stringT ((#(str"division of ") + uint64_to_string "x") + #(str" by zero"))))
+    else ("x" `quot` "y", NONE)).
+
+Definition useLookup: val :=
+  rec: "useLookup" "m" :=
+    let: ("v", "err") := lookupEntry "m" #1 in
+    (if: "err" ≠ NONE
+    then match: "err" with NONE => Panic "nil error" | SOME "$msg" => ![stringT] "$msg" end
+    else "v").
+
+Definition isNotFound: val :=
+  rec: "isNotFound" "err" :=
+    "err" = (![errorT] (globals.get #(str"ErrNotFound"))).
+
 (* generics.go *)
 
 Definition genericId (T:ty): val :=
@@ -353,6 +980,38 @@ Definition useMapClear: val :=
     ]);;
     generic.MapLen (struct.t void) "m".
 
+(* globals.go *)
+
+(* globals that are only read are translated as definitions *)
+Definition defaultTimeout : expr := #100.
+
+Definition verbose : expr := zero_val boolT.
+
+Definition timeout: val :=
+  rec: "timeout" <> :=
+    (if: verbose
+    then #2 * defaultTimeout
+    else defaultTimeout).
+
+(* globals that are modified are translated as locations *)
+Definition requests__init: val :=
+  rec: "requests__init" <> :=
+    globals.put #(str"requests") (ref_to uint64T (zero_val uint64T)).
+
+Definition lastRequest__init: val :=
+  rec: "lastRequest__init" <> :=
+    globals.put #(str"lastRequest") (ref_to stringT #(str"none")).
+
+Definition recordRequest: val :=
+  rec: "recordRequest" "name" :=
+    (globals.get #(str"requests")) <-[uint64T] ((![uint64T] (globals.get #(str"requests"))) + #1);;
+    (globals.get #(str"lastRequest")) <-[stringT] "name";;
+    ![uint64T] (globals.get #(str"requests")).
+
+Definition requestCounter: val :=
+  rec: "requestCounter" <> :=
+    globals.get #(str"requests").
+
 (* higher_order.go *)
 
 Definition TakesFunctionType: val :=
@@ -360,6 +1019,139 @@ Definition TakesFunctionType: val :=
     "f" #();;
     #().
 
+Definition hasFunctionFields := struct.decl [
+  "thunk" :: (unitT -> uint64T)%ht;
+  "nested" :: ((unitT -> uint64T)%ht -> uint64T)%ht
+].
+
+Definition nestedFunctionType: ty := ((unitT -> uint64T)%ht -> uint64T)%ht.
+
+Definition callFunctionParam: val :=
+  rec: "callFunctionParam" "f" "x" :=
+    "f" "x" #true + #1.
+
+Definition makeAdder: val :=
+  rec: "makeAdder" "n" :=
+    (λ: "x", "x" + "n").
+
+Definition callReturnedFunction: val :=
+  rec: "callReturnedFunction" <> :=
+    (makeAdder #2) #3.
+
+Definition callFunctionSlice: val :=
+  rec: "callFunctionSlice" "fs" "i" :=
+    (SliceGet (unitT -> uint64T)%ht "fs" "i") #().
+
+Definition callFunctionLiteral: val :=
+  rec: "callFunctionLiteral" <> :=
+    (λ: "x", "x" * #2) #4.
+
+(* the type of f comes from the type checker rather than a Go type expression *)
+Definition callThroughPointer: val :=
+  rec: "callThroughPointer" "p" "x" :=
+    let: "f" := ![(uint64T -> boolT)%ht] "p" in
+    "f" "x".
+
+(* interfaces.go *)
+
+Definition counter := struct.decl [
+  "$type" :: stringT;
+  "get" :: (unitT -> uint64T)%ht;
+  "add" :: (uint64T -> uint64T)%ht
+].
+
+Definition fixedCounter := struct.decl [
+  "n" :: uint64T
+].
+
+Definition fixedCounter__get: val :=
+  rec: "fixedCounter__get" "c" :=
+    struct.get fixedCounter "n" "c".
+
+Definition fixedCounter__add: val :=
+  rec: "fixedCounter__add" "c" "n" :=
+    struct.get fixedCounter "n" "c" + "n".
+
+Definition doubleCounter := struct.decl [
+  "n" :: uint64T
+].
+
+Definition doubleCounter__get: val :=
+  rec: "doubleCounter__get" "c" :=
+    #2 * struct.get doubleCounter "n" "c".
+
+Definition doubleCounter__add: val :=
+  rec: "doubleCounter__add" "c" "n" :=
+    #2 * (struct.get doubleCounter "n" "c" + "n").
+
+Definition counterHolder := struct.decl [
+  "c" :: struct.t counter
+].
+
+Definition useCounter: val :=
+  rec: "useCounter" "c" :=
+    (struct.get counter "add" "c") ((struct.get counter "get" "c") #()).
+
+Definition fixedCounter__to__counter: val :=
+  rec: "fixedCounter__to__counter" "t" :=
+    struct.mk counter [
+      "$type" ::= #(str"fixedCounter");
+      "add" ::= fixedCounter__add "t";
+      "get" ::= (λ: <>, fixedCounter__get "t")
+    ].
+
+Definition newCounter: val :=
+  rec: "newCounter" <> :=
+    fixedCounter__to__counter (struct.mk fixedCounter [
+      "n" ::= #1
+    ]).
+
+Definition doubleCounter__to__counter: val :=
+  rec: "doubleCounter__to__counter" "t" :=
+    struct.mk counter [
+      "$type" ::= #(str"doubleCounter");
+      "add" ::= doubleCounter__add "t";
+      "get" ::= (λ: <>, doubleCounter__get "t")
+    ].
+
+Definition counterConversions: val :=
+  rec: "counterConversions" <> :=
+    let: "c" := ref_to (struct.t counter) (fixedCounter__to__counter (struct.mk fixedCounter [
+      "n" ::= #2
+    ])) in
+    "c" <-[struct.t counter] (doubleCounter__to__counter (struct.mk doubleCounter [
+      "n" ::= #3
+    ]));;
+    let: "h" := struct.mk counterHolder [
+      "c" ::= fixedCounter__to__counter (struct.mk fixedCounter [
+        "n" ::= #4
+      ])
+    ] in
+    (useCounter (![struct.t counter] "c") + useCounter (struct.get counterHolder "c" "h")) + useCounter (newCounter #()).
+
+Definition counterOrZero: val :=
+  rec: "counterOrZero" "c" :=
+    (if: struct.get counter "$type" "c" = #(str"")
+    then #0
+    else (struct.get counter "get" "c") #()).
+
+Definition noCounter: val :=
+  rec: "noCounter" <> :=
+    zero_val (struct.t counter).
+
+Definition conditionalDefInterface: val :=
+  rec: "conditionalDefInterface" "x" "n" :=
+    let: "c" := (if: "x"
+      then
+        fixedCounter__to__counter (struct.mk fixedCounter [
+          "n" ::= "n"
+        ])
+      else
+        doubleCounter__to__counter (struct.mk doubleCounter [
+          "n" ::= "n"
+        ])) in
+    "c".
+
 (* ints.go *)
 
 Definition useInts: val :=
@@ -377,6 +1169,10 @@ Definition also_u32: ty := my_u32.
 
 Definition ConstWithAbbrevType : expr := #(U32 3).
 
+Definition MaxUint32 : expr := #(U32 4294967295).
+
+Definition MaxByte : expr := #(U8 255).
+
 (* literals.go *)
 
 Definition allTheLiterals := struct.decl [
@@ -409,6 +1205,30 @@ Definition oddLiterals: val :=
       "b" ::= #false
     ].
 
+Definition literalValue: val :=
+  rec: "literalValue" <> :=
+    struct.mk allTheLiterals [
+      "int" ::= #1;
+      "s" ::= #(str"value");
+      "b" ::= #true
+    ].
+
+Definition literalAllocation: val :=
+  rec: "literalAllocation" <> :=
+    struct.new allTheLiterals [
+      "int" ::= #2;
+      "s" ::= #(str"allocated");
+      "b" ::= #false
+    ].
+
+Definition sliceLiteralRef: val :=
+  rec: "sliceLiteralRef" "x" :=
+    ref_to (slice.T uint64T) (SliceSingleton "x").
+
+Definition arrayLiteralRef: val :=
+  rec: "arrayLiteralRef" "x" :=
+    ref_to (arrayT uint64T) (array.mk uint64T ["x"; "x"]).
+
 (* locks.go *)
 
 Definition useLocks: val :=
@@ -458,7 +1278,7 @@ Definition standardForLoop: val :=
     let: "sumPtr" := ref (zero_val uint64T) in
     let: "i" := ref_to uint64T #0 in
     (for: (λ: <>, #true); (λ: <>, Skip) := λ: <>,
-      (if: (![uint64T] "i") < (slice.len "s")
+      (if: (![uint64T] "i") < slice.len "s"
       then
         let: "sum" := ![uint64T] "sumPtr" in
         let: "x" := SliceGet uint64T "s" (![uint64T] "i") in
@@ -532,7 +1352,6 @@ Definition ImplicitLoopContinue2: val :=
 
 Definition ImplicitLoopContinueAfterIfBreak: val :=
   rec: "ImplicitLoopContinueAfterIfBreak" "i" :=
-    Skip;;
     (for: (λ: <>, #true); (λ: <>, Skip) := λ: <>,
       (if: "i" > #0
       then Break
@@ -575,13 +1394,50 @@ Definition sumSlice: val :=
 
 Definition breakFromLoop: val :=
   rec: "breakFromLoop" <> :=
-    Skip;;
     (for: (λ: <>, #true); (λ: <>, Skip) := λ: <>,
       (if: #true
       then Break
       else Continue));;
     #().
 
+Definition breakSkipsRest: val :=
+  rec: "breakSkipsRest" "n" :=
+    let: "x" := ref_to uint64T #0 in
+    (for: (λ: <>, #true); (λ: <>, Skip) := λ: <>,
+      (if: (![uint64T] "x") ≥ "n"
+      then Break
+      else
+        "x" <-[uint64T] ((![uint64T] "x") + #1);;
+        Continue));;
+    ![uint64T] "x".
+
+Definition explicitTrailingContinue: val :=
+  rec: "explicitTrailingContinue" "n" :=
+    let: "x" := ref_to uint64T #0 in
+    (for: (λ: <>, #true); (λ: <>, Skip) := λ: <>,
+      (if: (![uint64T] "x") ≥ "n"
+      then Break
+      else
+        "x" <-[uint64T] ((![uint64T] "x") + #1);;
+        Continue));;
+    ![uint64T] "x".
+
+Definition infiniteLoopBreak: val :=
+  rec: "infiniteLoopBreak" "c" :=
+    (for: (λ: <>, #true); (λ: <>, Skip) := λ: <>,
+      (if: ![boolT] "c"
+      then Break
+      else Continue));;
+    #().
+
+Definition whileLoop: val :=
+  rec: "whileLoop" "n" :=
+    let: "x" := ref_to uint64T #0 in
+    (for: (λ: <>, (![uint64T] "x") < "n"); (λ: <>, Skip) := λ: <>,
+      "x" <-[uint64T] ((![uint64T] "x") + #1);;
+      Continue);;
+    ![uint64T] "x".
+
 (* maps.go *)
 
 Definition clearMap: val :=
@@ -613,6 +1469,63 @@ Definition StringMap: val :=
   rec: "StringMap" "m" :=
     Fst (MapGet "m" #(str"foo")).
 
+Definition mapAsSet: val :=
+  rec: "mapAsSet" "keys" "k" :=
+    let: "s" := NewMap uint64T boolT #() in
+    ForSlice uint64T <> "key" "keys"
+      (MapInsert "s" "key" #true);;
+    (if: Fst (MapGet "s" "k")
+    then #true
+    else #false).
+
+Definition deleteKey: val :=
+  rec: "deleteKey" "m" "k" :=
+    MapDelete "m" "k";;
+    Fst (MapGet "m" "k").
+
+Definition deleteFromWrapper: val :=
+  rec: "deleteFromWrapper" "m" :=
+    MapDelete "m" #1;;
+    #().
+
+Definition mapLookupOk: val :=
+  rec: "mapLookupOk" "m" "k" :=
+    let: ("v", "ok") := MapGet "m" "k" in
+    ("v", "ok").
+
+Definition mapLookupValue: val :=
+  rec: "mapLookupValue" "m" "k" :=
+    let: "v" := Fst (MapGet "m" "k") in
+    "v".
+
+Definition mapLookupAssign: val :=
+  rec: "mapLookupAssign" "m" "k" :=
+    let: "v" := ref (zero_val uint64T) in
+    let: "ok" := ref (zero_val boolT) in
+    let: ("0_ret", "1_ret") := MapGet "m" "k" in
+    "v" <-[uint64T] "0_ret";;
+    "ok" <-[boolT] "1_ret";;
+    (![boolT] "ok") && ((![uint64T] "v") > #0).
+
+Definition emptyMapLiteral: val :=
+  rec: "emptyMapLiteral" <> :=
+    NewMap uint64T uint64T #().
+
+Definition mapLiteral: val :=
+  rec: "mapLiteral" <> :=
+    (let: "$m" := NewMap uint64T boolT #() in
+     MapInsert "$m" #1 #true;;
+     MapInsert "$m" #3 #false;;
+     "$m").
+
+Definition nestedMapLiteral: val :=
+  rec: "nestedMapLiteral" <> :=
+    (let: "$m" := NewMap uint64T (mapT uint64T) #() in
+     MapInsert "$m" #0 (let: "$m" := NewMap stringT uint64T #() in
+      MapInsert "$m" #(str"a") #1;;
+      "$m");;
+     "$m").
+
 (* multiple.go *)
 
 Definition returnTwo: val :=
@@ -628,6 +1541,91 @@ Definition multipleVar: val :=
   rec: "multipleVar" "x" "y" :=
     #().
 
+Definition swapValues: val :=
+  rec: "swapValues" "p" "q" :=
+    let: "0_ret" := ![uint64T] "q" in
+    let: "1_ret" := ![uint64T] "p" in
+    "p" <-[uint64T] "0_ret";;
+    "q" <-[uint64T] "1_ret";;
+    #().
+
+(* mutex.go *)
+
+Definition counterWithLock := struct.decl [
+  "mu" :: ptrT;
+  "count" :: uint64T
+].
+
+Definition newCounterWithLock: val :=
+  rec: "newCounterWithLock" <> :=
+    struct.new counterWithLock [
+      "mu" ::= lock.new #()
+    ].
+
+Definition counterWithLock__increment: val :=
+  rec: "counterWithLock__increment" "c" :=
+    lock.acquire (struct.loadF counterWithLock "mu" "c");;
+    struct.storeF counterWithLock "count" "c" (struct.loadF counterWithLock "count" "c" + #1);;
+    lock.release (struct.loadF counterWithLock "mu" "c");;
+    #().
+
+Definition counterWithLock__lockRef: val :=
+  rec: "counterWithLock__lockRef" "c" :=
+    struct.loadF counterWithLock "mu" "c".
+
+Definition allocMutex: val :=
+  rec: "allocMutex" <> :=
+    let: "mu" := lock.new #() in
+    lock.acquire "mu";;
+    lock.release "mu";;
+    #().
+
+(* named_types.go *)
+
+(* Bytes is a named slice type. *)
+Definition Bytes: ty := slice.T byteT.
+
+(* Counts maps keys to counts. *)
+Definition Counts: ty := mapT uint64T.
+
+Definition newBytes: val :=
+  rec: "newBytes" "n" :=
+    NewSlice byteT "n".
+
+Definition firstByte: val :=
+  rec: "firstByte" "b" :=
+    SliceGet byteT "b" #0.
+
+Definition countOf: val :=
+  rec: "countOf" "c" "k" :=
+    Fst (MapGet "c" "k").
+
+Definition Name: ty := stringT.
+
+Definition toColor: val :=
+  rec: "toColor" "x" :=
+    "x".
+
+Definition fromColor: val :=
+  rec: "fromColor" "c" :=
+    "c".
+
+Definition levelToColor: val :=
+  rec: "levelToColor" "l" :=
+    to_u64 "l".
+
+Definition toBytes: val :=
+  rec: "toBytes" "b" :=
+    "b".
+
+Definition fromBytes: val :=
+  rec: "fromBytes" "b" :=
+    "b".
+
+Definition nameLength: val :=
+  rec: "nameLength" "n" :=
+    StringLength "n".
+
 (* nil.go *)
 
 Definition AssignNilSlice: val :=
@@ -652,6 +1650,15 @@ Definition ComparePointerToNil: val :=
     let: "s" := ref (zero_val uint64T) in
     "s" ≠ #null.
 
+Definition CompareNilToPointer: val :=
+  rec: "CompareNilToPointer" <> :=
+    let: "s" := ref (zero_val uint64T) in
+    "s" = #null.
+
+Definition CompareNilToError: val :=
+  rec: "CompareNilToError" "err" :=
+    "err" ≠ NONE.
+
 (* operators.go *)
 
 Definition LogicalOperators: val :=
@@ -664,29 +1671,25 @@ Definition LogicalAndEqualityOperators: val :=
 
 Definition ArithmeticShifts: val :=
   rec: "ArithmeticShifts" "x" "y" :=
-    ((to_u64 ("x" ≪ #3)) + ("y" ≪ (to_u64 "x"))) + ("y" ≪ #1).
+    (to_u64 ("x" ≪ #3) + ("y" ≪ to_u64 "x")) + ("y" ≪ #1).
 
 Definition BitwiseOps: val :=
   rec: "BitwiseOps" "x" "y" :=
-    (to_u64 "x") `or` ((to_u64 (to_u32 "y")) `and` #43).
+    to_u64 "x" `or` (to_u64 (to_u32 "y") `and` #43).
 
 Definition Comparison: val :=
   rec: "Comparison" "x" "y" :=
     (if: "x" < "y"
     then #true
-    else
-      (if: "x" = "y"
-      then #true
-      else
-        (if: "x" ≠ "y"
-        then #true
-        else
-          (if: "x" > "y"
-          then #true
-          else
-            (if: ("x" + #1) > ("y" - #2)
-            then #true
-            else #false))))).
+    else (if: "x" = "y"
+    then #true
+    else (if: "x" ≠ "y"
+    then #true
+    else (if: "x" > "y"
+    then #true
+    else (if: ("x" + #1) > ("y" - #2)
+    then #true
+    else #false))))).
 
 Definition AssignOps: val :=
   rec: "AssignOps" <> :=
@@ -697,6 +1700,70 @@ Definition AssignOps: val :=
     "x" <-[uint64T] ((![uint64T] "x") - #1);;
     #().
 
+Definition NotEqualsInts: val :=
+  rec: "NotEqualsInts" "x" "y" :=
+    ("x" ≠ #0) && ("y" ≠ #(U32 1)).
+
+Definition NotEqualsPointers: val :=
+  rec: "NotEqualsPointers" "p" "q" :=
+    ("p" ≠ #null) && ("p" ≠ "q").
+
+Definition NotEqualsStrings: val :=
+  rec: "NotEqualsStrings" "s" :=
+    ("s" ≠ #(str"")) && ("s" ≠ #(str"x")).
+
+Definition counterFields := struct.decl [
+  "hits" :: uint64T;
+  "small" :: uint32T
+].
+
+Definition IncDecFields: val :=
+  rec: "IncDecFields" "c" :=
+    struct.storeF counterFields "hits" "c" (struct.loadF counterFields "hits" "c" + #1);;
+    struct.storeF counterFields "small" "c" (struct.loadF counterFields "small" "c" - #(U32 1));;
+    #().
+
+Definition IncDecLocals: val :=
+  rec: "IncDecLocals" <> :=
+    let: "x" := ref (zero_val uint32T) in
+    "x" <-[uint32T] ((![uint32T] "x") + #(U32 1));;
+    "x" <-[uint32T] ((![uint32T] "x") + #(U32 1));;
+    "x" <-[uint32T] ((![uint32T] "x") - #(U32 1));;
+    ![uint32T] "x".
+
+Definition CompoundAssignOps: val :=
+  rec: "CompoundAssignOps" "x" :=
+    let: "y" := ref_to uint64T #6 in
+    "y" <-[uint64T] ((![uint64T] "y") * "x");;
+    "y" <-[uint64T] ((![uint64T] "y") `quot` #2);;
+    "y" <-[uint64T] ((![uint64T] "y") `rem` #100);;
+    "y" <-[uint64T] ((![uint64T] "y") `xor` #1);;
+    "y" <-[uint64T] ((![uint64T] "y") `and` #255);;
+    "y" <-[uint64T] ((![uint64T] "y") ≪ #2);;
+    "y" <-[uint64T] ((![uint64T] "y") ≫ #1);;
+    ![uint64T] "y".
+
+Definition flags := struct.decl [
+  "bits" :: uint32T
+].
+
+Definition flags__set: val :=
+  rec: "flags__set" "f" "b" :=
+    struct.storeF flags "bits" "f" (struct.loadF flags "bits" "f" `or` "b");;
+    #().
+
+Definition BoolEquality: val :=
+  rec: "BoolEquality" "b1" "b2" :=
+    ("b1" = "b2") || ("b1" ≠ (~ "b2")).
+
+Definition CompoundAssignIndexCall: val :=
+  rec: "CompoundAssignIndexCall" "s" "m" "ch" :=
+    let: "$lhs0" := slice.len "s" - #1 in
+    SliceSet uint64T "s" "$lhs0" (SliceGet uint64T "s" "$lhs0" + #2);;
+    let: "$lhs0" := Fst (chan.receive "ch") in
+    MapInsert "m" "$lhs0" (Fst (MapGet "m" "$lhs0") * #3);;
+    #().
+
 (* package.go *)
 
 (* unittest has two package comments *)
@@ -718,6 +1785,48 @@ Definition PanicAtTheDisco: val :=
     Panic "disco";;
     #().
 
+Definition unreachable : expr := #(str"unreachable").
+
+Definition checkedDiv: val :=
+  rec: "checkedDiv" "x" "y" :=
+    (if: "y" ≠ #0
+    then "x" `quot` "y"
+    else
+      Panic "unreachable";;
+      #()).
+
+Definition maxValue : expr := #10.
+
+Definition panicFormatted: val :=
+  rec: "panicFormatted" "x" :=
+    (if: "x" > maxValue
+    then
+      Panic "value above 10";;
+      #()
+    else #()).
+
+Definition panicFormattedArg: val :=
+  rec: "panicFormattedArg" "x" :=
+    (if: "x" > maxValue
+    then
+      Panic "value %d above maximum";;
+      #()
+    else #()).
+
+Definition assertPositive: val :=
+  rec: "assertPositive" "x" :=
+    control.impl.Assert ("x" > #0);;
+    "x" - #1.
+
+(* prims.go *)
+
+(* Linearize is a value in the model, while RandomUint64 is a function that is
+   applied to #() *)
+Definition linearizeRandom: val :=
+  rec: "linearizeRandom" <> :=
+    Linearize;;
+    rand.RandomUint64 #().
+
 (* proph.go *)
 
 Definition Oracle: val :=
@@ -754,6 +1863,45 @@ Definition ReassignVars: val :=
     "x" <-[uint64T] (struct.get composite "a" (![struct.t composite] "z"));;
     #().
 
+Definition discardPureVar: val :=
+  rec: "discardPureVar" "x" :=
+    Skip;;
+    #1.
+
+Definition discardCall: val :=
+  rec: "discardCall" <> :=
+    struct.mk composite [
+      "a" ::= #1;
+      "b" ::= #2
+    ];;
+    discardPureVar #2;;
+    #().
+
+(* receivers.go *)
+
+Definition recv := struct.decl [
+  "n" :: uint64T
+].
+
+Definition recv__get: val :=
+  rec: "recv__get" "r" :=
+    struct.get recv "n" "r".
+
+Definition recv__set: val :=
+  rec: "recv__set" "r" "n" :=
+    struct.storeF recv "n" "r" "n";;
+    #().
+
+Definition valueOnPointer: val :=
+  rec: "valueOnPointer" "p" :=
+    recv__get (struct.load recv "p").
+
+Definition pointerOnValue: val :=
+  rec: "pointerOnValue" <> :=
+    let: "r" := ref (zero_val (struct.t recv)) in
+    recv__set "r" #3;;
+    recv__get (![struct.t recv] "r").
+
 (* replicated_disk.go *)
 
 Definition Block := struct.decl [
@@ -826,6 +1974,103 @@ Definition ReplicatedDiskRecover: val :=
         Continue));;
     #().
 
+(* reserved.go *)
+
+Definition match' : expr := #2.
+
+Definition interval := struct.decl [
+  "in" :: uint64T;
+  "end" :: uint64T
+].
+
+Definition end': val :=
+  rec: "end'" "i" :=
+    struct.get interval "end" "i".
+
+Definition contains: val :=
+  rec: "contains" "i" "x" :=
+    (struct.get interval "in" "i" ≤ "x") && ("x" < end' "i").
+
+Definition doubleMatch: val :=
+  rec: "doubleMatch" <> :=
+    match' * #2.
+
+(* rwmutex.go *)
+
+Definition cache := struct.decl [
+  "mu" :: ptrT;
+  "entries" :: mapT stringT
+].
+
+Definition newCache: val :=
+  rec: "newCache" <> :=
+    struct.new cache [
+      "entries" ::= NewMap uint64T stringT #();
+      "mu" ::= rwlock.new #()
+    ].
+
+Definition cache__get: val :=
+  rec: "cache__get" "c" "k" :=
+    rwlock.read_acquire (struct.loadF cache "mu" "c");;
+    let: "v" := Fst (MapGet (struct.loadF cache "entries" "c") "k") in
+    rwlock.read_release (struct.loadF cache "mu" "c");;
+    "v".
+
+Definition cache__put: val :=
+  rec: "cache__put" "c" "k" "v" :=
+    rwlock.write_acquire (struct.loadF cache "mu" "c");;
+    MapInsert (struct.loadF cache "entries" "c") "k" "v";;
+    rwlock.write_release (struct.loadF cache "mu" "c");;
+    #().
+
+Definition readThenWrite: val :=
+  rec: "readThenWrite" "l" :=
+    rwlock.read_acquire "l";;
+    rwlock.read_release "l";;
+    rwlock.write_acquire "l";;
+    rwlock.write_release "l";;
+    #().
+
+Definition allocRWMutex: val :=
+  rec: "allocRWMutex" <> :=
+    let: "l" := rwlock.new #() in
+    readThenWrite "l";;
+    #().
+
+(* select.go *)
+
+Definition tryReceive: val :=
+  rec: "tryReceive" "ch" :=
+    let: "x" := ref (zero_val uint64T) in
+    chan.select_nonblocking [
+      chan.select_receive "ch" (λ: "v" <>, "x" <-[uint64T] "v")
+    ] (λ: <>, "x" <-[uint64T] #0);;
+    ![uint64T] "x".
+
+Definition sendOrReceive: val :=
+  rec: "sendOrReceive" "in" "out" :=
+    let: "closed" := ref_to boolT #false in
+    chan.select_blocking [
+      chan.select_send "out" #1 (λ: <>, #());
+      chan.select_receive "in" (λ: <> "ok", "closed" <-[boolT] (~ "ok"))
+    ];;
+    ![boolT] "closed".
+
+Definition receiveEither: val :=
+  rec: "receiveEither" "a" "b" :=
+    let: "which" := ref (zero_val uint64T) in
+    chan.select_nonblocking [
+      chan.select_receive "a" (λ: <> <>, "which" <-[uint64T] #1);
+      chan.select_receive "b" (λ: <> <>, "which" <-[uint64T] #2)
+    ] (λ: <>, #());;
+    ![uint64T] "which".
+
+(* skip.go *)
+
+Definition notSkipped: val :=
+  rec: "notSkipped" <> :=
+    #1.
+
 (* slices.go *)
 
 Definition SliceAlias: ty := slice.T boolT.
@@ -837,7 +2082,7 @@ Definition sliceOps: val :=
     let: "v2" := SliceSubslice uint64T "x" #2 #3 in
     let: "v3" := SliceTake "x" #3 in
     let: "v4" := SliceRef uint64T "x" #2 in
-    (((("v1" + (SliceGet uint64T "v2" #0)) + (SliceGet uint64T "v3" #1)) + (![uint64T] "v4")) + (slice.len "x")) + (slice.cap "x").
+    (((("v1" + SliceGet uint64T "v2" #0) + SliceGet uint64T "v3" #1) + (![uint64T] "v4")) + slice.len "x") + slice.cap "x".
 
 Definition makeSingletonSlice: val :=
   rec: "makeSingletonSlice" "x" :=
@@ -859,6 +2104,49 @@ Definition makeAlias: val :=
   rec: "makeAlias" <> :=
     NewSlice boolT #10.
 
+Definition doubleInPlace: val :=
+  rec: "doubleInPlace" "s" :=
+    ForSlice uint64T "i" <> "s"
+      (SliceSet uint64T "s" "i" (#2 * SliceGet uint64T "s" "i"));;
+    #().
+
+Definition moreElements: val :=
+  rec: "moreElements" "n" :=
+    NewSlice uint64T "n".
+
+Definition appendCallResult: val :=
+  rec: "appendCallResult" "s" :=
+    SliceAppendSlice uint64T "s" (moreElements #2).
+
+Definition emptySliceLiteral: val :=
+  rec: "emptySliceLiteral" <> :=
+    NewSlice uint64T #0.
+
+Definition sliceLiteral: val :=
+  rec: "sliceLiteral" "x" :=
+    (let: "$s" := NewSlice uint64T #3 in
+     SliceSet uint64T "$s" #0 #1;;
+     SliceSet uint64T "$s" #1 "x";;
+     SliceSet uint64T "$s" #2 #3;;
+     "$s").
+
+Definition sparseSliceLiteral: val :=
+  rec: "sparseSliceLiteral" "x" :=
+    (let: "$s" := NewSlice uint64T #3 in
+     SliceSet uint64T "$s" #2 "x";;
+     SliceSet uint64T "$s" #0 #1;;
+     "$s").
+
+Definition aliasElementRef: val :=
+  rec: "aliasElementRef" "s" "i" :=
+    SliceRef boolT "s" "i".
+
+Definition setThroughElementRef: val :=
+  rec: "setThroughElementRef" "s" :=
+    let: "p" := SliceRef uint64T "s" #0 in
+    "p" <-[uint64T] #1;;
+    #().
+
 (* spawn.go *)
 
 (* Skip is a placeholder for some impure code *)
@@ -898,16 +2186,182 @@ Definition loopSpawn: val :=
       Continue);;
     #().
 
+Definition rangeSpawn: val :=
+  rec: "rangeSpawn" "s" :=
+    ForSlice uint64T <> "x" "s"
+      (let: "x" := "x" in
+      Fork (threadCode "x"));;
+    #().
+
+Definition spawnWithArgs: val :=
+  rec: "spawnWithArgs" <> :=
+    let: "x" := ref_to uint64T #1 in
+    let: "$a0" := ![uint64T] "x" in
+    Fork (threadCode "$a0");;
+    "x" <-[uint64T] #2;;
+    let: "$a0" := ![uint64T] "x" in
+    let: "$a1" := #3 in
+    Fork ((λ: "a" "b",
+            threadCode ("a" + "b");;
+            #()
+            ) "$a0" "$a1");;
+    #().
+
+Definition spawnCapture: val :=
+  rec: "spawnCapture" "l" :=
+    let: "count" := ref (zero_val uint64T) in
+    let: "x" := #5 in
+    Fork (lock.acquire "l";;
+          "count" <-[uint64T] ((![uint64T] "count") + "x");;
+          lock.release "l");;
+    Fork ((if: "x" = #0
+          then #()
+          else
+            threadCode "x";;
+            #()));;
+    #().
+
+Definition spawnWorker := struct.decl [
+  "id" :: uint64T
+].
+
+Definition spawnWorker__run: val :=
+  rec: "spawnWorker__run" "w" "n" :=
+    threadCode (struct.get spawnWorker "id" "w" + "n");;
+    #().
+
+(* the receiver and arguments are evaluated before the thread starts *)
+Definition spawnMethod: val :=
+  rec: "spawnMethod" "ws" "i" :=
+    let: "$recv" := SliceGet (struct.t spawnWorker) "ws" "i" in
+    let: "$a0" := "i" + #1 in
+    Fork (spawnWorker__run "$recv" "$a0");;
+    #().
+
+Definition spawnPackageFunction: val :=
+  rec: "spawnPackageFunction" "x" :=
+    let: "$a0" := "x" in
+    Fork (generic.Id uint64T "$a0");;
+    #().
+
+(* sprintf.go *)
+
+Definition formatInt: val :=
+  rec: "formatInt" "x" :=
+    #(str"x = ") + uint64_to_string "x".
+
+Definition formatMixed: val :=
+  rec: "formatMixed" "name" "n" :=
+    (("name" + #(str" has ")) + uint64_to_string (to_u64 "n")) + #(str" items (100%)").
+
 (* strings.go *)
 
 Definition stringAppend: val :=
   rec: "stringAppend" "s" "x" :=
-    ((#(str"prefix ") + "s") + #(str" ")) + (uint64_to_string "x").
+    ((#(str"prefix ") + "s") + #(str" ")) + uint64_to_string "x".
 
 Definition stringLength: val :=
   rec: "stringLength" "s" :=
     StringLength "s".
 
+Definition joinStrings: val :=
+  rec: "joinStrings" "pieces" "sep" :=
+    let: "out" := ref_to stringT #(str"") in
+    ForSlice stringT "i" "p" "pieces"
+      ((if: "i" > #0
+      then "out" <-[stringT] ((![stringT] "out") + "sep")
+      else #());;
+      "out" <-[stringT] ((![stringT] "out") + "p"));;
+    ![stringT] "out".
+
+Definition repeatString: val :=
+  rec: "repeatString" "s" "n" :=
+    let: "out" := ref_to stringT #(str"") in
+    let: "i" := ref_to uint64T #0 in
+    (for: (λ: <>, (![uint64T] "i") < "n"); (λ: <>, "i" <-[uint64T] ((![uint64T] "i") + #1)) := λ: <>,
+      "out" <-[stringT] ((![stringT] "out") + "s");;
+      Continue);;
+    ![stringT] "out".
+
+Definition stringsEqual: val :=
+  rec: "stringsEqual" "s1" "s2" :=
+    "s1" = "s2".
+
+Definition stringNotLiteral: val :=
+  rec: "stringNotLiteral" "s" :=
+    "s" ≠ #(str"foo").
+
+Definition concatWrapper: val :=
+  rec: "concatWrapper" "s" :=
+    "s" + #(str"!").
+
+Definition stringFirstByte: val :=
+  rec: "stringFirstByte" "s" :=
+    StringGet "s" #0.
+
+Definition stringByteAt: val :=
+  rec: "stringByteAt" "s" "i" :=
+    to_u64 (StringGet "s" "i").
+
+(* struct_compare.go *)
+
+Definition point := struct.decl [
+  "x" :: uint64T;
+  "y" :: uint64T
+].
+
+Definition segment := struct.decl [
+  "start" :: struct.t point;
+  "end" :: struct.t point;
+  "label" :: stringT
+].
+
+Definition pointsEqual: val :=
+  rec: "pointsEqual" "p" "q" :=
+    (struct.get point "x" "p" = struct.get point "x" "q") && (struct.get point "y" "p" = struct.get point "y" "q").
+
+Definition pointsDiffer: val :=
+  rec: "pointsDiffer" "p" "q" :=
+    ~ ((struct.get point "x" "p" = struct.get point "x" "q") && (struct.get point "y" "p" = struct.get point "y" "q")).
+
+Definition segmentsEqual: val :=
+  rec: "segmentsEqual" "s1" "s2" :=
+    (((struct.get point "x" (struct.get segment "start" "s1") = struct.get point "x" (struct.get segment "start" "s2")) && (struct.get point "y" (struct.get segment "start" "s1") = struct.get point "y" (struct.get segment "start" "s2"))) && ((struct.get point "x" (struct.get segment "end" "s1") = struct.get point "x" (struct.get segment "end" "s2")) && (struct.get point "y" (struct.get segment "end" "s1") = struct.get point "y" (struct.get segment "end" "s2")))) && (struct.get segment "label" "s1" = struct.get segment "label" "s2").
+
+Definition isOrigin: val :=
+  rec: "isOrigin" "p" :=
+    let: "$x" := struct.load point "p" in
+    let: "$y" := struct.mk point [
+      "x" ::= #0;
+      "y" ::= #0
+    ] in
+    (struct.get point "x" "$x" = struct.get point "x" "$y") && (struct.get point "y" "$x" = struct.get point "y" "$y").
+
+Definition makePoint: val :=
+  rec: "makePoint" "x" :=
+    struct.mk point [
+      "x" ::= "x";
+      "y" ::= "x"
+    ].
+
+Definition comparesCall: val :=
+  rec: "comparesCall" "p" :=
+    let: "$x" := makePoint (struct.get point "x" "p") in
+    (struct.get point "x" "$x" = struct.get point "x" "p") && (struct.get point "y" "$x" = struct.get point "y" "p").
+
+Definition switchOnPoint: val :=
+  rec: "switchOnPoint" "p" :=
+    let: "$tag" := "p" in
+    (if: (let: "$y" := struct.mk point [
+    ] in
+    (struct.get point "x" "$tag" = struct.get point "x" "$y") && (struct.get point "y" "$tag" = struct.get point "y" "$y"))
+    then #0
+    else (if: (let: "$y" := makePoint #1 in
+    (struct.get point "x" "$tag" = struct.get point "x" "$y") && (struct.get point "y" "$tag" = struct.get point "y" "$y")) || (let: "$y" := makePoint #2 in
+    (struct.get point "x" "$tag" = struct.get point "x" "$y") && (struct.get point "y" "$tag" = struct.get point "y" "$y"))
+    then #1
+    else #2)).
+
 (* struct_method.go *)
 
 Definition Point := struct.decl [
@@ -917,7 +2371,7 @@ Definition Point := struct.decl [
 
 Definition Point__Add: val :=
   rec: "Point__Add" "c" "z" :=
-    ((struct.get Point "x" "c") + (struct.get Point "y" "c")) + "z".
+    (struct.get Point "x" "c" + struct.get Point "y" "c") + "z".
 
 Definition Point__GetField: val :=
   rec: "Point__GetField" "c" :=
@@ -1004,6 +2458,63 @@ Definition setField: val :=
     struct.storeF S "c" "s" #true;;
     ![struct.t S] "s".
 
+Definition nestedPtr := struct.decl [
+  "inner" :: ptrT;
+  "s" :: struct.t S
+].
+
+Definition pointerValueChain: val :=
+  rec: "pointerValueChain" "p" :=
+    struct.get TwoInts "x" (struct.loadF S "b" "p").
+
+Definition valueValueChain: val :=
+  rec: "valueValueChain" "v" :=
+    struct.get TwoInts "y" (struct.get S "b" "v").
+
+Definition valuePointerChain: val :=
+  rec: "valuePointerChain" "n" :=
+    struct.loadF TwoInts "x" (struct.get nestedPtr "inner" "n").
+
+Definition pointerPointerChain: val :=
+  rec: "pointerPointerChain" "n" :=
+    struct.loadF TwoInts "y" (struct.loadF nestedPtr "inner" "n") + struct.get TwoInts "x" (struct.get S "b" (struct.loadF nestedPtr "s" "n")).
+
+(* switch.go *)
+
+Definition switchTag: val :=
+  rec: "switchTag" "x" :=
+    let: "$tag" := "x" in
+    (if: "$tag" = #0
+    then #10
+    else (if: ("$tag" = #1) || ("$tag" = #2)
+    then #20
+    else #30)).
+
+Definition switchNoTag: val :=
+  rec: "switchNoTag" "x" :=
+    (if: "x" < #10
+    then #(str"small")
+    else (if: "x" < #100
+    then #(str"medium")
+    else #(str"large"))).
+
+Definition switchDefaultFirst: val :=
+  rec: "switchDefaultFirst" "x" :=
+    let: "y" := ref_to uint64T #0 in
+    let: "$tag" := "x" in
+    (if: "$tag" = #1
+    then "y" <-[uint64T] #1
+    else "y" <-[uint64T] #3);;
+    ![uint64T] "y".
+
+Definition switchWithInit: val :=
+  rec: "switchWithInit" "s" :=
+    let: "n" := slice.len "s" in
+    let: "$tag" := "n" in
+    (if: "$tag" = #0
+    then #0
+    else "n" + #1).
+
 (* synchronization.go *)
 
 (* DoSomeLocking uses the entire lock API *)
@@ -1048,11 +2559,9 @@ Definition mkNothing: val :=
 
 (* type_alias.go *)
 
-Definition my_u64: ty := uint64T.
-
 Definition Timestamp: ty := uint64T.
 
-Definition UseTypeAbbrev: ty := my_u64.
+Definition UseTypeAbbrev: ty := uint64T.
 
 Definition UseNamedType: ty := Timestamp.
 
@@ -1060,3 +2569,89 @@ Definition convertToAlias: val :=
   rec: "convertToAlias" <> :=
     let: "x" := #2 in
     "x".
+
+Definition timestampPair := struct.decl [
+  "start" :: Timestamp;
+  "end" :: Timestamp
+].
+
+Definition aliasFields := struct.decl [
+  "ts" :: uint64T;
+  "interval" :: struct.t timestampPair
+].
+
+Definition aliasParam: val :=
+  rec: "aliasParam" "x" "i" :=
+    "x" + struct.get timestampPair "start" "i".
+
+Definition laterRecord := struct.decl [
+  "n" :: uint64T
+].
+
+(* aliasUser is declared before the type its alias refers to *)
+Definition aliasUser := struct.decl [
+  "r" :: struct.t laterRecord
+].
+
+(* waitgroup.go *)
+
+Definition workers := struct.decl [
+  "wg" :: ptrT
+].
+
+Definition runWorkers: val :=
+  rec: "runWorkers" "n" :=
+    let: "w" := struct.new workers [
+      "wg" ::= waitgroup.New #()
+    ] in
+    let: "i" := ref_to uint64T #0 in
+    (for: (λ: <>, (![uint64T] "i") < "n"); (λ: <>, "i" <-[uint64T] ((![uint64T] "i") + #1)) := λ: <>,
+      waitgroup.Add (struct.loadF workers "wg" "w") #1;;
+      Fork (waitgroup.Done (struct.loadF workers "wg" "w"));;
+      Continue);;
+    waitgroup.Wait (struct.loadF workers "wg" "w");;
+    #().
+
+Definition waitForOne: val :=
+  rec: "waitForOne" <> :=
+    let: "wg" := waitgroup.New #() in
+    waitgroup.Add "wg" #1;;
+    Fork (waitgroup.Done "wg");;
+    waitgroup.Wait "wg";;
+    #().
+
+(* zero.go *)
+
+Definition zeroInner := struct.decl [
+  "x" :: uint64T
+].
+
+Definition zeroFields := struct.decl [
+  "n" :: uint64T;
+  "s" :: slice.T uint64T;
+  "m" :: mapT boolT;
+  "p" :: ptrT;
+  "inner" :: struct.t zeroInner
+].
+
+Definition zeroStruct: val :=
+  rec: "zeroStruct" <> :=
+    let: "z" := ref (zero_val (struct.t zeroFields)) in
+    ![struct.t zeroFields] "z".
+
+Definition allocZeroStruct: val :=
+  rec: "allocZeroStruct" <> :=
+    struct.alloc zeroFields (zero_val (struct.t zeroFields)).
+
+Definition partialZeroStruct: val :=
+  rec: "partialZeroStruct" <> :=
+    struct.mk zeroFields [
+      "n" ::= #1
+    ].
+
+Definition globals__init: val :=
+  rec: "globals__init" <> :=
+    ErrNotFound__init #();;
+    requests__init #();;
+    lastRequest__init #();;
+    #().
@@ -0,0 +1,27 @@
+package unittest
+
+import "sync"
+
+type workers struct {
+	wg sync.WaitGroup
+}
+
+func runWorkers(n uint64) {
+	w := &workers{}
+	for i := uint64(0); i < n; i++ {
+		w.wg.Add(1)
+		go func() {
+			w.wg.Done()
+		}()
+	}
+	w.wg.Wait()
+}
+
+func waitForOne() {
+	wg := new(sync.WaitGroup)
+	wg.Add(1)
+	go func() {
+		wg.Done()
+	}()
+	wg.Wait()
+}
@@ -0,0 +1,26 @@
+package unittest
+
+type zeroInner struct {
+	x uint64
+}
+
+type zeroFields struct {
+	n     uint64
+	s     []uint64
+	m     map[uint64]bool
+	p     *uint64
+	inner zeroInner
+}
+
+func zeroStruct() zeroFields {
+	var z zeroFields
+	return z
+}
+
+func allocZeroStruct() *zeroFields {
+	return new(zeroFields)
+}
+
+func partialZeroStruct() zeroFields {
+	return zeroFields{n: 1}
+}
@@ -3,10 +3,12 @@ From Perennial.goose_lang Require Import prelude.
 
 From Perennial.goose_lang Require Import ffi.disk_prelude.
 
-(* 10 is completely arbitrary *)
+(* MaxTxnWrites is a guaranteed reservation for each transaction.
+
+   10 is completely arbitrary *)
 Definition MaxTxnWrites : expr := #10.
 
-Definition logLength : expr := #1 + (#2 * MaxTxnWrites).
+Definition logLength : expr := #21.
 
 Definition Log := struct.decl [
   "d" :: disk.Disk;
@@ -0,0 +1,9 @@
+package address
+
+func loopPointers() []*uint64 {
+	var ptrs []*uint64
+	for i := uint64(0); i < 10; i++ {
+		ptrs = append(ptrs, &i)
+	}
+	return ptrs
+}
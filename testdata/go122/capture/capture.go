@@ -0,0 +1,11 @@
+package capture
+
+func threadCode(i uint64) {}
+
+func loopSpawn() {
+	for i := uint64(0); i < 10; i++ {
+		go func() {
+			threadCode(i)
+		}()
+	}
+}
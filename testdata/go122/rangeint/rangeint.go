@@ -0,0 +1,17 @@
+package rangeint
+
+func sumTo(n uint64) uint64 {
+	var sum uint64
+	for i := range n {
+		sum += i
+	}
+	return sum
+}
+
+func countdown(n uint32) uint32 {
+	var x uint32
+	for range n {
+		x++
+	}
+	return x
+}
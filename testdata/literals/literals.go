@@ -0,0 +1,19 @@
+package literals
+
+import "sync"
+
+type record struct {
+	a  uint64
+	b  string
+	c  bool
+	mu sync.Mutex
+	d  uint64
+}
+
+func recordLiteral() record {
+	return record{d: 4, b: "b", a: 1, c: true}
+}
+
+func mapLiteral() map[string]uint64 {
+	return map[string]uint64{"z": 26, "a": 1, "m": 13, "b": 2, "y": 25}
+}
@@ -0,0 +1,12 @@
+// Package multifile has declarations split across files.
+package multifile
+
+// point is used by the functions in b_norm.go.
+type point struct {
+	x uint64
+	y uint64
+}
+
+func (p point) sum() uint64 {
+	return p.x + p.y
+}
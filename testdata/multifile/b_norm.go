@@ -0,0 +1,10 @@
+package multifile
+
+// norm refers to point, which is declared in a_point.go.
+func norm(p point) uint64 {
+	return p.sum() + origin().x
+}
+
+func origin() point {
+	return point{x: 0, y: 0}
+}
@@ -0,0 +1,5 @@
+package example
+
+func arraySlice(a [3]uint64) []uint64 {
+	return a[1:] // ERROR not declared with var
+}
@@ -1,12 +1,14 @@
 package example
 
-func Skip() {}
+func Skip(x uint64) {}
 
 func BadIf(i uint64) {
+	x := i
 	if i == 0 {
 		return
 	} else { // ERROR early return in if with an else branch
-		Skip()
+		x := i + 1
+		Skip(x)
 	}
-	Skip()
+	Skip(x)
 }
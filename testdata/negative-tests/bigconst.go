@@ -0,0 +1,3 @@
+package example
+
+const Huge = 1 << 64 // ERROR integer constant 18446744073709551616 does not fit in 64 bits
@@ -1,3 +1,11 @@
 package example
 
-func Foo(e error) {} // ERROR built-in type
+type MyError struct{}
+
+func (e MyError) Error() string {
+	return "my error"
+}
+
+func Foo() error {
+	return MyError{} // ERROR conversion of example.MyError to error
+}
@@ -0,0 +1,10 @@
+package example
+
+type S struct {
+	a uint64
+}
+
+func f() *uint64 {
+	s := S{a: 1}
+	return &s.a // ERROR address of s, which is not declared with var
+}
@@ -1,6 +0,0 @@
-package example
-
-func PointerToFunction(p *func()) {
-	f := *p // ERROR function type
-	f()
-}
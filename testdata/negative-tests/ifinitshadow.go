@@ -0,0 +1,8 @@
+package example
+
+func ifInitShadow(x uint64) uint64 {
+	if x := uint64(3); x > 2 { // ERROR if initialization shadows a variable used after the if
+		return 1
+	}
+	return x
+}
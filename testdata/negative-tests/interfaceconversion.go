@@ -0,0 +1,18 @@
+package example
+
+type getter interface {
+	get() uint64
+}
+
+type getSetter interface {
+	get() uint64
+	set(n uint64)
+}
+
+func useGetter(g getter) uint64 {
+	return g.get()
+}
+
+func useGetSetter(gs getSetter) uint64 {
+	return useGetter(gs) // ERROR conversion from interface
+}
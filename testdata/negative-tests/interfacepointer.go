@@ -0,0 +1,21 @@
+package example
+
+type counter interface {
+	get() uint64
+}
+
+type ptrCounter struct {
+	n uint64
+}
+
+func (c *ptrCounter) get() uint64 {
+	return c.n
+}
+
+func useCounter(c counter) uint64 {
+	return c.get()
+}
+
+func usePointer() uint64 {
+	return useCounter(&ptrCounter{n: 1}) // ERROR conversion of pointer
+}
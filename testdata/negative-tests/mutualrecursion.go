@@ -0,0 +1,15 @@
+package example
+
+func isEven(n uint64) bool {
+	if n == 0 {
+		return true
+	}
+	return isOdd(n - 1)
+}
+
+func isOdd(n uint64) bool { // ERROR dependency cycle
+	if n == 0 {
+		return false
+	}
+	return isEven(n - 1)
+}
@@ -0,0 +1,14 @@
+package example
+
+type embedA struct {
+	a uint64
+}
+
+type embedB struct {
+	embedA
+	b uint64
+}
+
+func setPromoted(b *embedB) {
+	b.a = 1 // ERROR assignment to promoted field
+}
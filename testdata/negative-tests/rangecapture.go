@@ -0,0 +1,9 @@
+package example
+
+func spawnAll(s []uint64, out []uint64) {
+	for i, x := range s {
+		go func() {
+			out[i] = x // ERROR closure captures loop variable i
+		}()
+	}
+}
@@ -0,0 +1,10 @@
+package example
+
+func find(xs []uint64, x uint64) bool {
+	for i := uint64(0); i < uint64(len(xs)); i++ {
+		if xs[i] == x {
+			return true // ERROR return inside a loop
+		}
+	}
+	return false
+}
@@ -0,0 +1,10 @@
+package example
+
+func trySend(ch chan uint64) bool {
+	select {
+	case ch <- 1:
+		return true // ERROR return in unsupported position
+	default:
+	}
+	return false
+}
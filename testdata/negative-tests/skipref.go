@@ -0,0 +1,10 @@
+package example
+
+//goose:skip
+func helper() uint64 {
+	return 1
+}
+
+func useHelper() uint64 {
+	return helper() // ERROR marked //goose:skip
+}
@@ -0,0 +1,7 @@
+package example
+
+import "fmt"
+
+func hex(x uint64) string {
+	return fmt.Sprintf("%x", x) // ERROR fmt.Sprintf verb %x
+}
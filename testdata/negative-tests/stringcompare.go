@@ -0,0 +1,5 @@
+package example
+
+func less(s1, s2 string) bool {
+	return s1 < s2 // ERROR string comparison
+}
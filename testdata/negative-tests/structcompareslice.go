@@ -0,0 +1,9 @@
+package example
+
+type withArray struct {
+	a [2]uint64
+}
+
+func compareWithArray(x withArray, y withArray) bool {
+	return x == y // ERROR comparison of structs with field a
+}
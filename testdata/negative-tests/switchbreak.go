@@ -0,0 +1,13 @@
+package example
+
+func switchBreak(x uint64) uint64 {
+	var y = uint64(0)
+	switch x {
+	case 0:
+		if y == 0 {
+			break // ERROR break in a switch case
+		}
+		y = 1
+	}
+	return y
+}
@@ -0,0 +1,13 @@
+package example
+
+func switchFallthrough(x uint64) uint64 {
+	var y = uint64(0)
+	switch x {
+	case 0:
+		y = 1
+		fallthrough // ERROR fallthrough in a switch case
+	case 1:
+		y = 2
+	}
+	return y
+}
@@ -0,0 +1,8 @@
+package example
+
+import "sync"
+
+func zeroMutex() {
+	var mu sync.Mutex // ERROR zero value of sync.Mutex
+	mu.Lock()
+}
@@ -0,0 +1,25 @@
+package results
+
+import (
+	"fmt"
+
+	"github.com/tchajed/goose/internal/examples/trust_import/trusted_example"
+)
+
+func callTrusted() {
+	trusted_example.Foo()
+}
+
+func less(a, b string) bool {
+	return a < b
+}
+
+func fail(msg string) {
+	panic(msg)
+}
+
+func failFormatted(x uint64) {
+	panic(fmt.Sprintf("bad value %d", x))
+}
+
+func end() {}
@@ -0,0 +1,14 @@
+package example
+
+type point struct {
+	x uint64
+	y uint64
+}
+
+func (p point) sum() uint64 {
+	return p.x + p.y
+}
+
+func origin() point {
+	return point{x: 0, y: 0}
+}
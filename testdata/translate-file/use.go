@@ -0,0 +1,11 @@
+package example
+
+const scale uint64 = 2
+
+func scaledSum(p point) uint64 {
+	return scale * p.sum()
+}
+
+func originSum() uint64 {
+	return scaledSum(origin())
+}
@@ -0,0 +1,5 @@
+package withtests
+
+func double(x uint64) uint64 {
+	return 2 * x
+}
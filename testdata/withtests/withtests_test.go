@@ -0,0 +1,5 @@
+package withtests
+
+func doubleTwice(x uint64) uint64 {
+	return double(double(x))
+}
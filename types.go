@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"go/ast"
 	"go/types"
+	"hash/fnv"
 
 	"github.com/tchajed/goose/internal/coq"
 )
@@ -49,8 +50,7 @@ func (ctx Ctx) selectorExprType(e *ast.SelectorExpr) coq.Expr {
 	if isIdent(e.X, "disk") && isIdent(e.Sel, "Block") {
 		return coq.TypeIdent("disk.blockT")
 	}
-	if isIdent(e.X, "sync") &&
-		(isIdent(e.Sel, "Cond") || isIdent(e.Sel, "Mutex")) {
+	if isIdent(e.X, "sync") && isIdent(e.Sel, "Cond") {
 		ctx.unsupported(e, "%s without pointer indirection", ctx.printGo(e))
 	}
 	return ctx.coqTypeOfType(e, ctx.typeOf(e))
@@ -71,7 +71,7 @@ func (ctx Ctx) coqTypeOfType(n ast.Node, t types.Type) coq.Type {
 	}
 	switch t := t.(type) {
 	case *types.Struct:
-		ctx.unsupported(n, "type for anonymous struct")
+		return coq.StructName(ctx.anonStruct(n, t))
 	case *types.TypeParam:
 		return coq.TypeIdent(t.Obj().Name())
 	case *types.Basic:
@@ -80,9 +80,13 @@ func (ctx Ctx) coqTypeOfType(n ast.Node, t types.Type) coq.Type {
 			return coq.TypeIdent("uint64T")
 		case "uint32":
 			return coq.TypeIdent("uint32T")
-		case "byte":
+		case "rune":
+			// GooseLang has no signed integers; runes are modeled as unsigned
+			// 32-bit code points
+			return coq.TypeIdent("uint32T")
+		case "byte", "uint8":
 			return coq.TypeIdent("byteT")
-		case "bool":
+		case "bool", "untyped bool":
 			return coq.TypeIdent("boolT")
 		case "string", "untyped string":
 			return coq.TypeIdent("stringT")
@@ -92,6 +96,9 @@ func (ctx Ctx) coqTypeOfType(n ast.Node, t types.Type) coq.Type {
 	case *types.Pointer:
 		return coq.PtrType{}
 	case *types.Named:
+		if isErrorType(t) {
+			return coq.TypeIdent("errorT")
+		}
 		if t.Obj().Pkg() == nil {
 			ctx.unsupported(n, "unexpected built-in type %v", t.Obj())
 		}
@@ -101,16 +108,42 @@ func (ctx Ctx) coqTypeOfType(n ast.Node, t types.Type) coq.Type {
 		if t.Obj().Pkg().Name() == "disk" && t.Obj().Name() == "Disk" {
 			return coq.TypeIdent("disk.Disk")
 		}
+		if _, ok := syncAllocation(t); ok {
+			// a mutex value holds a pointer to a lock (see isMutex)
+			return coq.PtrType{}
+		}
 		if info, ok := ctx.getStructInfo(t); ok {
 			return coq.StructName(info.name)
 		}
+		// interface values are structs of methods
+		if info, ok := ctx.getInterfaceInfo(t); ok && !info.interfaceType.Empty() {
+			return coq.StructName(info.name)
+		}
 		return coq.TypeIdent(ctx.qualifiedName(t.Obj()))
 	case *types.Slice:
 		return coq.SliceType{ctx.coqTypeOfType(n, t.Elem())}
+	case *types.Array:
+		return coq.ArrayType{Len: uint64(t.Len()), Elt: ctx.coqTypeOfType(n, t.Elem())}
 	case *types.Map:
 		return coq.MapType{Key: ctx.coqTypeOfType(n, t.Key()), Value: ctx.coqTypeOfType(n, t.Elem())}
+	case *types.Chan:
+		// the direction of a channel type only restricts how Go code uses it
+		return coq.ChanType{Elem: ctx.coqTypeOfType(n, t.Elem())}
 	case *types.Signature:
-		ctx.unsupported(n, "function type")
+		// like coqFuncType, from the type checker's signature
+		var args []coq.Type
+		for i := 0; i < t.Params().Len(); i++ {
+			args = append(args, ctx.coqTypeOfType(n, t.Params().At(i).Type()))
+		}
+		var ret coq.Type = coq.TypeIdent("unitT")
+		if t.Results().Len() > 0 {
+			var ts []coq.Type
+			for i := 0; i < t.Results().Len(); i++ {
+				ts = append(ts, ctx.coqTypeOfType(n, t.Results().At(i).Type()))
+			}
+			ret = coq.NewTupleType(ts)
+		}
+		return coq.ArrowType{ArgTypes: args, ReturnType: ret}
 	case *types.Interface:
 		return coq.InterfaceDecl{Name: ""}
 	}
@@ -154,23 +187,55 @@ func (ctx Ctx) coqFuncType(e *ast.FuncType) coq.Type {
 	for _, a := range args {
 		types = append(types, a.Type)
 	}
-	if len(args) == 0 {
-		types = append(types, coq.TypeIdent("unitT"))
-	}
 	resType := ctx.returnType(e.Results)
 	return coq.ArrowType{ArgTypes: types, ReturnType: resType}
 }
 
+// addTypeDeps records a dependency on each named type of this package that t
+// refers to
+func (ctx Ctx) addTypeDeps(t types.Type) {
+	switch t := t.(type) {
+	case *types.Named:
+		if t.Obj().Pkg() != nil && t.Obj().Pkg().Path() == ctx.pkgPath {
+			ctx.dep.addDep(coq.GallinaName(t.Obj().Name()))
+		}
+	case *types.Pointer:
+		ctx.addTypeDeps(t.Elem())
+	case *types.Slice:
+		ctx.addTypeDeps(t.Elem())
+	case *types.Array:
+		ctx.addTypeDeps(t.Elem())
+	case *types.Chan:
+		ctx.addTypeDeps(t.Elem())
+	case *types.Map:
+		ctx.addTypeDeps(t.Key())
+		ctx.addTypeDeps(t.Elem())
+	case *types.Struct:
+		for i := 0; i < t.NumFields(); i++ {
+			ctx.addTypeDeps(t.Field(i).Type())
+		}
+	}
+}
+
 func (ctx Ctx) coqType(e ast.Expr) coq.Type {
 	switch e := e.(type) {
 	case *ast.Ident:
-		ctx.dep.addDep(e.Name)
+		if obj, ok := ctx.info.Uses[e].(*types.TypeName); ok && obj.IsAlias() {
+			// an alias is the same type as its target, so it has no definition,
+			// but it still depends on the types the target refers to
+			ctx.addTypeDeps(ctx.typeOf(e))
+			return ctx.coqTypeOfType(e, ctx.typeOf(e))
+		}
+		name := coq.GallinaName(e.Name)
+		ctx.dep.addDep(name)
 		if ctx.identInfo(e).IsMacro {
-			return coq.TypeIdent(e.Name)
+			return coq.TypeIdent(name)
 		}
 		return ctx.coqTypeOfType(e, ctx.typeOf(e))
 	case *ast.MapType:
 		return ctx.mapType(e)
+	case *ast.StructType:
+		return ctx.coqTypeOfType(e, ctx.typeOf(e))
 	case *ast.SelectorExpr:
 		return ctx.selectorExprType(e)
 	case *ast.ArrayType:
@@ -190,6 +255,8 @@ func (ctx Ctx) coqType(e ast.Expr) coq.Type {
 		return coq.SliceType{ctx.coqType(e.Elt)}
 	case *ast.FuncType:
 		return ctx.coqFuncType(e)
+	case *ast.ChanType:
+		return ctx.coqTypeOfType(e, ctx.typeOf(e))
 	default:
 		ctx.unsupported(e, "unexpected type expr")
 	}
@@ -207,6 +274,72 @@ func isLockRef(t types.Type) bool {
 	return false
 }
 
+// isMutex checks if t is a sync.Mutex value, such as a struct field
+// mu sync.Mutex.
+//
+// A mutex value is modeled as a pointer to a lock, which must be allocated
+// when the mutex is created: struct literals allocate a lock for each omitted
+// mutex field, but zero values (as in var mu sync.Mutex) are not supported.
+// Taking the address of a mutex gives the same lock.
+func isMutex(t types.Type) bool {
+	return isSyncType(t, "Mutex")
+}
+
+// isRWMutex checks if t is a sync.RWMutex value, which is modeled like a
+// sync.Mutex (see isMutex) with a reader-writer lock
+func isRWMutex(t types.Type) bool {
+	return isSyncType(t, "RWMutex")
+}
+
+func isRWMutexRef(t types.Type) bool {
+	if t, ok := t.(*types.Pointer); ok {
+		return isRWMutex(t.Elem())
+	}
+	return false
+}
+
+// syncAllocation gives the function allocating a sync.Mutex, sync.RWMutex, or
+// sync.WaitGroup, which are all modeled as pointers (see isMutex)
+func syncAllocation(t types.Type) (string, bool) {
+	switch {
+	case isMutex(t):
+		return "lock.new", true
+	case isRWMutex(t):
+		return "rwlock.new", true
+	case isSyncType(t, "WaitGroup"):
+		return "waitgroup.New", true
+	}
+	return "", false
+}
+
+func isSyncType(t types.Type, name string) bool {
+	if t, ok := t.(*types.Named); ok {
+		obj := t.Obj()
+		return obj.Pkg() != nil && obj.Pkg().Path() == "sync" &&
+			obj.Name() == name
+	}
+	return false
+}
+
+// containsSyncValue checks if the zero value of t has a mutex or wait group,
+// which would not be allocated
+func containsSyncValue(t types.Type) bool {
+	if _, ok := syncAllocation(t); ok {
+		return true
+	}
+	switch t := t.Underlying().(type) {
+	case *types.Struct:
+		for i := 0; i < t.NumFields(); i++ {
+			if containsSyncValue(t.Field(i).Type()) {
+				return true
+			}
+		}
+	case *types.Array:
+		return containsSyncValue(t.Elem())
+	}
+	return false
+}
+
 func isCFMutexRef(t types.Type) bool {
 	if t, ok := t.(*types.Pointer); ok {
 		if t, ok := t.Elem().(*types.Named); ok {
@@ -229,15 +362,12 @@ func isCondVar(t types.Type) bool {
 	return false
 }
 
+// isWaitGroup checks if t is a sync.WaitGroup or a pointer to one
 func isWaitGroup(t types.Type) bool {
-	if t, ok := t.(*types.Pointer); ok {
-		if t, ok := t.Elem().(*types.Named); ok {
-			name := t.Obj()
-			return name.Pkg().Name() == "sync" &&
-				name.Name() == "WaitGroup"
-		}
+	if pt, ok := t.(*types.Pointer); ok {
+		t = pt.Elem()
 	}
-	return false
+	return isSyncType(t, "WaitGroup")
 }
 
 func isProphId(t types.Type) bool {
@@ -254,12 +384,21 @@ func isProphId(t types.Type) bool {
 func isByteSlice(t types.Type) bool {
 	if t, ok := t.(*types.Slice); ok {
 		if elTy, ok := t.Elem().(*types.Basic); ok {
-			return elTy.Name() == "byte"
+			return elTy.Kind() == types.Uint8
 		}
 	}
 	return false
 }
 
+// isErrorType reports whether t is the built-in error interface.
+//
+// Errors are modeled as options: nil is NONE, and errors.New(msg) allocates the
+// message and is SOME of its location, so that err.Error() loads the message
+// and errors are compared by identity, as in Go.
+func isErrorType(t types.Type) bool {
+	return types.Identical(t, types.Universe.Lookup("error").Type())
+}
+
 func isString(t types.Type) bool {
 	if t, ok := t.(*types.Basic); ok {
 		return t.Name() == "string"
@@ -309,6 +448,12 @@ func getIntegerType(t types.Type) (intTypeInfo, bool) {
 		return intTypeInfo{isUntyped: true}, true
 	case types.Uint32:
 		return intTypeInfo{width: 32}, true
+	case types.Int32, types.UntypedRune:
+		// only rune (an alias for int32) is supported, as an unsigned code point
+		if basicTy.Name() == "rune" || basicTy.Kind() == types.UntypedRune {
+			return intTypeInfo{width: 32}, true
+		}
+		return intTypeInfo{}, false
 	case types.Uint8:
 		return intTypeInfo{width: 8}, true
 	default:
@@ -338,9 +483,45 @@ func (ctx Ctx) getStructInfo(t types.Type) (structTypeInfo, bool) {
 			}, true
 		}
 	}
+	if structType, ok := t.(*types.Struct); ok {
+		return structTypeInfo{
+			name:           anonStructName(structType),
+			throughPointer: throughPointer,
+			structType:     structType,
+		}, true
+	}
 	return structTypeInfo{}, false
 }
 
+// anonStructName synthesizes a name for an anonymous struct type.
+//
+// The name is a hash of the field names and types, so it is stable across runs
+// and identical anonymous structs share a declaration.
+func anonStructName(t *types.Struct) string {
+	h := fnv.New32a()
+	for i := 0; i < t.NumFields(); i++ {
+		f := t.Field(i)
+		fmt.Fprintf(h, "%s %s;", f.Name(), f.Type())
+	}
+	return fmt.Sprintf("struct__%08x", h.Sum32())
+}
+
+// anonStruct declares an anonymous struct type along with the current
+// declaration, returning its synthesized name
+func (ctx Ctx) anonStruct(n ast.Node, t *types.Struct) string {
+	name := anonStructName(t)
+	decl := coq.StructDecl{Name: name}
+	for i := 0; i < t.NumFields(); i++ {
+		f := t.Field(i)
+		decl.Fields = append(decl.Fields, coq.FieldDecl{
+			Name: f.Name(),
+			Type: ctx.coqTypeOfType(n, f.Type()),
+		})
+	}
+	ctx.dep.addSynthesized(name, decl)
+	return name
+}
+
 type interfaceTypeInfo struct {
 	name          string
 	interfaceType *types.Interface
@@ -350,7 +531,7 @@ func (ctx Ctx) getInterfaceInfo(t types.Type) (interfaceTypeInfo, bool) {
 	if pt, ok := t.(*types.Pointer); ok {
 		t = pt.Elem()
 	}
-	if t, ok := t.(*types.Named); ok {
+	if t, ok := t.(*types.Named); ok && !isErrorType(t) {
 		name := ctx.qualifiedName(t.Obj())
 		if interfaceType, ok := t.Underlying().(*types.Interface); ok {
 			return interfaceTypeInfo{